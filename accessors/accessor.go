@@ -1,40 +1,54 @@
 package accessors
 
 import (
-	"encoding/json"
-	"github.com/satori/go.uuid"
-	"io/ioutil"
-	"net/http"
+	"fmt"
+	"time"
 )
 
-// Struct to model the response from the guid generator micro-service.
-type guid struct {
-	Status string
-	Data   string
+// Returns the current time as stored by the accessors: UTC, to the
+//   microsecond, which every supported database can hold exactly.
+var Now = func() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
 }
 
-var NewGuid = func() string {
-	var newGuid guid
+// Layouts the database drivers use when they return times as text.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+}
 
-	// Request new guid
-	res, err := http.Get("http://tmt-guid.byu.edu/guid")
-	// Check for errors, if so, create a new guid
-	if err != nil {
-		guid := uuid.NewV4()
-		return guid.String()
-	}
-	body, err := ioutil.ReadAll(res.Body)
-	// Check for errors, if so, create a new guid
-	if err != nil {
-		guid := uuid.NewV4()
-		return guid.String()
-	}
-	// Parse data, if an error occurred, create a new guid
-	if err := json.Unmarshal(body, &newGuid); err != nil || newGuid.Status != "OK" {
-		guid := uuid.NewV4()
-		return guid.String()
+// Scans a timestamp column into a time.Time, whether the driver returns
+//   it as a time.Time or as text. NULL becomes the zero time.
+type timestamp struct {
+	t *time.Time
+}
+
+func (ts timestamp) Scan(value interface{}) error {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		*ts.t = time.Time{}
+		return nil
+	case time.Time:
+		*ts.t = v.UTC()
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("cannot scan %T into a timestamp", value)
 	}
 
-	// Guid retrieved succesfully, return it
-	return newGuid.Data
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			*ts.t = t.UTC()
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse timestamp %q", text)
 }
@@ -0,0 +1,211 @@
+package accessors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Audited operations.
+const (
+	OpCreate  = "create"
+	OpUpdate  = "update"
+	OpDelete  = "delete"
+	OpRestore = "restore"
+)
+
+// Audited entities.
+const (
+	EntityResource    = "resource"
+	EntityVerb        = "verb"
+	EntityType        = "type"
+	EntitySuppression = "suppression"
+	EntityGrant       = "grant"
+)
+
+// AuditEntry records one change to a resource, verb, type association,
+//   verb suppression or grant. Before and After hold the JSON of the entity
+//   on either side of the change and are empty when there was no entity
+//   (before a create). Every entry is filed under the resource the changed
+//   entity belongs to.
+type AuditEntry struct {
+	Guid         string          `json:"guid"`
+	Actor        string          `json:"actor"`
+	Time         time.Time       `json:"time"`
+	Operation    string          `json:"operation"`
+	Entity       string          `json:"entity"`
+	EntityGUID   string          `json:"entityGUID"`
+	ResourceGUID string          `json:"resourceGUID"`
+	Before       json.RawMessage `json:"before,omitempty"`
+	After        json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditQuery selects audit entries. Zero fields match everything; Since is
+//   inclusive and Until exclusive. A Limit of 0 means no limit.
+type AuditQuery struct {
+	Actor        string
+	ResourceGUID string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
+
+type AuditAccessor struct {
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+}
+
+// Returns a new audit accessor.
+func NewAuditAccessor(db *sql.DB) *AuditAccessor {
+	return &AuditAccessor{db, MySQL}
+}
+
+// Makes the guids of audit entries. They are made here rather than by the
+//   guid service so that no change waits on the service, with its
+//   transaction open, to be recorded.
+var NewAuditGuid = func() string {
+	guid, _ := UUIDv4{}.Guid()
+	return guid
+}
+
+// The audited fields of a resource; its verbs are audited on their own.
+type auditedResource struct {
+	Guid        string    `json:"guid"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	APIEndpoint string    `json:"apiEndpoint"`
+	Created     time.Time `json:"created"`
+}
+
+func resourceValues(r Resource) auditedResource {
+	return auditedResource{r.Guid, r.Name, r.Description, r.APIEndpoint, r.Created}
+}
+
+// Makes the audit entry of a change actor made at the given time. before
+//   and after are the entity on either side of the change, nil if there is
+//   none.
+func newAuditEntry(actor string, changed time.Time, operation, entity, entityGUID, resourceGUID string, before, after interface{}) (AuditEntry, error) {
+	e := AuditEntry{
+		Actor:        actor,
+		Time:         changed,
+		Operation:    operation,
+		Entity:       entity,
+		EntityGUID:   entityGUID,
+		ResourceGUID: resourceGUID,
+	}
+
+	var err error
+	if before != nil {
+		if e.Before, err = json.Marshal(before); err != nil {
+			return e, err
+		}
+	}
+	if after != nil {
+		if e.After, err = json.Marshal(after); err != nil {
+			return e, err
+		}
+	}
+	return e, nil
+}
+
+// Records a change in the audit log within the transaction making it, so
+//   the change and its entry are committed or rolled back together.
+func record(tx *sql.Tx, d Dialect, actor string, changed time.Time, operation, entity, entityGUID, resourceGUID string, before, after interface{}) error {
+	e, err := newAuditEntry(actor, changed, operation, entity, entityGUID, resourceGUID, before, after)
+	if err != nil {
+		return err
+	}
+	return recordEntries(tx, d, e)
+}
+
+// Records entries made with newAuditEntry within the transaction making
+//   their changes.
+func recordEntries(tx *sql.Tx, d Dialect, entries ...AuditEntry) error {
+	var args [][]interface{}
+	for _, e := range entries {
+		args = append(args, []interface{}{NewAuditGuid(), e.Actor, e.Time, e.Operation, e.Entity, e.EntityGUID, e.ResourceGUID, nullJSON(e.Before), nullJSON(e.After)})
+	}
+	return execEach(tx, d, "INSERT INTO auditLog (guid, actor, changed, operation, entity, entityGUID, resourceGUID, beforeValues, afterValues) VALUES (?,?,?,?,?,?,?,?,?)", args)
+}
+
+// Records an audit entry at the current time and returns its guid. The
+//   given Guid and Time are ignored.
+func (aa *AuditAccessor) Record(e AuditEntry) (string, error) {
+	stmt, err := aa.Dialect.prepare(aa.DB, "INSERT INTO auditLog (guid, actor, changed, operation, entity, entityGUID, resourceGUID, beforeValues, afterValues) VALUES (?,?,?,?,?,?,?,?,?)")
+	if err != nil {
+		return "", err
+	}
+	defer stmt.Close()
+
+	guid := NewAuditGuid()
+	_, err = stmt.Exec(guid, e.Actor, Now(), e.Operation, e.Entity, e.EntityGUID, e.ResourceGUID, nullJSON(e.Before), nullJSON(e.After))
+	return guid, aa.Dialect.err(err)
+}
+
+// Gets the audit entries matching the query, newest first.
+func (aa *AuditAccessor) Get(q AuditQuery) ([]AuditEntry, error) {
+	entries := make([]AuditEntry, 0)
+
+	var where []string
+	var args []interface{}
+	if q.Actor != "" {
+		where = append(where, "actor=?")
+		args = append(args, q.Actor)
+	}
+	if q.ResourceGUID != "" {
+		where = append(where, "resourceGUID=?")
+		args = append(args, q.ResourceGUID)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "changed>=?")
+		args = append(args, q.Since)
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "changed<?")
+		args = append(args, q.Until)
+	}
+
+	query := "SELECT guid, actor, changed, operation, entity, entityGUID, resourceGUID, beforeValues, afterValues FROM auditLog"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY changed DESC, guid DESC"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	stmt, err := aa.Dialect.prepare(aa.DB, query)
+	if err != nil {
+		return entries, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return entries, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e AuditEntry
+		var before, after sql.NullString
+		if err := rows.Scan(&e.Guid, &e.Actor, timestamp{&e.Time}, &e.Operation, &e.Entity, &e.EntityGUID, &e.ResourceGUID, &before, &after); err != nil {
+			return entries, err
+		}
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Converts a JSON value to a nullable column value.
+func nullJSON(value json.RawMessage) sql.NullString {
+	return sql.NullString{String: string(value), Valid: len(value) > 0}
+}
@@ -0,0 +1,230 @@
+package accessors
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Outcomes of an authorization decision.
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+)
+
+// AccessRequest asks whether a principal, holding the given roles, may
+//   perform a verb on a resource.
+type AccessRequest struct {
+	Principal    string   `json:"principal"`
+	Roles        []string `json:"roles"`
+	ResourceGUID string   `json:"resourceGUID"`
+	Verb         string   `json:"verb"`
+}
+
+// Decision answers an AccessRequest. An allowed request comes with the
+//   grant that allows it; the reason explains either outcome.
+type Decision struct {
+	Decision string `json:"decision"`
+	Grant    *Grant `json:"grant,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// Reasons given with a decision.
+const (
+	reasonGranted    = "granted"
+	reasonNoResource = "the resource does not exist"
+	reasonNoVerb     = "the resource has no such verb"
+	reasonNoGrant    = "no grant matches"
+)
+
+// What deciding requests on one resource needs to know about it.
+type accessTarget struct {
+	live        bool
+	inheritance Inheritance
+}
+
+// Returns the resource followed by its type chain, nearest first; grants
+//   on any of them apply to the resource.
+func (at accessTarget) scope(resource string) []string {
+	scope := []string{resource}
+	for _, t := range at.inheritance.Chain {
+		scope = append(scope, t.Guid)
+	}
+	return scope
+}
+
+// Returns whether the target has the verb of the given name.
+func (at accessTarget) has(verb string) bool {
+	for _, v := range at.inheritance.Verbs {
+		if v.Verb == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// Decides a request given its resource and the grants of its verb on the
+//   resource's scope that name the principal or one of the roles. A grant
+//   on the resource itself is preferred to one on its types, the nearest
+//   type to farther ones, and a grant to the principal to one to a role.
+func decide(req AccessRequest, target accessTarget, grants []Grant) Decision {
+	if !target.live {
+		return Decision{DecisionDeny, nil, reasonNoResource}
+	}
+	if !target.has(req.Verb) {
+		return Decision{DecisionDeny, nil, reasonNoVerb}
+	}
+
+	rank := make(map[string]int)
+	for i, guid := range target.scope(req.ResourceGUID) {
+		if _, ok := rank[guid]; !ok {
+			rank[guid] = 2 * i
+		}
+	}
+	var best *Grant
+	bestRank := 0
+	for i := range grants {
+		g := &grants[i]
+		r, ok := rank[g.ResourceGUID]
+		if !ok || g.Verb != req.Verb || !grantMatches(*g, req) {
+			continue
+		}
+		if g.Principal == "" {
+			r++
+		}
+		if best == nil || r < bestRank {
+			best, bestRank = g, r
+		}
+	}
+	if best == nil {
+		return Decision{DecisionDeny, nil, reasonNoGrant}
+	}
+	grant := *best
+	return Decision{DecisionAllow, &grant, reasonGranted}
+}
+
+// Returns whether a grant names the principal or one of the roles of a
+//   request.
+func grantMatches(g Grant, req AccessRequest) bool {
+	if g.Principal != "" {
+		return g.Principal == req.Principal
+	}
+	for _, role := range req.Roles {
+		if g.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Decides each request, in order. The requests on each resource share one
+//   resolution of its type chain and one query for grants.
+func (ga *GrantAccessor) Authorize(reqs []AccessRequest) ([]Decision, error) {
+	var order []string
+	byResource := make(map[string][]int)
+	for i, req := range reqs {
+		if _, ok := byResource[req.ResourceGUID]; !ok {
+			order = append(order, req.ResourceGUID)
+		}
+		byResource[req.ResourceGUID] = append(byResource[req.ResourceGUID], i)
+	}
+
+	decisions := make([]Decision, len(reqs))
+	for _, resource := range order {
+		target, err := ga.target(resource)
+		if err != nil {
+			return nil, err
+		}
+
+		var asked []AccessRequest
+		for _, i := range byResource[resource] {
+			if target.live && target.has(reqs[i].Verb) {
+				asked = append(asked, reqs[i])
+			}
+		}
+		grants, err := ga.candidates(asked, target.scope(resource))
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range byResource[resource] {
+			decisions[i] = decide(reqs[i], target, grants)
+		}
+	}
+	return decisions, nil
+}
+
+// Loads what deciding requests on a resource needs.
+func (ga *GrantAccessor) target(resource string) (accessTarget, error) {
+	var target accessTarget
+	stmt, err := ga.Dialect.prepare(ga.DB, "SELECT COUNT(*) FROM resources WHERE guid=? AND deleted IS NULL")
+	if err != nil {
+		return target, err
+	}
+	defer stmt.Close()
+
+	var count int
+	if err := stmt.QueryRow(resource).Scan(&count); err != nil || count == 0 {
+		return target, err
+	}
+	target.live = true
+	target.inheritance, err = inherit(resource, sqlInheritance{ga.DB, ga.Dialect})
+	return target, err
+}
+
+// Returns the placeholders of an IN list of the given values and appends
+//   the values to args.
+func inList(values []string, args []interface{}) (string, []interface{}) {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", len(values)), ","), args
+}
+
+// Gets the grants on the given resources of any of the requests' verbs
+//   that name any of their principals or roles. Grants that match a
+//   different request than the one they name are left for decide to skip.
+func (ga *GrantAccessor) candidates(reqs []AccessRequest, scope []string) ([]Grant, error) {
+	var verbs, principals, roles []string
+	seen := make(map[string]bool)
+	add := func(list *[]string, kind, value string) {
+		if value != "" && !seen[kind+value] {
+			seen[kind+value] = true
+			*list = append(*list, value)
+		}
+	}
+	for _, req := range reqs {
+		add(&verbs, "verb:", req.Verb)
+		add(&principals, "principal:", req.Principal)
+		for _, role := range req.Roles {
+			add(&roles, "role:", role)
+		}
+	}
+	if len(verbs) == 0 || len(principals)+len(roles) == 0 {
+		return nil, nil
+	}
+
+	var args []interface{}
+	var who []string
+	placeholders, args := inList(verbs, args)
+	where := "verb IN (" + placeholders + ")"
+	if len(principals) > 0 {
+		placeholders, args = inList(principals, args)
+		who = append(who, "principal IN ("+placeholders+")")
+	}
+	if len(roles) > 0 {
+		placeholders, args = inList(roles, args)
+		who = append(who, "role IN ("+placeholders+")")
+	}
+	placeholders, args = inList(scope, args)
+	where += " AND (" + strings.Join(who, " OR ") + ") AND resourceGUID IN (" + placeholders + ")"
+
+	var grants []Grant
+	err := queryEach(ga.DB, ga.Dialect, "SELECT "+grantColumns+" FROM grants WHERE "+where+" ORDER BY created, guid", func(rows *sql.Rows) error {
+		var g Grant
+		if err := scanGrant(rows, &g); err != nil {
+			return err
+		}
+		grants = append(grants, g)
+		return nil
+	}, args...)
+	return grants, err
+}
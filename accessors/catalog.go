@@ -0,0 +1,737 @@
+package accessors
+
+import (
+	"database/sql"
+	"fmt"
+	rules "github.com/byu-oit-ssengineering/tmt-resources/rules"
+	"time"
+)
+
+// Modes of importing a catalog.
+const (
+	ImportMerge   = "merge"   // Add and update what is in the catalog and keep everything else
+	ImportReplace = "replace" // Also move whatever is not in the catalog to the trash
+)
+
+// An import mode other than ImportMerge or ImportReplace.
+var ErrInvalidImportMode = kindError(ErrInvalid, "accessors: invalid import mode")
+
+// Catalog is a copy of every resource outside the trash with its verbs, and
+//   of every type association between them, for moving the resources from
+//   one environment to another. GUIDs are kept so that other services'
+//   references stay valid.
+type Catalog struct {
+	Resources []CatalogResource `json:"resources" yaml:"resources"`
+	Types     []CatalogType     `json:"types" yaml:"types"`
+}
+
+// A resource in a catalog.
+type CatalogResource struct {
+	Guid        string        `json:"guid" yaml:"guid"`
+	Name        string        `json:"name" yaml:"name"`
+	Description string        `json:"description" yaml:"description"`
+	APIEndpoint string        `json:"apiEndpoint" yaml:"apiEndpoint"`
+	Verbs       []CatalogVerb `json:"verbs" yaml:"verbs"`
+}
+
+// A verb of a resource in a catalog.
+type CatalogVerb struct {
+	Guid        string `json:"guid" yaml:"guid"`
+	Verb        string `json:"verb" yaml:"verb"`
+	Description string `json:"description" yaml:"description"`
+}
+
+// A type association in a catalog.
+type CatalogType struct {
+	Guid         string `json:"guid" yaml:"guid"`
+	ResourceGUID string `json:"resourceGUID" yaml:"resourceGUID"`
+	Type         string `json:"type" yaml:"type"`
+}
+
+// ImportResult counts the resources, verbs and type associations an import
+//   created, updated, left as they were and deleted.
+type ImportResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Deleted   int `json:"deleted"`
+}
+
+// Checks that everything in the catalog has a well-formed guid, that no
+//   guid is used twice, that resources and verbs are named, that no name,
+//   verb of a resource or type association is repeated, that no resource is
+//   its own type and that the fields keep the rules requests are held to.
+//   Returns a *CatalogError listing every problem found.
+func (c Catalog) Validate() error {
+	var problems []string
+	seen := make(map[string]bool)
+	checkGuid := func(what, guid string) {
+		switch {
+		case guid == "":
+			problems = append(problems, what+" has no guid")
+		case !rules.GuidPattern.MatchString(guid):
+			problems = append(problems, fmt.Sprintf("%s has the malformed guid %q", what, guid))
+		case seen[guid]:
+			problems = append(problems, fmt.Sprintf("guid %s is used more than once", guid))
+		}
+		seen[guid] = true
+	}
+
+	names := make(map[string]int)
+	for i, r := range c.Resources {
+		checkGuid(fmt.Sprintf("resource %d", i+1), r.Guid)
+		if r.Name == "" {
+			problems = append(problems, fmt.Sprintf("resource %d has no name", i+1))
+		} else if first, ok := names[r.Name]; ok {
+			problems = append(problems, fmt.Sprintf("resource %d has the name %q of resource %d", i+1, r.Name, first))
+		} else {
+			names[r.Name] = i + 1
+		}
+		verbs := make(map[string]bool)
+		for j, v := range r.Verbs {
+			checkGuid(fmt.Sprintf("verb %d of resource %d", j+1, i+1), v.Guid)
+			if v.Verb == "" {
+				problems = append(problems, fmt.Sprintf("verb %d of resource %d has no verb", j+1, i+1))
+			} else if verbs[v.Verb] {
+				problems = append(problems, fmt.Sprintf("resource %d has the verb %q more than once", i+1, v.Verb))
+			}
+			verbs[v.Verb] = true
+		}
+	}
+	pairs := make(map[[2]string]int)
+	for i, t := range c.Types {
+		checkGuid(fmt.Sprintf("type association %d", i+1), t.Guid)
+		if t.ResourceGUID == "" || t.Type == "" {
+			problems = append(problems, fmt.Sprintf("type association %d needs both a resourceGUID and a type", i+1))
+		} else if t.ResourceGUID == t.Type {
+			problems = append(problems, fmt.Sprintf("type association %d makes resource %s its own type", i+1, t.ResourceGUID))
+		} else if first, ok := pairs[[2]string{t.ResourceGUID, t.Type}]; ok {
+			problems = append(problems, fmt.Sprintf("type association %d repeats type association %d", i+1, first))
+		} else {
+			pairs[[2]string{t.ResourceGUID, t.Type}] = i + 1
+		}
+	}
+
+	// Fields are held to the rules of the requests creating them, so that an
+	//   import cannot store what the API refuses
+	checkField := func(prefix string, f rules.Field, name, value string) {
+		for _, v := range f.Check(name, value) {
+			problems = append(problems, prefix+v.Message)
+		}
+	}
+	for i, r := range c.Resources {
+		prefix := fmt.Sprintf("resource %d: ", i+1)
+		if r.Name != "" {
+			checkField(prefix, rules.Name, "name", r.Name)
+		}
+		checkField(prefix, rules.Description, "description", r.Description)
+		checkField(prefix, rules.APIEndpoint, "apiEndpoint", r.APIEndpoint)
+		for j, v := range r.Verbs {
+			prefix := fmt.Sprintf("verb %d of resource %d: ", j+1, i+1)
+			if v.Verb != "" {
+				checkField(prefix, rules.Verb, "verb", v.Verb)
+			}
+			checkField(prefix, rules.Description, "description", v.Description)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &CatalogError{problems}
+	}
+	return nil
+}
+
+// The rows an import is compared against, trashed ones included, in the
+//   order they are exported.
+type catalogState struct {
+	resources map[string]storedResource
+	verbs     map[string]storedVerb
+	types     map[string]CatalogType
+
+	resourceOrder []string
+	verbOrder     []string
+	typeOrder     []string
+}
+
+// A stored resource; its Verbs are not filled in.
+type storedResource struct {
+	CatalogResource
+	created time.Time
+	deleted bool
+}
+
+// A stored verb.
+type storedVerb struct {
+	CatalogVerb
+	resourceGUID string
+	version      int
+	deleted      bool
+}
+
+func newCatalogState() catalogState {
+	return catalogState{
+		resources: make(map[string]storedResource),
+		verbs:     make(map[string]storedVerb),
+		types:     make(map[string]CatalogType),
+	}
+}
+
+// Returns whether a resource is stored and outside the trash.
+func (st catalogState) live(resource string) bool {
+	r, ok := st.resources[resource]
+	return ok && !r.deleted
+}
+
+// Returns the catalog of the live rows. A type association is only
+//   included if both of its resources are.
+func (st catalogState) catalog() Catalog {
+	c := Catalog{make([]CatalogResource, 0), make([]CatalogType, 0)}
+	index := make(map[string]int)
+	for _, guid := range st.resourceOrder {
+		if r := st.resources[guid]; !r.deleted {
+			index[guid] = len(c.Resources)
+			r.Verbs = make([]CatalogVerb, 0)
+			c.Resources = append(c.Resources, r.CatalogResource)
+		}
+	}
+	for _, guid := range st.verbOrder {
+		v := st.verbs[guid]
+		if i, ok := index[v.resourceGUID]; ok && !v.deleted {
+			c.Resources[i].Verbs = append(c.Resources[i].Verbs, v.CatalogVerb)
+		}
+	}
+	for _, guid := range st.typeOrder {
+		if t := st.types[guid]; st.live(t.ResourceGUID) && st.live(t.Type) {
+			c.Types = append(c.Types, t)
+		}
+	}
+	return c
+}
+
+// A verb to insert or update, with the resource it belongs to.
+type importedVerb struct {
+	CatalogVerb
+	resourceGUID string
+}
+
+// The writes that bring the stored rows in line with a catalog.
+type importPlan struct {
+	insertResources []CatalogResource
+	updateResources []CatalogResource
+	insertVerbs     []importedVerb
+	updateVerbs     []importedVerb
+	insertTypes     []CatalogType
+	updateTypes     []CatalogType
+
+	// Guids of the rows to move to the trash or, for type associations,
+	//   to delete.
+	deleteResources []string
+	deleteVerbs     []string
+	deleteTypes     []string
+
+	// Resources that are otherwise unchanged but whose verbs or types
+	//   changed, as either changes the verbs they have.
+	touch []string
+
+	// Stored verbs that leave a resource staying outside the trash, by
+	//   being trashed, renamed or moved. Their grants go as when a verb is
+	//   removed.
+	removeVerbs []ResourceVerb
+
+	result ImportResult
+}
+
+// Works out how to import a valid catalog. Type associations must refer to
+//   resources in the catalog or, when merging, to live stored resources,
+//   and when merging the catalog must not repeat a name, verb of a resource
+//   or type association of the live stored rows it leaves in place; if it
+//   does, a *CatalogError lists the problems.
+func planImport(st catalogState, c Catalog, mode string) (importPlan, error) {
+	var p importPlan
+	if mode != ImportMerge && mode != ImportReplace {
+		return p, ErrInvalidImportMode
+	}
+
+	inCatalog := make(map[string]bool)
+	for _, r := range c.Resources {
+		inCatalog[r.Guid] = true
+		for _, v := range r.Verbs {
+			inCatalog[v.Guid] = true
+		}
+	}
+	for _, t := range c.Types {
+		inCatalog[t.Guid] = true
+	}
+
+	var problems []string
+	for _, t := range c.Types {
+		for _, ref := range []string{t.ResourceGUID, t.Type} {
+			if !inCatalog[ref] && (mode == ImportReplace || !st.live(ref)) {
+				problems = append(problems, fmt.Sprintf("type association %s refers to resource %s, which is not in the catalog", t.Guid, ref))
+			}
+		}
+	}
+	if mode == ImportMerge {
+		problems = append(problems, clashes(st, c, inCatalog)...)
+	}
+	if len(problems) > 0 {
+		return p, &CatalogError{problems}
+	}
+
+	changed := make(map[string]bool) // Resources created, updated or trashed
+	verbsChanged := make(map[string]bool)
+	for _, r := range c.Resources {
+		old, ok := st.resources[r.Guid]
+		switch {
+		case !ok:
+			p.insertResources = append(p.insertResources, r)
+			p.result.Created++
+		case old.deleted || old.Name != r.Name || old.Description != r.Description || old.APIEndpoint != r.APIEndpoint:
+			p.updateResources = append(p.updateResources, r)
+			p.result.Updated++
+		default:
+			p.result.Unchanged++
+			continue
+		}
+		changed[r.Guid] = true
+	}
+	for _, r := range c.Resources {
+		for _, v := range r.Verbs {
+			old, ok := st.verbs[v.Guid]
+			switch {
+			case !ok:
+				p.insertVerbs = append(p.insertVerbs, importedVerb{v, r.Guid})
+				p.result.Created++
+			case old.deleted || old.resourceGUID != r.Guid || old.Verb != v.Verb || old.Description != v.Description:
+				p.updateVerbs = append(p.updateVerbs, importedVerb{v, r.Guid})
+				p.result.Updated++
+				verbsChanged[old.resourceGUID] = true
+			default:
+				p.result.Unchanged++
+				continue
+			}
+			verbsChanged[r.Guid] = true
+		}
+	}
+	for _, t := range c.Types {
+		old, ok := st.types[t.Guid]
+		switch {
+		case !ok:
+			p.insertTypes = append(p.insertTypes, t)
+			p.result.Created++
+		case old != t:
+			p.updateTypes = append(p.updateTypes, t)
+			p.result.Updated++
+			verbsChanged[old.ResourceGUID] = true
+		default:
+			p.result.Unchanged++
+			continue
+		}
+		verbsChanged[t.ResourceGUID] = true
+	}
+
+	if mode == ImportReplace {
+		for _, guid := range st.typeOrder {
+			if !inCatalog[guid] {
+				p.deleteTypes = append(p.deleteTypes, guid)
+				p.result.Deleted++
+				verbsChanged[st.types[guid].ResourceGUID] = true
+			}
+		}
+		for _, guid := range st.verbOrder {
+			if v := st.verbs[guid]; !inCatalog[guid] && !v.deleted {
+				p.deleteVerbs = append(p.deleteVerbs, guid)
+				p.result.Deleted++
+				verbsChanged[v.resourceGUID] = true
+			}
+		}
+		for _, guid := range st.resourceOrder {
+			if !inCatalog[guid] && st.live(guid) {
+				p.deleteResources = append(p.deleteResources, guid)
+				p.result.Deleted++
+				changed[guid] = true
+			}
+		}
+	}
+
+	for _, guid := range st.resourceOrder {
+		if verbsChanged[guid] && !changed[guid] && st.live(guid) {
+			p.touch = append(p.touch, guid)
+		}
+	}
+
+	trashed := make(map[string]bool)
+	for _, guid := range p.deleteResources {
+		trashed[guid] = true
+	}
+	moved := make(map[string]bool)
+	for _, v := range p.updateVerbs {
+		old := st.verbs[v.Guid]
+		moved[v.Guid] = !old.deleted && (old.resourceGUID != v.resourceGUID || old.Verb != v.Verb)
+	}
+	for _, guid := range st.verbOrder {
+		v := st.verbs[guid]
+		if (moved[guid] || !inCatalog[guid] && !v.deleted && mode == ImportReplace) && st.live(v.resourceGUID) && !trashed[v.resourceGUID] {
+			p.removeVerbs = append(p.removeVerbs, ResourceVerb{v.Guid, v.resourceGUID, v.Verb, v.Description, v.version})
+		}
+	}
+	return p, nil
+}
+
+// Lists the names, verbs of a resource and type associations of a catalog
+//   that repeat those of the live stored rows not in it.
+func clashes(st catalogState, c Catalog, inCatalog map[string]bool) []string {
+	names := make(map[string]string)
+	verbs := make(map[[2]string]string)
+	pairs := make(map[[2]string]string)
+	for _, guid := range st.resourceOrder {
+		if r := st.resources[guid]; !inCatalog[guid] && !r.deleted {
+			names[r.Name] = guid
+		}
+	}
+	for _, guid := range st.verbOrder {
+		if v := st.verbs[guid]; !inCatalog[guid] && !v.deleted {
+			verbs[[2]string{v.resourceGUID, v.Verb}] = guid
+		}
+	}
+	for _, guid := range st.typeOrder {
+		if t := st.types[guid]; !inCatalog[guid] {
+			pairs[[2]string{t.ResourceGUID, t.Type}] = guid
+		}
+	}
+
+	var problems []string
+	for _, r := range c.Resources {
+		if other, ok := names[r.Name]; ok {
+			problems = append(problems, fmt.Sprintf("resource %s has the name %q of stored resource %s", r.Guid, r.Name, other))
+		}
+		for _, v := range r.Verbs {
+			if other, ok := verbs[[2]string{r.Guid, v.Verb}]; ok {
+				problems = append(problems, fmt.Sprintf("verb %s repeats stored verb %s of resource %s", v.Guid, other, r.Guid))
+			}
+		}
+	}
+	for _, t := range c.Types {
+		if other, ok := pairs[[2]string{t.ResourceGUID, t.Type}]; ok {
+			problems = append(problems, fmt.Sprintf("type association %s repeats stored type association %s", t.Guid, other))
+		}
+	}
+	return problems
+}
+
+// Makes the audit entries of carrying out an import plan at the given
+//   time, in the order apply writes the changes. A resource or verb brought
+//   back from the trash is recorded as restored.
+func (p importPlan) auditEntries(st catalogState, actor string, now time.Time) ([]AuditEntry, error) {
+	var entries []AuditEntry
+	add := func(operation, entity, guid, resource string, before, after interface{}) error {
+		e, err := newAuditEntry(actor, now, operation, entity, guid, resource, before, after)
+		entries = append(entries, e)
+		return err
+	}
+	storedResourceValues := func(guid string) auditedResource {
+		r := st.resources[guid]
+		return resourceValues(Resource{Guid: r.Guid, Name: r.Name, Description: r.Description, APIEndpoint: r.APIEndpoint, Created: r.created})
+	}
+	storedVerbValues := func(guid string) ResourceVerb {
+		v := st.verbs[guid]
+		return ResourceVerb{v.Guid, v.resourceGUID, v.Verb, v.Description, v.version}
+	}
+	changed := func(deleted bool) string {
+		if deleted {
+			return OpRestore
+		}
+		return OpUpdate
+	}
+
+	for _, guid := range p.deleteTypes {
+		t := st.types[guid]
+		if err := add(OpDelete, EntityType, guid, t.ResourceGUID, ResourceType{t.Guid, t.ResourceGUID, t.Type}, nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, guid := range p.deleteVerbs {
+		if err := add(OpDelete, EntityVerb, guid, st.verbs[guid].resourceGUID, storedVerbValues(guid), nil); err != nil {
+			return nil, err
+		}
+	}
+	for _, guid := range p.deleteResources {
+		if err := add(OpDelete, EntityResource, guid, guid, storedResourceValues(guid), nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range p.insertResources {
+		after := resourceValues(Resource{Guid: r.Guid, Name: r.Name, Description: r.Description, APIEndpoint: r.APIEndpoint, Created: now})
+		if err := add(OpCreate, EntityResource, r.Guid, r.Guid, nil, after); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range p.updateResources {
+		old := st.resources[r.Guid]
+		after := resourceValues(Resource{Guid: r.Guid, Name: r.Name, Description: r.Description, APIEndpoint: r.APIEndpoint, Created: old.created})
+		if err := add(changed(old.deleted), EntityResource, r.Guid, r.Guid, storedResourceValues(r.Guid), after); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range p.insertVerbs {
+		if err := add(OpCreate, EntityVerb, v.Guid, v.resourceGUID, nil, ResourceVerb{v.Guid, v.resourceGUID, v.Verb, v.Description, 1}); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range p.updateVerbs {
+		old := st.verbs[v.Guid]
+		after := ResourceVerb{v.Guid, v.resourceGUID, v.Verb, v.Description, old.version + 1}
+		if err := add(changed(old.deleted), EntityVerb, v.Guid, v.resourceGUID, storedVerbValues(v.Guid), after); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, t := range p.insertTypes {
+		if err := add(OpCreate, EntityType, t.Guid, t.ResourceGUID, nil, ResourceType{t.Guid, t.ResourceGUID, t.Type}); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range p.updateTypes {
+		old := st.types[t.Guid]
+		if err := add(OpUpdate, EntityType, t.Guid, t.ResourceGUID, ResourceType{old.Guid, old.ResourceGUID, old.Type}, ResourceType{t.Guid, t.ResourceGUID, t.Type}); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+type CatalogAccessor struct {
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+	Actor   string  // Who the changes are recorded as made by
+}
+
+// Returns a new catalog accessor.
+func NewCatalogAccessor(db *sql.DB) *CatalogAccessor {
+	return &CatalogAccessor{db, MySQL, ""}
+}
+
+// Gets every resource outside the trash with its verbs, and the type
+//   associations between them. Resources and verbs are in the order they
+//   were created.
+func (ca *CatalogAccessor) Export() (Catalog, error) {
+	st, err := ca.state(ca.DB)
+	if err != nil {
+		return Catalog{}, err
+	}
+	return st.catalog(), nil
+}
+
+// Imports a catalog in one transaction. In ImportMerge mode resources,
+//   verbs and type associations are created or updated to match the
+//   catalog, and trashed ones in it are restored. ImportReplace also moves
+//   the resources and verbs that are not in the catalog to the trash and
+//   deletes the type associations that are not. Grants go with the verbs
+//   trashed, renamed or moved as they do when a verb is removed. Every
+//   change is recorded in the audit log in the same transaction. The catalog is validated before
+//   anything is written; an invalid catalog writes nothing and returns a
+//   *CatalogError.
+func (ca *CatalogAccessor) Import(c Catalog, mode string) (ImportResult, error) {
+	if err := c.Validate(); err != nil {
+		return ImportResult{}, err
+	}
+
+	tx, err := ca.DB.Begin()
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	st, err := ca.state(tx)
+	if err != nil {
+		tx.Rollback()
+		return ImportResult{}, err
+	}
+	p, err := planImport(st, c, mode)
+	if err != nil {
+		tx.Rollback()
+		return ImportResult{}, err
+	}
+	now := Now()
+	entries, err := p.auditEntries(st, ca.Actor, now)
+	if err != nil {
+		tx.Rollback()
+		return ImportResult{}, err
+	}
+	if err := ca.apply(tx, p, now); err != nil {
+		tx.Rollback()
+		return ImportResult{}, err
+	}
+	for _, v := range p.removeVerbs {
+		if err := deleteOrphanedGrants(tx, ca.Dialect, ca.Actor, now, v); err != nil {
+			tx.Rollback()
+			return ImportResult{}, err
+		}
+	}
+	if err := recordEntries(tx, ca.Dialect, entries...); err != nil {
+		tx.Rollback()
+		return ImportResult{}, err
+	}
+	return p.result, tx.Commit()
+}
+
+// Loads every stored resource, verb and type association.
+func (ca *CatalogAccessor) state(db preparer) (catalogState, error) {
+	st := newCatalogState()
+
+	err := queryEach(db, ca.Dialect, "SELECT guid, name, description, apiEndpoint, created, deleted FROM resources ORDER BY created, guid", func(rows *sql.Rows) error {
+		var r storedResource
+		var deleted time.Time
+		if err := rows.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint, timestamp{&r.created}, timestamp{&deleted}); err != nil {
+			return err
+		}
+		r.deleted = !deleted.IsZero()
+		st.resources[r.Guid] = r
+		st.resourceOrder = append(st.resourceOrder, r.Guid)
+		return nil
+	})
+	if err != nil {
+		return st, err
+	}
+
+	err = queryEach(db, ca.Dialect, "SELECT guid, resourceGUID, verb, description, version, deleted FROM resourceVerbs ORDER BY created, guid", func(rows *sql.Rows) error {
+		var v storedVerb
+		var deleted time.Time
+		if err := rows.Scan(&v.Guid, &v.resourceGUID, &v.Verb, &v.Description, &v.version, timestamp{&deleted}); err != nil {
+			return err
+		}
+		v.deleted = !deleted.IsZero()
+		st.verbs[v.Guid] = v
+		st.verbOrder = append(st.verbOrder, v.Guid)
+		return nil
+	})
+	if err != nil {
+		return st, err
+	}
+
+	err = queryEach(db, ca.Dialect, "SELECT guid, resourceGUID, type FROM resourceTypes ORDER BY guid", func(rows *sql.Rows) error {
+		var t CatalogType
+		if err := rows.Scan(&t.Guid, &t.ResourceGUID, &t.Type); err != nil {
+			return err
+		}
+		st.types[t.Guid] = t
+		st.typeOrder = append(st.typeOrder, t.Guid)
+		return nil
+	})
+	return st, err
+}
+
+// Runs a query with the given arguments and calls scan for each row.
+func queryEach(db preparer, d Dialect, query string, scan func(*sql.Rows) error, args ...interface{}) error {
+	stmt, err := d.prepare(db, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Writes an import plan.
+func (ca *CatalogAccessor) apply(tx *sql.Tx, p importPlan, now time.Time) error {
+	var types, verbs, resources, touched [][]interface{}
+	for _, guid := range p.deleteTypes {
+		types = append(types, []interface{}{guid})
+	}
+	for _, guid := range p.deleteVerbs {
+		verbs = append(verbs, []interface{}{now, now, guid})
+	}
+	for _, guid := range p.deleteResources {
+		resources = append(resources, []interface{}{now, now, guid})
+	}
+	if err := execEach(tx, ca.Dialect, "DELETE FROM resourceTypes WHERE guid=?", types); err != nil {
+		return err
+	}
+	if err := execEach(tx, ca.Dialect, "UPDATE resourceVerbs SET deleted=?, modified=? WHERE guid=?", verbs); err != nil {
+		return err
+	}
+	if err := execEach(tx, ca.Dialect, "UPDATE resources SET deleted=?, modified=? WHERE guid=?", resources); err != nil {
+		return err
+	}
+
+	resources = nil
+	for _, r := range p.insertResources {
+		resources = append(resources, []interface{}{r.Guid, r.Name, r.Description, r.APIEndpoint, now, now})
+	}
+	if err := execEach(tx, ca.Dialect, "INSERT INTO resources (guid, name, description, apiEndpoint, created, modified) VALUES (?,?,?,?,?,?)", resources); err != nil {
+		return err
+	}
+	resources = nil
+	for _, r := range p.updateResources {
+		resources = append(resources, []interface{}{r.Name, r.Description, r.APIEndpoint, now, r.Guid})
+	}
+	if err := execEach(tx, ca.Dialect, "UPDATE resources SET name=?, description=?, apiEndpoint=?, deleted=NULL, version=version+1, modified=? WHERE guid=?", resources); err != nil {
+		return err
+	}
+
+	verbs = nil
+	for _, v := range p.insertVerbs {
+		verbs = append(verbs, []interface{}{v.Guid, v.resourceGUID, v.Verb, v.Description, now, now})
+	}
+	if err := execEach(tx, ca.Dialect, "INSERT INTO resourceVerbs (guid, resourceGUID, verb, description, created, modified) VALUES (?,?,?,?,?,?)", verbs); err != nil {
+		return err
+	}
+	verbs = nil
+	for _, v := range p.updateVerbs {
+		verbs = append(verbs, []interface{}{v.resourceGUID, v.Verb, v.Description, now, v.Guid})
+	}
+	if err := execEach(tx, ca.Dialect, "UPDATE resourceVerbs SET resourceGUID=?, verb=?, description=?, deleted=NULL, version=version+1, modified=? WHERE guid=?", verbs); err != nil {
+		return err
+	}
+
+	types = nil
+	for _, t := range p.insertTypes {
+		types = append(types, []interface{}{t.Guid, t.ResourceGUID, t.Type})
+	}
+	if err := execEach(tx, ca.Dialect, "INSERT INTO resourceTypes (guid, resourceGUID, type) VALUES (?,?,?)", types); err != nil {
+		return err
+	}
+	types = nil
+	for _, t := range p.updateTypes {
+		types = append(types, []interface{}{t.ResourceGUID, t.Type, t.Guid})
+	}
+	if err := execEach(tx, ca.Dialect, "UPDATE resourceTypes SET resourceGUID=?, type=? WHERE guid=?", types); err != nil {
+		return err
+	}
+
+	for _, guid := range p.touch {
+		touched = append(touched, []interface{}{now, guid})
+	}
+	return execEach(tx, ca.Dialect, "UPDATE resources SET version=version+1, modified=? WHERE guid=?", touched)
+}
+
+// Runs a statement once for each set of arguments.
+func execEach(tx *sql.Tx, d Dialect, query string, args [][]interface{}) error {
+	if len(args) == 0 {
+		return nil
+	}
+	stmt, err := d.prepare(tx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, a := range args {
+		if _, err := stmt.Exec(a...); err != nil {
+			return d.err(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package accessors
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Kinds of change.
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeDeleted = "deleted"
+)
+
+// A resource that changed after a point in time. A resource also changes
+//   when its verbs do. The verbs of the resource are not filled in.
+type ResourceChange struct {
+	Change   string   `json:"change"`
+	Resource Resource `json:"resource"`
+}
+
+// A verb that changed after a point in time.
+type VerbChange struct {
+	Change   string       `json:"change"`
+	Modified time.Time    `json:"modified"`
+	Verb     ResourceVerb `json:"verb"`
+}
+
+// How long a change may take to commit after it is timestamped. A change
+//   that commits late is not seen by a reader that has already seen newer
+//   ones, so Since reports again what changed this long before its point
+//   in time.
+var CommitWindow = 5 * time.Second
+
+// Changes holds the resources and verbs that changed after a point in time,
+//   or in the CommitWindow before it, oldest change first. Last is the time
+//   of the newest change, or the point in time itself if nothing changed.
+type Changes struct {
+	Resources []ResourceChange `json:"resources"`
+	Verbs     []VerbChange     `json:"verbs"`
+	Last      time.Time        `json:"-"`
+}
+
+// Returns when the changes reported since a point in time start: the
+//   CommitWindow before it, unless it is the beginning of time.
+func windowStart(since time.Time) time.Time {
+	if since.IsZero() {
+		return since
+	}
+	return since.Add(-CommitWindow)
+}
+
+// Returns the kind of change of an entity that changed after since.
+func changeKind(since, created, deleted time.Time) string {
+	switch {
+	case !deleted.IsZero():
+		return ChangeDeleted
+	case created.After(since):
+		return ChangeCreated
+	default:
+		return ChangeUpdated
+	}
+}
+
+type ChangeAccessor struct {
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+}
+
+// Returns a new change accessor.
+func NewChangeAccessor(db *sql.DB) *ChangeAccessor {
+	return &ChangeAccessor{db, MySQL}
+}
+
+// Gets the resources and verbs that were created, updated or moved to the
+//   trash after the given time, or in the CommitWindow before it; the
+//   caller drops the changes it has already seen. Anything purged from the
+//   trash since then is not reported.
+func (ca *ChangeAccessor) Since(since time.Time) (Changes, error) {
+	changes := Changes{make([]ResourceChange, 0), make([]VerbChange, 0), since}
+	from := windowStart(since)
+
+	stmt, err := ca.Dialect.prepare(ca.DB, "SELECT "+resourceColumns+", deleted FROM resources WHERE modified>? ORDER BY modified, guid")
+	if err != nil {
+		return changes, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(from)
+	if err != nil {
+		return changes, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Resource
+		var deleted time.Time
+		if err := rows.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint, timestamp{&r.Created}, timestamp{&r.Modified}, &r.Version, timestamp{&deleted}); err != nil {
+			return changes, err
+		}
+		changes.Resources = append(changes.Resources, ResourceChange{changeKind(since, r.Created, deleted), r})
+		if r.Modified.After(changes.Last) {
+			changes.Last = r.Modified
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return changes, err
+	}
+
+	verbStmt, err := ca.Dialect.prepare(ca.DB, "SELECT "+verbColumns+", created, modified, deleted FROM resourceVerbs WHERE modified>? ORDER BY modified, guid")
+	if err != nil {
+		return changes, err
+	}
+	defer verbStmt.Close()
+
+	verbRows, err := verbStmt.Query(from)
+	if err != nil {
+		return changes, err
+	}
+	defer verbRows.Close()
+
+	for verbRows.Next() {
+		var v VerbChange
+		var created, deleted time.Time
+		if err := verbRows.Scan(&v.Verb.Guid, &v.Verb.ResourceGUID, &v.Verb.Verb, &v.Verb.Description, &v.Verb.Version, timestamp{&created}, timestamp{&v.Modified}, timestamp{&deleted}); err != nil {
+			return changes, err
+		}
+		v.Change = changeKind(since, created, deleted)
+		changes.Verbs = append(changes.Verbs, v)
+		if v.Modified.After(changes.Last) {
+			changes.Last = v.Modified
+		}
+	}
+	return changes, verbRows.Err()
+}
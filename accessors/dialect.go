@@ -0,0 +1,56 @@
+package accessors
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// Dialect captures what differs between the SQL databases the accessors
+//   run against. The accessors write their queries with MySQL style ?
+//   placeholders and the dialect rewrites them when needed.
+type Dialect struct {
+	Name string // Name of the database/sql driver
+
+	migrations string                    // Directory of the dialect's migrations
+	ddlCommits bool                      // Whether DDL statements commit on their own, outside any transaction
+	bind       func(query string) string // Rewrites ? placeholders
+	translate  func(err error) error     // Maps driver errors to ErrDuplicate and ErrForeignKey
+	hasTable   string                    // Counts the tables of the current database with the name given
+}
+
+// Anything statements can be prepared on: *sql.DB or *sql.Tx.
+type preparer interface {
+	Prepare(query string) (*sql.Stmt, error)
+}
+
+// Prepares query on db using the dialect's placeholders.
+func (d Dialect) prepare(db preparer, query string) (*sql.Stmt, error) {
+	if d.bind != nil {
+		query = d.bind(query)
+	}
+	return db.Prepare(query)
+}
+
+// Translates a driver error into one of this package's errors, if it has one.
+func (d Dialect) err(err error) error {
+	if err == nil || d.translate == nil {
+		return err
+	}
+	return d.translate(err)
+}
+
+// Rewrites ? placeholders to the numbered $1, $2, ... form.
+func bindDollar(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
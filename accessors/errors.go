@@ -0,0 +1,146 @@
+package accessors
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of error. Each error the accessors return for a problem with what
+//   was asked, rather than with the database, matches one of them with
+//   errors.Is, so callers can tell a missing guid or a clash from a fault.
+var (
+	// The entity asked for does not exist or is in the trash.
+	ErrNotFound = errors.New("accessors: not found")
+
+	// A write clashes with what is stored.
+	ErrConflict = errors.New("accessors: conflict")
+
+	// A request is malformed.
+	ErrInvalid = errors.New("accessors: invalid")
+)
+
+var (
+	// A write would have duplicated a value that must be unique.
+	ErrDuplicate = kindError(ErrConflict, "accessors: duplicate entry")
+
+	// A write referred to a row that does not exist, or would have removed
+	//   a row that other rows still refer to.
+	ErrForeignKey = kindError(ErrConflict, "accessors: foreign key violation")
+
+	// An update was based on a version of a row that is no longer current.
+	ErrVersionMismatch = kindError(ErrConflict, "accessors: version mismatch")
+)
+
+// An error of one of the kinds.
+type kinded struct {
+	kind error
+	msg  string
+}
+
+// Returns a new error of the given kind.
+func kindError(kind error, msg string) error {
+	return &kinded{kind, msg}
+}
+
+func (e *kinded) Error() string {
+	return e.msg
+}
+
+func (e *kinded) Is(target error) bool {
+	return target == e.kind
+}
+
+// NotFoundError is returned when the entity asked for does not exist or
+//   is in the trash. It matches ErrNotFound and, as the accessors used to
+//   return that, sql.ErrNoRows with errors.Is.
+type NotFoundError struct {
+	Entity string // One of the Entity constants
+	Guid   string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("accessors: no %s %s", e.Entity, e.Guid)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound || target == sql.ErrNoRows
+}
+
+// Turns the sql.ErrNoRows of a missing row into a *NotFoundError naming
+//   the entity. Other errors are returned as they are.
+func notFound(err error, entity, guid string) error {
+	if err == sql.ErrNoRows {
+		return &NotFoundError{entity, guid}
+	}
+	return err
+}
+
+// DuplicateError is returned when a write would give a second live
+//   resource a name, a resource a second verb of a name or a resource the
+//   same type twice. It names the entity already holding the value, and
+//   matches ErrDuplicate and ErrConflict with errors.Is.
+type DuplicateError struct {
+	Entity string // One of the Entity constants
+	Guid   string // Guid of the existing entity
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("accessors: duplicates %s %s", e.Entity, e.Guid)
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate || target == ErrConflict
+}
+
+// Turns the ErrDuplicate of a write into a *DuplicateError naming the live
+//   entity it clashed with, which query selects the guid of. If none is
+//   found, as when the clash was on a guid, ErrDuplicate is returned as it
+//   is. The write's transaction must be over, as some databases refuse
+//   queries in a transaction after a failed statement.
+func duplicate(db preparer, d Dialect, err error, entity, query string, args ...interface{}) error {
+	if err != ErrDuplicate {
+		return err
+	}
+	stmt, prepErr := d.prepare(db, query)
+	if prepErr != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var guid string
+	if stmt.QueryRow(args...).Scan(&guid) != nil {
+		return err
+	}
+	return &DuplicateError{entity, guid}
+}
+
+// DependentsError is returned when deleting a resource that verbs or type
+//   associations still refer to. It matches ErrForeignKey and ErrConflict
+//   with errors.Is.
+type DependentsError struct {
+	Dependents Dependents
+}
+
+func (e *DependentsError) Error() string {
+	return fmt.Sprintf("accessors: resource still has %d verbs and %d type associations", len(e.Dependents.Verbs), len(e.Dependents.Types))
+}
+
+func (e *DependentsError) Is(target error) bool {
+	return target == ErrForeignKey || target == ErrConflict
+}
+
+// CatalogError lists the problems that stopped a catalog from being
+//   imported.
+type CatalogError struct {
+	Problems []string
+}
+
+func (e *CatalogError) Error() string {
+	return "accessors: invalid catalog: " + strings.Join(e.Problems, "; ")
+}
+
+func (e *CatalogError) Is(target error) bool {
+	return target == ErrInvalid
+}
@@ -0,0 +1,355 @@
+package accessors
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+var (
+	// A grant named both a principal and a role, or neither.
+	ErrInvalidGrant = kindError(ErrInvalid, "accessors: a grant needs exactly one of a principal and a role")
+
+	// A grant named a verb its resource does not have, neither its own
+	//   nor inherited.
+	ErrUnknownVerb = kindError(ErrInvalid, "accessors: the resource has no such verb")
+)
+
+// Grant allows a principal, or every principal with a role, to perform a
+//   verb on a resource. Exactly one of Principal and Role is set.
+type Grant struct {
+	Guid         string `json:"guid"`
+	Principal    string `json:"principal,omitempty"`
+	Role         string `json:"role,omitempty"`
+	ResourceGUID string `json:"resourceGUID"`
+	Verb         string `json:"verb"`
+	Version      int    `json:"version"` // Incremented by every update
+}
+
+// Returns ErrInvalidGrant unless exactly one of the grant's principal and
+//   role is set.
+func (g Grant) validate() error {
+	if (g.Principal == "") == (g.Role == "") {
+		return ErrInvalidGrant
+	}
+	return nil
+}
+
+// GrantQuery selects grants. Zero fields match everything.
+type GrantQuery struct {
+	Principal    string
+	Role         string
+	ResourceGUID string
+	Verb         string
+}
+
+// The columns of the grants table, in the order scanGrant reads them.
+const grantColumns = "guid, principal, role, resourceGUID, verb, version"
+
+// Scans the grantColumns of a row into g.
+func scanGrant(row scanner, g *Grant) error {
+	return row.Scan(&g.Guid, &g.Principal, &g.Role, &g.ResourceGUID, &g.Verb, &g.Version)
+}
+
+// Returns whether the verb of the given name is one of a resource's
+//   effective verbs.
+func hasVerb(src inheritanceSource, resource, verb string) (bool, error) {
+	inheritance, err := inherit(resource, src)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range inheritance.Verbs {
+		if v.Verb == verb {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type GrantAccessor struct {
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+	Actor   string  // Who the changes are recorded as made by
+}
+
+// Returns a new grant accessor.
+func NewGrantAccessor(db *sql.DB) *GrantAccessor {
+	return &GrantAccessor{db, MySQL, ""}
+}
+
+// Gets a grant by guid. The grants of resources in the trash are hidden.
+func (ga *GrantAccessor) Get(guid string) (Grant, error) {
+	var g Grant
+	stmt, err := ga.Dialect.prepare(ga.DB, "SELECT "+grantColumns+" FROM grants WHERE guid=? AND resourceGUID IN (SELECT guid FROM resources WHERE deleted IS NULL)")
+	if err != nil {
+		return g, err
+	}
+	defer stmt.Close()
+
+	err = scanGrant(stmt.QueryRow(guid), &g)
+	return g, notFound(err, EntityGrant, guid)
+}
+
+// Gets the grants matching the query in the order they were made. The
+//   grants of resources in the trash are left out.
+func (ga *GrantAccessor) List(q GrantQuery) ([]Grant, error) {
+	grants := make([]Grant, 0)
+
+	where := []string{"resourceGUID IN (SELECT guid FROM resources WHERE deleted IS NULL)"}
+	var args []interface{}
+	for _, filter := range []struct {
+		column string
+		value  string
+	}{
+		{"principal", q.Principal},
+		{"role", q.Role},
+		{"resourceGUID", q.ResourceGUID},
+		{"verb", q.Verb},
+	} {
+		if filter.value != "" {
+			where = append(where, filter.column+"=?")
+			args = append(args, filter.value)
+		}
+	}
+
+	err := queryEach(ga.DB, ga.Dialect, "SELECT "+grantColumns+" FROM grants WHERE "+strings.Join(where, " AND ")+" ORDER BY created, guid", func(rows *sql.Rows) error {
+		var g Grant
+		if err := scanGrant(rows, &g); err != nil {
+			return err
+		}
+		grants = append(grants, g)
+		return nil
+	}, args...)
+	return grants, err
+}
+
+// Checks, in a transaction, that a grant's resource is outside the trash
+//   and has its verb.
+func checkGrant(tx *sql.Tx, d Dialect, g Grant) error {
+	stmt, err := d.prepare(tx, "SELECT COUNT(*) FROM resources WHERE guid=? AND deleted IS NULL")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var count int
+	if err := stmt.QueryRow(g.ResourceGUID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrForeignKey
+	}
+
+	ok, err := hasVerb(sqlInheritance{tx, d}, g.ResourceGUID, g.Verb)
+	if err == nil && !ok {
+		err = ErrUnknownVerb
+	}
+	return err
+}
+
+// Makes a grant and returns its guid. Returns ErrInvalidGrant unless it
+//   has exactly one of a principal and a role, ErrForeignKey if the
+//   resource does not exist or is in the trash, ErrUnknownVerb if the
+//   resource does not have the verb and ErrDuplicate if the same grant
+//   exists.
+func (ga *GrantAccessor) Add(g Grant) (string, error) {
+	if err := g.validate(); err != nil {
+		return "", err
+	}
+	tx, err := ga.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkGrant(tx, ga.Dialect, g); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	guid, now := NewGuid(), Now()
+	if err := execTx(tx, ga.Dialect, "INSERT INTO grants (guid, principal, role, resourceGUID, verb, created, modified) VALUES (?,?,?,?,?,?,?)", guid, g.Principal, g.Role, g.ResourceGUID, g.Verb, now, now); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	g.Guid, g.Version = guid, 1
+	if err := record(tx, ga.Dialect, ga.Actor, now, OpCreate, EntityGrant, guid, g.ResourceGUID, nil, g); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	return guid, tx.Commit()
+}
+
+// Changes who a grant is for or what it allows and bumps its version,
+//   provided g.Version is still the stored version. Returns the errors of
+//   Add, ErrVersionMismatch if the grant has changed since that version
+//   and a *NotFoundError if it does not exist.
+func (ga *GrantAccessor) Update(g Grant) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+	tx, err := ga.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := checkGrant(tx, ga.Dialect, g); err != nil {
+		tx.Rollback()
+		return err
+	}
+	before, err := getGrant(tx, ga.Dialect, g.Guid)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := ga.Dialect.prepare(tx, "UPDATE grants SET principal=?, role=?, resourceGUID=?, verb=?, version=version+1, modified=? WHERE guid=? AND version=?")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	now := Now()
+	result, err := stmt.Exec(g.Principal, g.Role, g.ResourceGUID, g.Verb, now, g.Guid, g.Version)
+	if err != nil {
+		tx.Rollback()
+		return ga.Dialect.err(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			// Nothing was updated; find out why
+			err = versionMismatch(tx, ga.Dialect, "SELECT COUNT(*) FROM grants WHERE guid=?", EntityGrant, g.Guid)
+		}
+		tx.Rollback()
+		return err
+	}
+
+	g.Version++
+	if err := record(tx, ga.Dialect, ga.Actor, now, OpUpdate, EntityGrant, g.Guid, g.ResourceGUID, before, g); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Revokes a grant, provided version is still its stored version. Grants
+//   have no trash; the grant is deleted. Returns ErrVersionMismatch if it
+//   has changed since that version and a *NotFoundError if it does not
+//   exist.
+func (ga *GrantAccessor) Remove(guid string, version int) error {
+	tx, err := ga.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	grant, err := getGrant(tx, ga.Dialect, guid)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := ga.Dialect.prepare(tx, "DELETE FROM grants WHERE guid=? AND version=?")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(guid, version)
+	if err != nil {
+		tx.Rollback()
+		return ga.Dialect.err(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			// Nothing was deleted; find out why
+			err = versionMismatch(tx, ga.Dialect, "SELECT COUNT(*) FROM grants WHERE guid=?", EntityGrant, guid)
+		}
+		tx.Rollback()
+		return err
+	}
+	if err := record(tx, ga.Dialect, ga.Actor, Now(), OpDelete, EntityGrant, guid, grant.ResourceGUID, grant, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Gets a grant in a transaction, whether or not its resource is in the
+//   trash.
+func getGrant(tx *sql.Tx, d Dialect, guid string) (Grant, error) {
+	var g Grant
+	stmt, err := d.prepare(tx, "SELECT "+grantColumns+" FROM grants WHERE guid=?")
+	if err != nil {
+		return g, err
+	}
+	defer stmt.Close()
+
+	err = scanGrant(stmt.QueryRow(guid), &g)
+	return g, notFound(err, EntityGrant, guid)
+}
+
+// Deletes the grants of a removed verb's name from its resource, and from
+//   the live resources inheriting from it, that are left without a verb of
+//   that name, recording each deletion in the audit log as made by actor.
+//   Grants on other resources, including trashed ones, are left alone.
+func deleteOrphanedGrants(tx *sql.Tx, d Dialect, actor string, now time.Time, verb ResourceVerb) error {
+	resources, err := inheritors(tx, d, verb.ResourceGUID)
+	if err != nil {
+		return err
+	}
+
+	var entries []AuditEntry
+	var deleted [][]interface{}
+	for _, resource := range resources {
+		ok, err := hasVerb(sqlInheritance{tx, d}, resource, verb.Verb)
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		err = queryEach(tx, d, "SELECT "+grantColumns+" FROM grants WHERE resourceGUID=? AND verb=? ORDER BY created, guid", func(rows *sql.Rows) error {
+			var g Grant
+			if err := scanGrant(rows, &g); err != nil {
+				return err
+			}
+			e, err := newAuditEntry(actor, now, OpDelete, EntityGrant, g.Guid, g.ResourceGUID, g, nil)
+			entries = append(entries, e)
+			deleted = append(deleted, []interface{}{g.Guid})
+			return err
+		}, resource, verb.Verb)
+		if err != nil {
+			return err
+		}
+	}
+	if err := execEach(tx, d, "DELETE FROM grants WHERE guid=?", deleted); err != nil {
+		return err
+	}
+	return recordEntries(tx, d, entries...)
+}
+
+// Gets a resource and the live resources of its type, directly or through
+//   other types, nearest first.
+func inheritors(tx *sql.Tx, d Dialect, resource string) ([]string, error) {
+	found := map[string]bool{resource: true}
+	resources := []string{resource}
+	for i := 0; i < len(resources); i++ {
+		err := queryEach(tx, d, "SELECT resourceTypes.resourceGUID FROM resourceTypes JOIN resources ON resources.guid=resourceTypes.resourceGUID WHERE resourceTypes.type=? AND resources.deleted IS NULL ORDER BY resourceTypes.guid", func(rows *sql.Rows) error {
+			var guid string
+			if err := rows.Scan(&guid); err != nil {
+				return err
+			}
+			if !found[guid] {
+				found[guid] = true
+				resources = append(resources, guid)
+			}
+			return nil
+		}, resources[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resources, nil
+}
@@ -0,0 +1,265 @@
+package accessors
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	testhelpers "github.com/byu-oit-ssengineering/tmt-test-helpers"
+	"testing"
+)
+
+// Expects the queries resolving the effective verbs of a resource without
+//   types, which has the given verbs as CSV.
+func expectEffectiveVerbs(resource, verbs string) {
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID IN \\((.)\\)").
+		WithArgs(resource).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}).FromCSVString(verbs))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM suppressedVerbs WHERE resourceGUID IN \\((.)\\)").
+		WithArgs(resource).
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceTypes JOIN resources ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID IN \\((.)\\)").
+		WithArgs(resource).
+		WillReturnRows(sqlmock.NewRows([]string{"resourceGUID", "guid", "name", "description", "apiEndpoint", "created", "modified", "version"}))
+}
+
+func TestGetGrant(t *testing.T) {
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred creating the mock database")
+		return
+	}
+
+	ga := NewGrantAccessor(db)
+
+	expected := Grant{"11111111-2222-3333-4444-555555555555", "jdoe", "", "11111111-1111-1111-1111-111111111111", "edit", 1}
+	columns := []string{"guid", "principal", "role", "resourceGUID", "verb", "version"}
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM grants WHERE guid=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,jdoe,,11111111-1111-1111-1111-111111111111,edit,1"))
+	grant, err := ga.Get("11111111-2222-3333-4444-555555555555")
+	if err != nil {
+		t.Errorf("An unexpected error occurred while getting a grant %v", err)
+	}
+
+	if grant != expected {
+		t.Errorf("Expected %v but got %v", expected, grant)
+	}
+
+	if err := ga.DB.Close(); err != nil {
+		t.Errorf("An error occurred: %v", err)
+	}
+}
+
+func TestListGrants(t *testing.T) {
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred creating the mock database")
+		return
+	}
+
+	ga := NewGrantAccessor(db)
+
+	expected := []Grant{
+		Grant{"11111111-2222-3333-4444-555555555555", "jdoe", "", "11111111-1111-1111-1111-111111111111", "edit", 1},
+		Grant{"00000000-9999-8888-7777-666666666666", "", "staff", "11111111-1111-1111-1111-111111111111", "edit", 2},
+	}
+	columns := []string{"guid", "principal", "role", "resourceGUID", "verb", "version"}
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM grants WHERE (.+) AND resourceGUID=(.) AND verb=(.) ORDER BY created, guid").
+		WithArgs("11111111-1111-1111-1111-111111111111", "edit").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,jdoe,,11111111-1111-1111-1111-111111111111,edit,1\n00000000-9999-8888-7777-666666666666,,staff,11111111-1111-1111-1111-111111111111,edit,2"))
+	grants, err := ga.List(GrantQuery{ResourceGUID: "11111111-1111-1111-1111-111111111111", Verb: "edit"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred while listing grants %v", err)
+	}
+
+	if len(grants) != len(expected) {
+		t.Errorf("Expected %v but got %v", expected, grants)
+	}
+	for i := 0; i < len(grants) && i < len(expected); i++ {
+		if grants[i] != expected[i] {
+			t.Errorf("Expected %v but got %v", expected, grants)
+		}
+	}
+
+	if err := ga.DB.Close(); err != nil {
+		t.Errorf("An error occurred: %v", err)
+	}
+}
+
+func TestInsertGrant(t *testing.T) {
+	NewGuid = func() string {
+		return "123def"
+	}
+	stopClock()
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred creating the mock database")
+		return
+	}
+
+	ga := NewGrantAccessor(db)
+
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-1111-1111-1111-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).FromCSVString("1"))
+	expectEffectiveVerbs("11111111-1111-1111-1111-111111111111", "11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,edit,can edit,1")
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO grants .+ VALUES .+").
+		WithArgs("123def", "jdoe", "", "11111111-1111-1111-1111-111111111111", "edit", testTime, testTime).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
+
+	guid, err := ga.Add(Grant{Principal: "jdoe", ResourceGUID: "11111111-1111-1111-1111-111111111111", Verb: "edit"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred while adding a grant: %v", err)
+	}
+	if guid != "123def" {
+		t.Errorf("Expected guid 123def but got %v", guid)
+	}
+
+	if err := ga.DB.Close(); err != nil {
+		t.Errorf("An error occurred: %v", err)
+	}
+}
+
+func TestInsertGrantUnknownVerb(t *testing.T) {
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred creating the mock database")
+		return
+	}
+
+	ga := NewGrantAccessor(db)
+
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-1111-1111-1111-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).FromCSVString("1"))
+	expectEffectiveVerbs("11111111-1111-1111-1111-111111111111", "11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,edit,can edit,1")
+	sqlmock.ExpectRollback()
+
+	if _, err := ga.Add(Grant{Role: "staff", ResourceGUID: "11111111-1111-1111-1111-111111111111", Verb: "erase"}); err != ErrUnknownVerb {
+		t.Errorf("Expected %v but got %v", ErrUnknownVerb, err)
+	}
+	if _, err := ga.Add(Grant{Principal: "jdoe", Role: "staff", ResourceGUID: "11111111-1111-1111-1111-111111111111", Verb: "edit"}); err != ErrInvalidGrant {
+		t.Errorf("Expected %v but got %v", ErrInvalidGrant, err)
+	}
+
+	if err := ga.DB.Close(); err != nil {
+		t.Errorf("An error occurred: %v", err)
+	}
+}
+
+func TestUpdateGrant(t *testing.T) {
+	stopClock()
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred creating the mock database")
+		return
+	}
+
+	ga := NewGrantAccessor(db)
+
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-1111-1111-1111-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).FromCSVString("1"))
+	expectEffectiveVerbs("11111111-1111-1111-1111-111111111111", "11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,edit,can edit,1")
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM grants WHERE guid=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "principal", "role", "resourceGUID", "verb", "version"}).FromCSVString("11111111-2222-3333-4444-555555555555,jdoe,,11111111-1111-1111-1111-111111111111,edit,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE grants SET principal=(.), role=(.), resourceGUID=(.), verb=(.), version=version\\+1, modified=(.) WHERE guid=(.) AND version=(.)").
+		WithArgs("", "staff", "11111111-1111-1111-1111-111111111111", "edit", testTime, "11111111-2222-3333-4444-555555555555", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
+
+	err = ga.Update(Grant{"11111111-2222-3333-4444-555555555555", "", "staff", "11111111-1111-1111-1111-111111111111", "edit", 1})
+	if err != nil {
+		t.Errorf("An unexpected error occurred while updating a grant: %v", err)
+	}
+
+	if err := ga.DB.Close(); err != nil {
+		t.Errorf("An error occurred: %v", err)
+	}
+}
+
+func TestDeleteGrant(t *testing.T) {
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred creating the mock database")
+		return
+	}
+
+	ga := NewGrantAccessor(db)
+
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM grants WHERE guid=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "principal", "role", "resourceGUID", "verb", "version"}).FromCSVString("11111111-2222-3333-4444-555555555555,jdoe,,11111111-1111-1111-1111-111111111111,edit,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("DELETE FROM grants WHERE guid=(.) AND version=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
+
+	err = ga.Remove("11111111-2222-3333-4444-555555555555", 1)
+	if err != nil {
+		t.Errorf("An unexpected error occurred while deleting a grant: %v", err)
+	}
+
+	if err := ga.DB.Close(); err != nil {
+		t.Errorf("An error occurred: %v", err)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred creating the mock database")
+		return
+	}
+
+	ga := NewGrantAccessor(db)
+
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-1111-1111-1111-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).FromCSVString("1"))
+	expectEffectiveVerbs("11111111-1111-1111-1111-111111111111", "11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,edit,can edit,1")
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM grants WHERE verb IN \\((.)\\) AND \\(principal IN \\((.)\\) OR role IN \\((.)\\)\\) AND resourceGUID IN \\((.)\\)").
+		WithArgs("edit", "jdoe", "staff", "11111111-1111-1111-1111-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "principal", "role", "resourceGUID", "verb", "version"}).FromCSVString("11111111-2222-3333-4444-666666666666,,staff,11111111-1111-1111-1111-111111111111,edit,1"))
+	decisions, err := ga.Authorize([]AccessRequest{AccessRequest{"jdoe", []string{"staff"}, "11111111-1111-1111-1111-111111111111", "edit"}})
+	if err != nil {
+		t.Errorf("An unexpected error occurred while deciding a request %v", err)
+	}
+
+	expected := Grant{"11111111-2222-3333-4444-666666666666", "", "staff", "11111111-1111-1111-1111-111111111111", "edit", 1}
+	if len(decisions) != 1 || decisions[0].Decision != DecisionAllow || *decisions[0].Grant != expected {
+		t.Errorf("Expected %v to allow the request but got %v", expected, decisions)
+	}
+
+	if err := ga.DB.Close(); err != nil {
+		t.Errorf("An error occurred: %v", err)
+	}
+}
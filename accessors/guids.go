@@ -0,0 +1,303 @@
+package accessors
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	rules "github.com/byu-oit-ssengineering/tmt-resources/rules"
+	"github.com/satori/go.uuid"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// GuidSource makes the guids of new entities.
+type GuidSource interface {
+	Guid() (string, error)
+}
+
+// The source NewGuid draws from and how often it has fallen back.
+var (
+	guidSourceMu sync.RWMutex
+	guidSource   GuidSource = UUIDv4{}
+	guidFallback uint64
+)
+
+// Makes NewGuid draw from src.
+func SetGuidSource(src GuidSource) {
+	guidSourceMu.Lock()
+	defer guidSourceMu.Unlock()
+	guidSource = src
+}
+
+// Reports whether the source NewGuid draws from can make guids. Local
+//   sources always can; a source that depends on a service is checked by
+//   its Check method.
+func CheckGuidSource() error {
+	guidSourceMu.RLock()
+	src := guidSource
+	guidSourceMu.RUnlock()
+
+	if checker, ok := src.(interface{ Check() error }); ok {
+		return checker.Check()
+	}
+	return nil
+}
+
+// Returns how many guids NewGuid has made locally because its source
+//   failed.
+func GuidFallbacks() uint64 {
+	return atomic.LoadUint64(&guidFallback)
+}
+
+// Returns a guid from the source set with SetGuidSource, a local UUIDv4
+//   until one is set. If the source fails a UUIDv4 is made instead, and
+//   the fallback is logged and counted.
+var NewGuid = func() string {
+	guidSourceMu.RLock()
+	src := guidSource
+	guidSourceMu.RUnlock()
+
+	guid, err := src.Guid()
+	if err != nil {
+		atomic.AddUint64(&guidFallback, 1)
+		// An open circuit was logged when it opened
+		if err != ErrCircuitOpen {
+			slog.Warn("making a local guid; the guid source failed", "error", err)
+		}
+		guid, _ = UUIDv4{}.Guid()
+	}
+	return guid
+}
+
+// UUIDv4 makes random guids locally.
+type UUIDv4 struct{}
+
+func (UUIDv4) Guid() (string, error) {
+	return uuid.NewV4().String(), nil
+}
+
+// UUIDv7 makes guids locally that sort in the order they were made: a
+//   millisecond timestamp, then a counter for guids made in the same
+//   millisecond, then random bits.
+type UUIDv7 struct {
+	mu     sync.Mutex
+	last   int64  // Timestamp of the last guid, in Unix milliseconds
+	serial uint16 // Counter within the last millisecond, 12 bits
+}
+
+func (u *UUIDv7) Guid() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", err
+	}
+
+	u.mu.Lock()
+	ms := time.Now().UnixNano() / int64(time.Millisecond)
+	if ms > u.last {
+		u.last, u.serial = ms, uint16(b[6]&0x07)<<8|uint16(b[7])
+	} else if u.serial++; u.serial > 0xfff {
+		// The counter ran out; borrow the next millisecond
+		u.last, u.serial = u.last+1, 0
+	}
+	ms, serial := u.last, u.serial
+	u.mu.Unlock()
+
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> uint(40-8*i))
+	}
+	b[6] = 0x70 | byte(serial>>8)
+	b[7] = byte(serial)
+	b[8] = 0x80 | b[8]&0x3f
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// Returned while a RemoteGuids' circuit is open.
+var ErrCircuitOpen = errors.New("accessors: the guid service is failing; not calling it for now")
+
+// Configures a RemoteGuids. Zero fields take the defaults.
+type RemoteGuidConfig struct {
+	URL              string        // Endpoint returning {"Status": "OK", "Data": "<guid>"}
+	Timeout          time.Duration // Longest wait for one guid, 2s by default
+	PoolSize         int           // Guids fetched ahead of use, 100 by default
+	FailureThreshold int           // Failures in a row that open the circuit, 5 by default
+	Cooldown         time.Duration // How long the circuit stays open, 30s by default
+}
+
+// RemoteGuids gets guids from the TMT guid service. It keeps a pool of
+//   guids fetched in the background so making one rarely waits on the
+//   network. After FailureThreshold failures in a row its circuit opens
+//   and it fails fast with ErrCircuitOpen for the Cooldown, after which
+//   it lets one call through to try the service again. A response that is
+//   not a guid counts as a failure.
+type RemoteGuids struct {
+	config RemoteGuidConfig
+	client *http.Client
+	pool   chan string
+	refill chan struct{}
+	done   chan struct{}
+	start  sync.Once
+
+	mu        sync.Mutex
+	failures  int       // Failures in a row
+	openUntil time.Time // When the open circuit lets a call through again
+	probing   bool      // Whether a call is trying the service after the cooldown
+}
+
+// Returns a remote guid source. It starts fetching once the first guid
+//   is asked for; call Close to stop it.
+func NewRemoteGuids(config RemoteGuidConfig) *RemoteGuids {
+	if config.Timeout <= 0 {
+		config.Timeout = 2 * time.Second
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 100
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = 30 * time.Second
+	}
+	return &RemoteGuids{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		pool:   make(chan string, config.PoolSize),
+		refill: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// Takes a guid from the pool, or fetches one if the pool is empty.
+func (rg *RemoteGuids) Guid() (string, error) {
+	rg.start.Do(func() { go rg.fill() })
+	defer rg.topUp()
+
+	select {
+	case guid := <-rg.pool:
+		return guid, nil
+	default:
+	}
+	return rg.fetch()
+}
+
+// Checks that the service can be reached by fetching a guid from it, which
+//   is kept in the pool. Returns ErrCircuitOpen without calling it while
+//   the circuit is open.
+func (rg *RemoteGuids) Check() error {
+	guid, err := rg.fetch()
+	if err != nil {
+		return err
+	}
+	select {
+	case rg.pool <- guid:
+	default:
+	}
+	return nil
+}
+
+// Stops fetching guids in the background.
+func (rg *RemoteGuids) Close() {
+	rg.start.Do(func() {})
+	select {
+	case <-rg.done:
+	default:
+		close(rg.done)
+	}
+}
+
+// Asks the background fetcher to fill the pool.
+func (rg *RemoteGuids) topUp() {
+	select {
+	case rg.refill <- struct{}{}:
+	default:
+	}
+}
+
+// Fills the pool whenever asked to, until closed. A failure leaves the
+//   pool as it is until the next request.
+func (rg *RemoteGuids) fill() {
+	for {
+		select {
+		case <-rg.refill:
+		case <-rg.done:
+			return
+		}
+		for len(rg.pool) < cap(rg.pool) {
+			guid, err := rg.fetch()
+			if err != nil {
+				break
+			}
+			select {
+			case rg.pool <- guid:
+			case <-rg.done:
+				return
+			}
+		}
+	}
+}
+
+// Gets one guid from the service, unless the circuit is open. Once the
+//   cooldown is over only one call at a time tries the service; the others
+//   fail fast until it succeeds or opens the circuit again.
+func (rg *RemoteGuids) fetch() (string, error) {
+	rg.mu.Lock()
+	halfOpen := rg.failures >= rg.config.FailureThreshold
+	if time.Now().Before(rg.openUntil) || (halfOpen && rg.probing) {
+		rg.mu.Unlock()
+		return "", ErrCircuitOpen
+	}
+	rg.probing = halfOpen
+	rg.mu.Unlock()
+
+	guid, err := rg.get()
+
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+	rg.probing = false
+	if err == nil {
+		if rg.failures >= rg.config.FailureThreshold {
+			slog.Info("the guid service has recovered", "url", rg.config.URL)
+		}
+		rg.failures = 0
+		return guid, nil
+	}
+	if rg.failures++; rg.failures >= rg.config.FailureThreshold {
+		rg.openUntil = time.Now().Add(rg.config.Cooldown)
+		slog.Warn("the guid service keeps failing; not calling it for now", "url", rg.config.URL, "failures", rg.failures, "cooldown", rg.config.Cooldown, "error", err)
+	}
+	return "", err
+}
+
+// Struct to model the response from the guid generator micro-service.
+type guidResponse struct {
+	Status string
+	Data   string
+}
+
+// Calls the service for one guid.
+func (rg *RemoteGuids) get() (string, error) {
+	res, err := rg.client.Get(rg.config.URL)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the guid service responded %s", res.Status)
+	}
+	var body guidResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("the guid service sent a malformed response: %v", err)
+	}
+	if body.Status != "OK" {
+		return "", fmt.Errorf("the guid service sent status %q", body.Status)
+	}
+	if !rules.GuidPattern.MatchString(body.Data) {
+		return "", fmt.Errorf("the guid service sent %q, which is not a guid", body.Data)
+	}
+	return body.Data, nil
+}
@@ -0,0 +1,319 @@
+package accessors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// NewGuid as the package defines it; the store tests replace it.
+var sourcedGuid = NewGuid
+
+// Matches a UUID of the given version in canonical form.
+func uuidPattern(version string) *regexp.Regexp {
+	return regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-" + version + "[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+}
+
+// A stand-in for the guid service. It hands out sequential guids while
+//   healthy and responds 500 otherwise, and counts the calls it gets.
+type guidService struct {
+	mu      sync.Mutex
+	calls   int
+	healthy bool
+	delay   time.Duration
+}
+
+func (gs *guidService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	gs.mu.Lock()
+	gs.calls++
+	n, healthy, delay := gs.calls, gs.healthy, gs.delay
+	gs.mu.Unlock()
+
+	time.Sleep(delay)
+	if !healthy {
+		w.WriteHeader(500)
+		fmt.Fprint(w, `{"Status": "ERROR", "Data": "unavailable"}`)
+		return
+	}
+	fmt.Fprintf(w, `{"Status": "OK", "Data": "00000000-0000-0000-0000-%012d"}`, n)
+}
+
+func (gs *guidService) set(healthy bool, delay time.Duration) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	gs.healthy, gs.delay = healthy, delay
+}
+
+func (gs *guidService) count() int {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	return gs.calls
+}
+
+// Waits up to a second for f to return true.
+func eventually(f func() bool) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if f() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRemoteGuidsPrefetch(t *testing.T) {
+	service := &guidService{healthy: true}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	rg := NewRemoteGuids(RemoteGuidConfig{URL: server.URL, PoolSize: 10})
+	defer rg.Close()
+
+	// The first guid is fetched on demand, then the pool is filled
+	if guid, err := rg.Guid(); err != nil || guid != "00000000-0000-0000-0000-000000000001" {
+		t.Errorf("Expected 00000000-0000-0000-0000-000000000001 but got %v, %v", guid, err)
+	}
+	if !eventually(func() bool { return len(rg.pool) == 10 }) {
+		t.Fatalf("Expected a pool of 10 guids but got %d", len(rg.pool))
+	}
+
+	// Pooled guids come without calling the service, which stays unhealthy
+	//   until the pool is refilled
+	service.set(false, 0)
+	calls := service.count()
+	seen := make(map[string]bool)
+	for i := 0; i < 10; i++ {
+		guid, err := rg.Guid()
+		if err != nil || seen[guid] {
+			t.Errorf("Expected a new pooled guid but got %v, %v", guid, err)
+		}
+		seen[guid] = true
+	}
+	if !eventually(func() bool { return service.count() > calls }) {
+		t.Error("Expected taking guids to refill the pool")
+	}
+}
+
+func TestRemoteGuidsTimeout(t *testing.T) {
+	service := &guidService{healthy: true, delay: 200 * time.Millisecond}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	rg := NewRemoteGuids(RemoteGuidConfig{URL: server.URL, Timeout: 20 * time.Millisecond})
+	defer rg.Close()
+
+	start := time.Now()
+	if _, err := rg.Guid(); err == nil {
+		t.Error("Expected a slow service to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Expected to give up after 20ms but waited %v", elapsed)
+	}
+}
+
+func TestRemoteGuidsCircuitBreaker(t *testing.T) {
+	service := &guidService{}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	rg := NewRemoteGuids(RemoteGuidConfig{URL: server.URL, PoolSize: 1, FailureThreshold: 3, Cooldown: 50 * time.Millisecond})
+	rg.Close() // Fetch on demand only, so the calls can be counted
+
+	for i := 0; i < 3; i++ {
+		if _, err := rg.Guid(); err == nil || err == ErrCircuitOpen {
+			t.Errorf("Expected the service's error but got %v", err)
+		}
+	}
+
+	// Open, the circuit fails fast without calling the service
+	if _, err := rg.Guid(); err != ErrCircuitOpen {
+		t.Errorf("Expected %v but got %v", ErrCircuitOpen, err)
+	}
+	if calls := service.count(); calls != 3 {
+		t.Errorf("Expected 3 calls to the service but got %d", calls)
+	}
+
+	// After the cooldown the service is tried again
+	service.set(true, 0)
+	time.Sleep(60 * time.Millisecond)
+	if guid, err := rg.Guid(); err != nil || guid != "00000000-0000-0000-0000-000000000004" {
+		t.Errorf("Expected 00000000-0000-0000-0000-000000000004 but got %v, %v", guid, err)
+	}
+}
+
+func TestRemoteGuidsMalformedGuid(t *testing.T) {
+	defer SetGuidSource(UUIDv4{})
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"Status": "OK", "Data": "not-a-guid"}`)
+	}))
+	defer server.Close()
+
+	rg := NewRemoteGuids(RemoteGuidConfig{URL: server.URL, PoolSize: 1, FailureThreshold: 2, Cooldown: time.Minute})
+	rg.Close()
+
+	// A response that is not a guid is a failure, and counts towards
+	//   opening the circuit
+	for i := 0; i < 2; i++ {
+		if guid, err := rg.Guid(); err == nil || err == ErrCircuitOpen {
+			t.Errorf("Expected the malformed guid to fail but got %v, %v", guid, err)
+		}
+	}
+	if _, err := rg.Guid(); err != ErrCircuitOpen {
+		t.Errorf("Expected %v but got %v", ErrCircuitOpen, err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls to the service but got %d", calls)
+	}
+
+	// NewGuid falls back to a local guid
+	SetGuidSource(rg)
+	fallbacks := GuidFallbacks()
+	if guid := sourcedGuid(); !uuidPattern("4").MatchString(guid) {
+		t.Errorf("Expected a local UUIDv4 but got %v", guid)
+	}
+	if GuidFallbacks() != fallbacks+1 {
+		t.Errorf("Expected %d fallbacks but got %d", fallbacks+1, GuidFallbacks())
+	}
+}
+
+func TestRemoteGuidsHalfOpen(t *testing.T) {
+	service := &guidService{}
+	server := httptest.NewServer(service)
+	defer server.Close()
+
+	rg := NewRemoteGuids(RemoteGuidConfig{URL: server.URL, PoolSize: 1, FailureThreshold: 1, Cooldown: 20 * time.Millisecond})
+	rg.Close()
+
+	if _, err := rg.Guid(); err == nil || err == ErrCircuitOpen {
+		t.Errorf("Expected the service's error but got %v", err)
+	}
+
+	// After the cooldown one call tries the service while the rest fail fast
+	service.set(true, 100*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := rg.Guid()
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded, failedFast := 0, 0
+	for err := range errs {
+		switch err {
+		case nil:
+			succeeded++
+		case ErrCircuitOpen:
+			failedFast++
+		}
+	}
+	if succeeded != 1 || failedFast != 9 {
+		t.Errorf("Expected 1 trial and 9 fast failures but got %d and %d", succeeded, failedFast)
+	}
+	if calls := service.count(); calls != 2 {
+		t.Errorf("Expected 2 calls to the service but got %d", calls)
+	}
+
+	// The trial closed the circuit
+	service.set(true, 0)
+	if _, err := rg.Guid(); err != nil {
+		t.Errorf("Expected a guid but got %v", err)
+	}
+}
+
+func TestCheckGuidSource(t *testing.T) {
+	defer SetGuidSource(UUIDv4{})
+
+	// Local sources are always available
+	SetGuidSource(&UUIDv7{})
+	if err := CheckGuidSource(); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+
+	service := &guidService{}
+	server := httptest.NewServer(service)
+	defer server.Close()
+	rg := NewRemoteGuids(RemoteGuidConfig{URL: server.URL, PoolSize: 1, FailureThreshold: 1, Cooldown: time.Minute})
+	rg.Close()
+	SetGuidSource(rg)
+
+	// A failing service fails the check, and opens the circuit like any call
+	if err := CheckGuidSource(); err == nil || err == ErrCircuitOpen {
+		t.Errorf("Expected the service's error but got %v", err)
+	}
+	if err := CheckGuidSource(); err != ErrCircuitOpen {
+		t.Errorf("Expected %v but got %v", ErrCircuitOpen, err)
+	}
+
+	// The guid fetched by a passing check is not wasted
+	rg = NewRemoteGuids(RemoteGuidConfig{URL: server.URL, PoolSize: 1})
+	rg.Close()
+	SetGuidSource(rg)
+	service.set(true, 0)
+	if err := CheckGuidSource(); err != nil {
+		t.Errorf("Expected no error but got %v", err)
+	}
+	if guid, err := rg.Guid(); err != nil || guid != "00000000-0000-0000-0000-000000000002" {
+		t.Errorf("Expected 00000000-0000-0000-0000-000000000002 but got %v, %v", guid, err)
+	}
+	if calls := service.count(); calls != 2 {
+		t.Errorf("Expected 2 calls to the service but got %d", calls)
+	}
+}
+
+func TestUUIDv7(t *testing.T) {
+	pattern := uuidPattern("7")
+	u := &UUIDv7{}
+	last := ""
+	for i := 0; i < 10000; i++ {
+		guid, err := u.Guid()
+		if err != nil || !pattern.MatchString(guid) {
+			t.Fatalf("Expected a UUIDv7 but got %v, %v", guid, err)
+		}
+		if guid <= last {
+			t.Fatalf("Expected %v to sort after %v", guid, last)
+		}
+		last = guid
+	}
+}
+
+// A guid source that always fails.
+type failingGuids struct{}
+
+func (failingGuids) Guid() (string, error) {
+	return "", errors.New("no guids today")
+}
+
+func TestNewGuidFallback(t *testing.T) {
+	defer SetGuidSource(UUIDv4{})
+
+	SetGuidSource(failingGuids{})
+	before := GuidFallbacks()
+	if guid := sourcedGuid(); !uuidPattern("4").MatchString(guid) {
+		t.Errorf("Expected a UUIDv4 but got %v", guid)
+	}
+	if fallbacks := GuidFallbacks(); fallbacks != before+1 {
+		t.Errorf("Expected %d fallbacks but got %d", before+1, fallbacks)
+	}
+
+	SetGuidSource(&UUIDv7{})
+	if guid := sourcedGuid(); !uuidPattern("7").MatchString(guid) {
+		t.Errorf("Expected a UUIDv7 but got %v", guid)
+	}
+	if fallbacks := GuidFallbacks(); fallbacks != before+1 {
+		t.Errorf("Expected %d fallbacks but got %d", before+1, fallbacks)
+	}
+}
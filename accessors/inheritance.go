@@ -0,0 +1,286 @@
+package accessors
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// A verb a resource has, either its own or inherited from its type chain:
+//   its types, their types and so on. Inherited verbs keep the guid and
+//   resourceGUID of the type that defines them.
+type EffectiveVerb struct {
+	ResourceVerb
+	Inherited     bool     `json:"inherited"`
+	InheritedFrom string   `json:"inheritedFrom,omitempty"` // Guid of the type defining the verb
+	Via           string   `json:"via,omitempty"`           // Guid of the resource's own type it was inherited through
+	Overrides     []string `json:"overrides,omitempty"`     // Guids of the inherited verbs of the same name it hides
+}
+
+// A suppression stops a resource, and everything of its type, from
+//   inheriting the verb of that name.
+type VerbSuppression struct {
+	Guid         string `json:"guid"`
+	ResourceGUID string `json:"resourceGUID"`
+	Verb         string `json:"verb"`
+}
+
+// The verbs a resource ends up with once its type chain is resolved.
+type Inheritance struct {
+	Verbs      []EffectiveVerb   `json:"verbs"`
+	Suppressed []VerbSuppression `json:"suppressed"` // The resource's own suppressions
+	Chain      []Resource        `json:"chain"`      // Every type in the chain, nearest first
+}
+
+// What resolving a type chain needs to know about a set of resources. Each
+//   method returns an entry per resource that has anything. Types in the
+//   trash are left out and types come in association guid order.
+type inheritanceSource interface {
+	verbsOf(resources []string) (map[string][]ResourceVerb, error)
+	suppressionsOf(resources []string) (map[string][]VerbSuppression, error)
+	typesOf(resources []string) (map[string][]Resource, error)
+}
+
+// Resolves the effective verbs of a resource. The chain is walked breadth
+//   first, a level at a time, so the nearest definition of a verb name
+//   wins: a resource's own verbs override everything it inherits and a
+//   type's verbs override those of its own types. A suppression hides the
+//   inherited verbs of its name from farther away. Types already walked
+//   are skipped, so cycles in the chain end.
+func inherit(resource string, src inheritanceSource) (Inheritance, error) {
+	inh := Inheritance{make([]EffectiveVerb, 0), make([]VerbSuppression, 0), make([]Resource, 0)}
+	claimed := make(map[string]int) // Index in inh.Verbs by verb name, -1 if suppressed
+	seen := map[string]bool{resource: true}
+	via := make(map[string]string)
+
+	level := []string{resource}
+	for depth := 0; len(level) > 0; depth++ {
+		verbs, err := src.verbsOf(level)
+		if err != nil {
+			return inh, err
+		}
+		suppressions, err := src.suppressionsOf(level)
+		if err != nil {
+			return inh, err
+		}
+		types, err := src.typesOf(level)
+		if err != nil {
+			return inh, err
+		}
+
+		var next []string
+		for _, guid := range level {
+			for _, v := range verbs[guid] {
+				i, ok := claimed[v.Verb]
+				if ok && i >= 0 && inh.Verbs[i].ResourceGUID != guid {
+					inh.Verbs[i].Overrides = append(inh.Verbs[i].Overrides, v.Guid)
+					continue
+				}
+				if ok && i < 0 {
+					continue
+				}
+				ev := EffectiveVerb{ResourceVerb: v}
+				if depth > 0 {
+					ev.Inherited, ev.InheritedFrom, ev.Via = true, guid, via[guid]
+				}
+				if !ok {
+					claimed[v.Verb] = len(inh.Verbs)
+				}
+				inh.Verbs = append(inh.Verbs, ev)
+			}
+			for _, sup := range suppressions[guid] {
+				if depth == 0 {
+					inh.Suppressed = append(inh.Suppressed, sup)
+				}
+				if _, ok := claimed[sup.Verb]; !ok {
+					claimed[sup.Verb] = -1
+				}
+			}
+			for _, t := range types[guid] {
+				if seen[t.Guid] {
+					continue
+				}
+				seen[t.Guid] = true
+				if via[t.Guid] = via[guid]; depth == 0 {
+					via[t.Guid] = t.Guid
+				}
+				inh.Chain = append(inh.Chain, t)
+				next = append(next, t.Guid)
+			}
+		}
+		level = next
+	}
+	return inh, nil
+}
+
+// Runs f once per maxInList resources with the placeholders and arguments
+//   of an IN list of them.
+func inLists(resources []string, f func(placeholders string, args []interface{}) error) error {
+	for start := 0; start < len(resources); start += maxInList {
+		end := start + maxInList
+		if end > len(resources) {
+			end = len(resources)
+		}
+		args := make([]interface{}, 0, end-start)
+		for _, guid := range resources[start:end] {
+			args = append(args, guid)
+		}
+		if err := f(strings.TrimSuffix(strings.Repeat("?,", len(args)), ","), args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Loads a type chain from the database, or from a transaction.
+type sqlInheritance struct {
+	db preparer
+	d  Dialect
+}
+
+func (si sqlInheritance) verbsOf(resources []string) (map[string][]ResourceVerb, error) {
+	verbs := make(map[string][]ResourceVerb)
+	err := inLists(resources, func(placeholders string, args []interface{}) error {
+		return queryEach(si.db, si.d, "SELECT "+verbColumns+" FROM resourceVerbs WHERE resourceGUID IN ("+placeholders+") AND deleted IS NULL", func(rows *sql.Rows) error {
+			var v ResourceVerb
+			if err := scanVerb(rows, &v); err != nil {
+				return err
+			}
+			verbs[v.ResourceGUID] = append(verbs[v.ResourceGUID], v)
+			return nil
+		}, args...)
+	})
+	return verbs, err
+}
+
+func (si sqlInheritance) suppressionsOf(resources []string) (map[string][]VerbSuppression, error) {
+	suppressions := make(map[string][]VerbSuppression)
+	err := inLists(resources, func(placeholders string, args []interface{}) error {
+		return queryEach(si.db, si.d, "SELECT guid, resourceGUID, verb FROM suppressedVerbs WHERE resourceGUID IN ("+placeholders+") ORDER BY guid", func(rows *sql.Rows) error {
+			var s VerbSuppression
+			if err := rows.Scan(&s.Guid, &s.ResourceGUID, &s.Verb); err != nil {
+				return err
+			}
+			suppressions[s.ResourceGUID] = append(suppressions[s.ResourceGUID], s)
+			return nil
+		}, args...)
+	})
+	return suppressions, err
+}
+
+func (si sqlInheritance) typesOf(resources []string) (map[string][]Resource, error) {
+	types := make(map[string][]Resource)
+	err := inLists(resources, func(placeholders string, args []interface{}) error {
+		return queryEach(si.db, si.d, "SELECT resourceTypes.resourceGUID, "+joinedResourceColumns+" FROM resourceTypes JOIN resources ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID IN ("+placeholders+") AND resources.deleted IS NULL ORDER BY resourceTypes.guid", func(rows *sql.Rows) error {
+			var resource string
+			var r Resource
+			if err := rows.Scan(&resource, &r.Guid, &r.Name, &r.Description, &r.APIEndpoint, timestamp{&r.Created}, timestamp{&r.Modified}, &r.Version); err != nil {
+				return err
+			}
+			types[resource] = append(types[resource], r)
+			return nil
+		}, args...)
+	})
+	return types, err
+}
+
+// Gets the effective verbs of a resource: its own and those inherited
+//   from its type chain.
+func (ra *ResourceVerbAccessor) GetEffective(resource string) (Inheritance, error) {
+	return inherit(resource, sqlInheritance{ra.DB, ra.Dialect})
+}
+
+// Stops a resource, and everything of its type, from inheriting the verb
+//   of the given name and returns the suppression's guid. Returns
+//   ErrForeignKey if the resource does not exist or is in the trash and
+//   ErrDuplicate if the verb is already suppressed.
+func (ra *ResourceVerbAccessor) Suppress(resource, verb string) (string, error) {
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	countStmt, err := ra.Dialect.prepare(tx, "SELECT COUNT(*) FROM resources WHERE guid=? AND deleted IS NULL")
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	defer countStmt.Close()
+
+	var count int
+	if err := countStmt.QueryRow(resource).Scan(&count); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	if count == 0 {
+		tx.Rollback()
+		return "", ErrForeignKey
+	}
+
+	guid, now := NewGuid(), Now()
+	if err := execTx(tx, ra.Dialect, "INSERT INTO suppressedVerbs (guid, resourceGUID, verb, created) VALUES (?,?,?,?)", guid, resource, verb, now); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	if err := execTx(tx, ra.Dialect, "UPDATE resources SET version=version+1, modified=? WHERE guid=?", now, resource); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	if err := record(tx, ra.Dialect, ra.Actor, now, OpCreate, EntitySuppression, guid, resource, nil, VerbSuppression{guid, resource, verb}); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	return guid, tx.Commit()
+}
+
+// Lets a resource inherit the verb of the given name again. Returns a
+//   *NotFoundError naming the resource if the verb is not suppressed.
+func (ra *ResourceVerbAccessor) Unsuppress(resource, verb string) error {
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	suppression := VerbSuppression{ResourceGUID: resource, Verb: verb}
+	guidStmt, err := ra.Dialect.prepare(tx, "SELECT guid FROM suppressedVerbs WHERE resourceGUID=? AND verb=?")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer guidStmt.Close()
+
+	if err := guidStmt.QueryRow(resource, verb).Scan(&suppression.Guid); err != nil {
+		tx.Rollback()
+		return notFound(err, EntitySuppression, resource)
+	}
+
+	stmt, err := ra.Dialect.prepare(tx, "DELETE FROM suppressedVerbs WHERE resourceGUID=? AND verb=?")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(resource, verb)
+	if err != nil {
+		tx.Rollback()
+		return ra.Dialect.err(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = &NotFoundError{EntitySuppression, resource}
+		}
+		tx.Rollback()
+		return err
+	}
+
+	now := Now()
+	if err := execTx(tx, ra.Dialect, "UPDATE resources SET version=version+1, modified=? WHERE guid=?", now, resource); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := record(tx, ra.Dialect, ra.Actor, now, OpDelete, EntitySuppression, suppression.Guid, resource, suppression, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
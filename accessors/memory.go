@@ -0,0 +1,1452 @@
+package accessors
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store that keeps everything in memory. It is safe for
+//   concurrent use and is meant for local development and tests; nothing
+//   survives a restart.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	resources map[string]Resource
+	verbs     map[string]ResourceVerb
+	types     map[string]ResourceType
+	audit     []AuditEntry
+
+	// When a resource or verb was last changed according to the audit log,
+	//   so LastModified need not scan it.
+	lastAudited time.Time
+
+	// Suppressed verbs, by suppression guid.
+	suppressions map[string]VerbSuppression
+
+	// Grants and the order they were made in.
+	grants     map[string]Grant
+	grantOrder []string
+
+	// Deletion times of the resources and verbs in the trash.
+	resourceDeleted map[string]time.Time
+	verbDeleted     map[string]time.Time
+
+	// Creation and modification times of the verbs.
+	verbCreated  map[string]time.Time
+	verbModified map[string]time.Time
+
+	// Insertion order, so listings are stable like a table scan.
+	resourceOrder []string
+	verbOrder     []string
+}
+
+// Returns a new, empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		resources:       make(map[string]Resource),
+		verbs:           make(map[string]ResourceVerb),
+		types:           make(map[string]ResourceType),
+		suppressions:    make(map[string]VerbSuppression),
+		grants:          make(map[string]Grant),
+		resourceDeleted: make(map[string]time.Time),
+		verbDeleted:     make(map[string]time.Time),
+		verbCreated:     make(map[string]time.Time),
+		verbModified:    make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryStore) Resources() ResourceStore {
+	return &memoryResourceAccessor{s, ""}
+}
+
+func (s *MemoryStore) Verbs() ResourceVerbStore {
+	return &memoryResourceVerbAccessor{s, ""}
+}
+
+func (s *MemoryStore) Types() ResourceTypeStore {
+	return &memoryResourceTypeAccessor{s, ""}
+}
+
+func (s *MemoryStore) Trash() TrashStore {
+	return &memoryTrashAccessor{s}
+}
+
+func (s *MemoryStore) Changes() ChangeStore {
+	return &memoryChangeAccessor{s}
+}
+
+func (s *MemoryStore) Audit() AuditStore {
+	return &memoryAuditAccessor{s}
+}
+
+func (s *MemoryStore) Catalog() CatalogStore {
+	return &memoryCatalogAccessor{s, ""}
+}
+
+func (s *MemoryStore) Grants() GrantStore {
+	return &memoryGrantAccessor{s, ""}
+}
+
+// Returns the store recording its changes as made by actor.
+func (s *MemoryStore) As(actor string) Store {
+	return &memoryStoreAs{s, actor}
+}
+
+// A memory store making its changes as an actor.
+type memoryStoreAs struct {
+	*MemoryStore
+	actor string
+}
+
+func (s *memoryStoreAs) Resources() ResourceStore {
+	return &memoryResourceAccessor{s.MemoryStore, s.actor}
+}
+
+func (s *memoryStoreAs) Verbs() ResourceVerbStore {
+	return &memoryResourceVerbAccessor{s.MemoryStore, s.actor}
+}
+
+func (s *memoryStoreAs) Types() ResourceTypeStore {
+	return &memoryResourceTypeAccessor{s.MemoryStore, s.actor}
+}
+
+func (s *memoryStoreAs) Grants() GrantStore {
+	return &memoryGrantAccessor{s.MemoryStore, s.actor}
+}
+
+func (s *memoryStoreAs) Catalog() CatalogStore {
+	return &memoryCatalogAccessor{s.MemoryStore, s.actor}
+}
+
+// Records changes in the audit log. The caller must
+//   hold the lock, and makes the entries before the changes so that
+//   nothing is changed if they cannot be made.
+func (s *MemoryStore) record(entries ...AuditEntry) {
+	for _, e := range entries {
+		e.Guid = NewAuditGuid()
+		s.appendAudit(e)
+	}
+}
+
+// Adds an entry to the audit log. The caller must hold the lock.
+func (s *MemoryStore) appendAudit(e AuditEntry) {
+	s.audit = append(s.audit, e)
+	if (e.Entity == EntityResource || e.Entity == EntityVerb) && e.Time.After(s.lastAudited) {
+		s.lastAudited = e.Time
+	}
+}
+
+// Removes guid from an insertion order list.
+func removeGuid(order []string, guid string) []string {
+	for i, g := range order {
+		if g == guid {
+			return append(order[:i], order[i+1:]...)
+		}
+	}
+	return order
+}
+
+// Gets a resource that is not in the trash. The caller must hold the lock.
+func (s *MemoryStore) liveResource(guid string) (Resource, bool) {
+	r, ok := s.resources[guid]
+	if _, deleted := s.resourceDeleted[guid]; deleted {
+		return Resource{}, false
+	}
+	return r, ok
+}
+
+// Gets a verb that is not in the trash. The caller must hold the lock.
+func (s *MemoryStore) liveVerb(guid string) (ResourceVerb, bool) {
+	v, ok := s.verbs[guid]
+	if _, deleted := s.verbDeleted[guid]; deleted {
+		return ResourceVerb{}, false
+	}
+	return v, ok
+}
+
+// Returns the guid of a live resource other than except that has the given
+//   name. The caller must hold the lock.
+func (s *MemoryStore) resourceNamed(name, except string) (string, bool) {
+	for guid, r := range s.resources {
+		if _, ok := s.liveResource(guid); ok && guid != except && r.Name == name {
+			return guid, true
+		}
+	}
+	return "", false
+}
+
+// Returns the guid of a live verb other than except that the resource has
+//   of the given name. The caller must hold the lock.
+func (s *MemoryStore) verbNamed(resource, verb, except string) (string, bool) {
+	for guid, v := range s.verbs {
+		if _, ok := s.liveVerb(guid); ok && guid != except && v.ResourceGUID == resource && v.Verb == verb {
+			return guid, true
+		}
+	}
+	return "", false
+}
+
+// Gets the verbs outside the trash and the type associations that refer to
+//   the resource with the given guid, ordered by guid. The caller must hold
+//   the lock.
+func (s *MemoryStore) dependents(guid string) Dependents {
+	deps := Dependents{make([]ResourceVerb, 0), make([]ResourceType, 0)}
+	for _, v := range s.verbs {
+		if _, ok := s.liveVerb(v.Guid); ok && v.ResourceGUID == guid {
+			deps.Verbs = append(deps.Verbs, v)
+		}
+	}
+	for _, rt := range s.types {
+		if rt.ResourceGUID == guid || rt.Type == guid {
+			deps.Types = append(deps.Types, rt)
+		}
+	}
+	sort.Slice(deps.Verbs, func(i, j int) bool { return deps.Verbs[i].Guid < deps.Verbs[j].Guid })
+	sort.Slice(deps.Types, func(i, j int) bool { return deps.Types[i].Guid < deps.Types[j].Guid })
+	return deps
+}
+
+// Bumps the version and modification time of a resource whose verbs
+//   changed. The caller must hold the lock.
+func (s *MemoryStore) touchResource(guid string, now time.Time) {
+	r := s.resources[guid]
+	r.Version++
+	r.Modified = now
+	s.resources[guid] = r
+}
+
+// Permanently deletes a verb. The caller must hold the lock.
+func (s *MemoryStore) purgeVerb(guid string) {
+	delete(s.verbs, guid)
+	delete(s.verbDeleted, guid)
+	delete(s.verbCreated, guid)
+	delete(s.verbModified, guid)
+	s.verbOrder = removeGuid(s.verbOrder, guid)
+}
+
+type memoryResourceAccessor struct {
+	s     *MemoryStore
+	actor string // Who the changes are recorded as made by
+}
+
+// Gets the resource with the given id, unless it is in the trash.
+func (ra *memoryResourceAccessor) Get(guid string) (Resource, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	r, ok := ra.s.liveResource(guid)
+	if !ok {
+		return Resource{}, &NotFoundError{EntityResource, guid}
+	}
+	return r, nil
+}
+
+// Gets all resources that are not in the trash.
+func (ra *memoryResourceAccessor) GetAll() ([]Resource, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	resources := make([]Resource, 0, len(ra.s.resourceOrder))
+	for _, guid := range ra.s.resourceOrder {
+		if r, ok := ra.s.liveResource(guid); ok {
+			resources = append(resources, r)
+		}
+	}
+	return resources, nil
+}
+
+// Gets the last time any resource, or the verbs of any resource, changed,
+//   counting the changes to resources since purged.
+func (ra *memoryResourceAccessor) LastModified() (time.Time, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	var modified time.Time
+	for _, r := range ra.s.resources {
+		if r.Modified.After(modified) {
+			modified = r.Modified
+		}
+	}
+	if ra.s.lastAudited.After(modified) {
+		modified = ra.s.lastAudited
+	}
+	return modified, nil
+}
+
+// Gets one page of the resources matching the query.
+func (ra *memoryResourceAccessor) List(q ResourceQuery) (ResourcePage, error) {
+	page := ResourcePage{Resources: make([]Resource, 0)}
+	if err := q.normalize(); err != nil {
+		return page, err
+	}
+
+	var after Resource
+	if q.Cursor != "" {
+		c, err := parseResourceCursor(q.Sort, q.Cursor)
+		if err != nil {
+			return page, err
+		}
+		after = Resource{Guid: c.Guid, Name: c.Name, Created: c.Created}
+	}
+
+	ra.s.mu.RLock()
+	matches := make([]Resource, 0)
+	for _, guid := range ra.s.resourceOrder {
+		r, ok := ra.s.liveResource(guid)
+		if !ok {
+			continue
+		}
+		if q.Name != "" && r.Name != q.Name {
+			continue
+		}
+		if !strings.HasPrefix(r.APIEndpoint, q.APIEndpointPrefix) {
+			continue
+		}
+		matches = append(matches, r)
+	}
+	ra.s.mu.RUnlock()
+
+	page.Total = len(matches)
+	sort.Slice(matches, func(i, j int) bool { return resourceLess(q.Sort, matches[i], matches[j]) })
+	for _, r := range matches {
+		if q.Cursor != "" && !resourceLess(q.Sort, after, r) {
+			continue
+		}
+		if len(page.Resources) == q.Limit {
+			page.Next = newResourceCursor(q.Sort, page.Resources[q.Limit-1])
+			break
+		}
+		page.Resources = append(page.Resources, r)
+	}
+	return page, nil
+}
+
+// Create a new resource and return its guid.
+func (ra *memoryResourceAccessor) Insert(r Resource) (string, error) {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	r.Guid = NewGuid()
+	r.Created = Now()
+	r.Modified = r.Created
+	r.Version = 1
+	r.Verbs = nil
+	if _, ok := ra.s.resources[r.Guid]; ok {
+		return "", ErrDuplicate
+	}
+	if existing, ok := ra.s.resourceNamed(r.Name, ""); ok {
+		return "", &DuplicateError{EntityResource, existing}
+	}
+	e, err := newAuditEntry(ra.actor, r.Created, OpCreate, EntityResource, r.Guid, r.Guid, nil, resourceValues(r))
+	if err != nil {
+		return "", err
+	}
+	ra.s.resources[r.Guid] = r
+	ra.s.resourceOrder = append(ra.s.resourceOrder, r.Guid)
+	ra.s.record(e)
+	return r.Guid, nil
+}
+
+// Updates the name, description and api endpoint of a resource and bumps
+//   its version, provided that r.Version is still the stored version.
+func (ra *memoryResourceAccessor) Update(r Resource) error {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	old, ok := ra.s.liveResource(r.Guid)
+	if !ok {
+		return &NotFoundError{EntityResource, r.Guid}
+	}
+	if r.Version != old.Version {
+		return ErrVersionMismatch
+	}
+	if existing, ok := ra.s.resourceNamed(r.Name, r.Guid); ok {
+		return &DuplicateError{EntityResource, existing}
+	}
+	r.Created = old.Created
+	r.Modified = Now()
+	r.Version++
+	r.Verbs = nil
+	e, err := newAuditEntry(ra.actor, r.Modified, OpUpdate, EntityResource, r.Guid, r.Guid, resourceValues(old), resourceValues(r))
+	if err != nil {
+		return err
+	}
+	ra.s.resources[r.Guid] = r
+	ra.s.record(e)
+	return nil
+}
+
+// Moves a resource to the trash, provided version is still its stored
+//   version. If verbs or type associations still refer to it, nothing is
+//   deleted and a *DependentsError listing them is returned.
+func (ra *memoryResourceAccessor) Delete(guid string, version int) error {
+	_, err := ra.delete(guid, version, false)
+	return err
+}
+
+// Moves a resource to the trash together with its verbs, provided version
+//   is still its stored version. Returns the dependents.
+func (ra *memoryResourceAccessor) DeleteCascade(guid string, version int) (Dependents, error) {
+	return ra.delete(guid, version, true)
+}
+
+func (ra *memoryResourceAccessor) delete(guid string, version int, cascade bool) (Dependents, error) {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	resource, ok := ra.s.liveResource(guid)
+	if !ok {
+		return Dependents{make([]ResourceVerb, 0), make([]ResourceType, 0)}, &NotFoundError{EntityResource, guid}
+	}
+	deps := ra.s.dependents(guid)
+	if !deps.empty() && !cascade {
+		return deps, &DependentsError{deps}
+	}
+	if resource.Version != version {
+		return deps, ErrVersionMismatch
+	}
+
+	deleted := Now()
+	entries := make([]AuditEntry, 0, len(deps.Verbs)+1)
+	for _, v := range deps.Verbs {
+		e, err := newAuditEntry(ra.actor, deleted, OpDelete, EntityVerb, v.Guid, guid, v, nil)
+		if err != nil {
+			return deps, err
+		}
+		entries = append(entries, e)
+	}
+	e, err := newAuditEntry(ra.actor, deleted, OpDelete, EntityResource, guid, guid, resourceValues(resource), nil)
+	if err != nil {
+		return deps, err
+	}
+	entries = append(entries, e)
+
+	for _, v := range deps.Verbs {
+		ra.s.verbDeleted[v.Guid] = deleted
+		ra.s.verbModified[v.Guid] = deleted
+	}
+	ra.s.resourceDeleted[guid] = deleted
+	resource.Modified = deleted
+	ra.s.resources[guid] = resource
+	ra.s.record(entries...)
+	return deps, nil
+}
+
+// Takes a resource out of the trash, along with the verbs that were trashed
+//   with it.
+func (ra *memoryResourceAccessor) Restore(guid string) error {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	deleted, ok := ra.s.resourceDeleted[guid]
+	if !ok {
+		return &NotFoundError{EntityResource, guid}
+	}
+	if existing, ok := ra.s.resourceNamed(ra.s.resources[guid].Name, guid); ok {
+		return &DuplicateError{EntityResource, existing}
+	}
+
+	// The verbs trashed with the resource
+	verbs := make([]ResourceVerb, 0)
+	for verb, verbDeleted := range ra.s.verbDeleted {
+		if ra.s.verbs[verb].ResourceGUID == guid && verbDeleted.Equal(deleted) {
+			verbs = append(verbs, ra.s.verbs[verb])
+		}
+	}
+	sort.Slice(verbs, func(i, j int) bool { return verbs[i].Guid < verbs[j].Guid })
+
+	restored := Now()
+	entries := make([]AuditEntry, 0, len(verbs)+1)
+	for _, v := range verbs {
+		e, err := newAuditEntry(ra.actor, restored, OpRestore, EntityVerb, v.Guid, guid, nil, v)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+	}
+	e, err := newAuditEntry(ra.actor, restored, OpRestore, EntityResource, guid, guid, nil, resourceValues(ra.s.resources[guid]))
+	if err != nil {
+		return err
+	}
+	entries = append(entries, e)
+
+	for _, v := range verbs {
+		delete(ra.s.verbDeleted, v.Guid)
+		ra.s.verbModified[v.Guid] = restored
+	}
+	delete(ra.s.resourceDeleted, guid)
+	r := ra.s.resources[guid]
+	r.Modified = restored
+	ra.s.resources[guid] = r
+	ra.s.record(entries...)
+	return nil
+}
+
+type memoryResourceVerbAccessor struct {
+	s     *MemoryStore
+	actor string // Who the changes are recorded as made by
+}
+
+func (ra *memoryResourceVerbAccessor) Get(guid string) (ResourceVerb, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	v, ok := ra.s.liveVerb(guid)
+	if !ok {
+		return ResourceVerb{}, &NotFoundError{EntityVerb, guid}
+	}
+	return v, nil
+}
+
+// Gets all verbs associated to a given resource by that resource's guid.
+func (ra *memoryResourceVerbAccessor) GetByResource(resource string) ([]ResourceVerb, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	verbs := make([]ResourceVerb, 0)
+	for _, guid := range ra.s.verbOrder {
+		if v, ok := ra.s.liveVerb(guid); ok && v.ResourceGUID == resource {
+			verbs = append(verbs, v)
+		}
+	}
+	return verbs, nil
+}
+
+// Gets the verbs of several resources at once, keyed by resource guid.
+func (ra *memoryResourceVerbAccessor) GetByResources(resources []string) (map[string][]ResourceVerb, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	verbs := make(map[string][]ResourceVerb, len(resources))
+	for _, guid := range resources {
+		verbs[guid] = make([]ResourceVerb, 0)
+	}
+	for _, guid := range ra.s.verbOrder {
+		v, ok := ra.s.liveVerb(guid)
+		if !ok {
+			continue
+		}
+		if list, ok := verbs[v.ResourceGUID]; ok {
+			verbs[v.ResourceGUID] = append(list, v)
+		}
+	}
+	return verbs, nil
+}
+
+// Associate a new verb to a resource and return the association's guid.
+func (ra *memoryResourceVerbAccessor) Add(r ResourceVerb) (string, error) {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	r.Guid = NewGuid()
+	r.Version = 1
+	if _, ok := ra.s.verbs[r.Guid]; ok {
+		return "", ErrDuplicate
+	}
+	if _, ok := ra.s.liveResource(r.ResourceGUID); !ok {
+		return "", &NotFoundError{EntityResource, r.ResourceGUID}
+	}
+	if existing, ok := ra.s.verbNamed(r.ResourceGUID, r.Verb, ""); ok {
+		return "", &DuplicateError{EntityVerb, existing}
+	}
+	now := Now()
+	e, err := newAuditEntry(ra.actor, now, OpCreate, EntityVerb, r.Guid, r.ResourceGUID, nil, r)
+	if err != nil {
+		return "", err
+	}
+	ra.s.verbs[r.Guid] = r
+	ra.s.verbCreated[r.Guid] = now
+	ra.s.verbModified[r.Guid] = now
+	ra.s.verbOrder = append(ra.s.verbOrder, r.Guid)
+	ra.s.touchResource(r.ResourceGUID, now)
+	ra.s.record(e)
+	return r.Guid, nil
+}
+
+// Update the description for a verb on a resource and bump its version,
+//   provided that version is still the stored version.
+func (ra *memoryResourceVerbAccessor) Update(guid, description string, version int) error {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	v, ok := ra.s.liveVerb(guid)
+	if !ok {
+		return &NotFoundError{EntityVerb, guid}
+	}
+	if v.Version != version {
+		return ErrVersionMismatch
+	}
+	before := v
+	v.Description = description
+	v.Version++
+	now := Now()
+	e, err := newAuditEntry(ra.actor, now, OpUpdate, EntityVerb, guid, v.ResourceGUID, before, v)
+	if err != nil {
+		return err
+	}
+	ra.s.verbs[guid] = v
+	ra.s.verbModified[guid] = now
+	ra.s.touchResource(v.ResourceGUID, now)
+	ra.s.record(e)
+	return nil
+}
+
+// Disassociate a verb from a resource by moving it to the trash, along
+//   with the grants of it on resources left without a verb of its name,
+//   provided version is still the verb's stored version.
+func (ra *memoryResourceVerbAccessor) Remove(guid string, version int) error {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	v, ok := ra.s.liveVerb(guid)
+	if !ok {
+		return &NotFoundError{EntityVerb, guid}
+	}
+	if v.Version != version {
+		return ErrVersionMismatch
+	}
+	now := Now()
+	e, err := newAuditEntry(ra.actor, now, OpDelete, EntityVerb, guid, v.ResourceGUID, v, nil)
+	if err != nil {
+		return err
+	}
+	entries := []AuditEntry{e}
+
+	// Which grants go depends on the verb being gone
+	ra.s.verbDeleted[guid] = now
+	orphaned, err := ra.s.orphanedGrants(v)
+	for _, g := range orphaned {
+		if err == nil {
+			e, err = newAuditEntry(ra.actor, now, OpDelete, EntityGrant, g.Guid, g.ResourceGUID, g, nil)
+			entries = append(entries, e)
+		}
+	}
+	if err != nil {
+		delete(ra.s.verbDeleted, guid)
+		return err
+	}
+
+	ra.s.verbModified[guid] = now
+	ra.s.touchResource(v.ResourceGUID, now)
+	for _, g := range orphaned {
+		delete(ra.s.grants, g.Guid)
+		ra.s.grantOrder = removeGuid(ra.s.grantOrder, g.Guid)
+	}
+	ra.s.record(entries...)
+	return nil
+}
+
+// Takes a verb out of the trash.
+func (ra *memoryResourceVerbAccessor) Restore(guid string) error {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	if _, ok := ra.s.verbDeleted[guid]; !ok {
+		return &NotFoundError{EntityVerb, guid}
+	}
+	resource := ra.s.verbs[guid].ResourceGUID
+	if _, ok := ra.s.liveResource(resource); !ok {
+		return ErrForeignKey
+	}
+	if existing, ok := ra.s.verbNamed(resource, ra.s.verbs[guid].Verb, guid); ok {
+		return &DuplicateError{EntityVerb, existing}
+	}
+	now := Now()
+	e, err := newAuditEntry(ra.actor, now, OpRestore, EntityVerb, guid, resource, nil, ra.s.verbs[guid])
+	if err != nil {
+		return err
+	}
+	delete(ra.s.verbDeleted, guid)
+	ra.s.verbModified[guid] = now
+	ra.s.touchResource(resource, now)
+	ra.s.record(e)
+	return nil
+}
+
+// Gets the effective verbs of a resource: its own and those inherited
+//   from its type chain.
+func (ra *memoryResourceVerbAccessor) GetEffective(resource string) (Inheritance, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	return inherit(resource, memoryInheritance{ra.s})
+}
+
+// Stops a resource, and everything of its type, from inheriting the verb
+//   of the given name and returns the suppression's guid.
+func (ra *memoryResourceVerbAccessor) Suppress(resource, verb string) (string, error) {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	guid := NewGuid()
+	if _, ok := ra.s.suppressions[guid]; ok {
+		return "", ErrDuplicate
+	}
+	if _, ok := ra.s.liveResource(resource); !ok {
+		return "", ErrForeignKey
+	}
+	for _, sup := range ra.s.suppressions {
+		if sup.ResourceGUID == resource && sup.Verb == verb {
+			return "", ErrDuplicate
+		}
+	}
+	suppression, now := VerbSuppression{guid, resource, verb}, Now()
+	e, err := newAuditEntry(ra.actor, now, OpCreate, EntitySuppression, guid, resource, nil, suppression)
+	if err != nil {
+		return "", err
+	}
+	ra.s.suppressions[guid] = suppression
+	ra.s.touchResource(resource, now)
+	ra.s.record(e)
+	return guid, nil
+}
+
+// Lets a resource inherit the verb of the given name again.
+func (ra *memoryResourceVerbAccessor) Unsuppress(resource, verb string) error {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	for guid, sup := range ra.s.suppressions {
+		if sup.ResourceGUID == resource && sup.Verb == verb {
+			now := Now()
+			e, err := newAuditEntry(ra.actor, now, OpDelete, EntitySuppression, guid, resource, sup, nil)
+			if err != nil {
+				return err
+			}
+			delete(ra.s.suppressions, guid)
+			ra.s.touchResource(resource, now)
+			ra.s.record(e)
+			return nil
+		}
+	}
+	return &NotFoundError{EntitySuppression, resource}
+}
+
+// Loads a type chain from a memory store. The caller must hold the lock.
+type memoryInheritance struct {
+	s *MemoryStore
+}
+
+func (mi memoryInheritance) verbsOf(resources []string) (map[string][]ResourceVerb, error) {
+	wanted := make(map[string]bool, len(resources))
+	for _, guid := range resources {
+		wanted[guid] = true
+	}
+	verbs := make(map[string][]ResourceVerb)
+	for _, guid := range mi.s.verbOrder {
+		if v, ok := mi.s.liveVerb(guid); ok && wanted[v.ResourceGUID] {
+			verbs[v.ResourceGUID] = append(verbs[v.ResourceGUID], v)
+		}
+	}
+	return verbs, nil
+}
+
+func (mi memoryInheritance) suppressionsOf(resources []string) (map[string][]VerbSuppression, error) {
+	wanted := make(map[string]bool, len(resources))
+	for _, guid := range resources {
+		wanted[guid] = true
+	}
+	suppressions := make(map[string][]VerbSuppression)
+	for _, sup := range mi.s.suppressions {
+		if wanted[sup.ResourceGUID] {
+			suppressions[sup.ResourceGUID] = append(suppressions[sup.ResourceGUID], sup)
+		}
+	}
+	for _, list := range suppressions {
+		sort.Slice(list, func(i, j int) bool { return list[i].Guid < list[j].Guid })
+	}
+	return suppressions, nil
+}
+
+func (mi memoryInheritance) typesOf(resources []string) (map[string][]Resource, error) {
+	wanted := make(map[string]bool, len(resources))
+	for _, guid := range resources {
+		wanted[guid] = true
+	}
+	associations := make([]ResourceType, 0)
+	for _, rt := range mi.s.types {
+		if _, ok := mi.s.liveResource(rt.Type); ok && wanted[rt.ResourceGUID] {
+			associations = append(associations, rt)
+		}
+	}
+	sort.Slice(associations, func(i, j int) bool { return associations[i].Guid < associations[j].Guid })
+
+	types := make(map[string][]Resource)
+	for _, rt := range associations {
+		types[rt.ResourceGUID] = append(types[rt.ResourceGUID], mi.s.resources[rt.Type])
+	}
+	return types, nil
+}
+
+type memoryResourceTypeAccessor struct {
+	s     *MemoryStore
+	actor string // Who the changes are recorded as made by
+}
+
+// Returns the resource type information for the given resource.
+func (ra *memoryResourceTypeAccessor) GetType(guid string) (Resource, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	if _, ok := ra.s.liveResource(guid); !ok {
+		return Resource{}, &NotFoundError{EntityType, guid}
+	}
+	first := ResourceType{}
+	for _, rt := range ra.s.types {
+		if _, ok := ra.s.liveResource(rt.Type); ok && rt.ResourceGUID == guid && (first.Guid == "" || rt.Guid < first.Guid) {
+			first = rt
+		}
+	}
+	if first.Guid == "" {
+		return Resource{}, &NotFoundError{EntityType, guid}
+	}
+	return ra.s.resources[first.Type], nil
+}
+
+// Returns every type of the given resource, each with its association,
+//   ordered by association guid.
+func (ra *memoryResourceTypeAccessor) GetTypes(resource string) ([]ResourceTypeDetail, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	types := make([]ResourceTypeDetail, 0)
+	if _, ok := ra.s.liveResource(resource); !ok {
+		return types, nil
+	}
+	for _, rt := range ra.s.types {
+		if rt.ResourceGUID != resource {
+			continue
+		}
+		if t, ok := ra.s.liveResource(rt.Type); ok {
+			types = append(types, ResourceTypeDetail{rt, t})
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Guid < types[j].Guid })
+	return types, nil
+}
+
+// Returns the resources of the given type that are not in the trash, in
+//   the order they were created.
+func (ra *memoryResourceTypeAccessor) GetResourcesOfType(typeGUID string) ([]Resource, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	resources := make([]Resource, 0)
+	if _, ok := ra.s.liveResource(typeGUID); !ok {
+		return resources, nil
+	}
+	ofType := make(map[string]bool)
+	for _, rt := range ra.s.types {
+		if rt.Type == typeGUID {
+			ofType[rt.ResourceGUID] = true
+		}
+	}
+	for _, guid := range ra.s.resourceOrder {
+		if r, ok := ra.s.liveResource(guid); ok && ofType[guid] {
+			resources = append(resources, r)
+		}
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		return a.Created.Before(b.Created) || (a.Created.Equal(b.Created) && a.Guid < b.Guid)
+	})
+	return resources, nil
+}
+
+// Returns every resource outside the trash that is the type of at least
+//   one other resource outside the trash, with how many, ordered by name.
+func (ra *memoryResourceTypeAccessor) GetTypesInUse() ([]TypeInUse, error) {
+	ra.s.mu.RLock()
+	defer ra.s.mu.RUnlock()
+
+	instances := make(map[string]map[string]bool)
+	for _, rt := range ra.s.types {
+		_, resourceOk := ra.s.liveResource(rt.ResourceGUID)
+		_, typeOk := ra.s.liveResource(rt.Type)
+		if !resourceOk || !typeOk {
+			continue
+		}
+		if instances[rt.Type] == nil {
+			instances[rt.Type] = make(map[string]bool)
+		}
+		instances[rt.Type][rt.ResourceGUID] = true
+	}
+
+	types := make([]TypeInUse, 0, len(instances))
+	for guid, resources := range instances {
+		types = append(types, TypeInUse{ra.s.resources[guid], len(resources)})
+	}
+	sort.Slice(types, func(i, j int) bool {
+		a, b := types[i], types[j]
+		return a.Name < b.Name || (a.Name == b.Name && a.Guid < b.Guid)
+	})
+	return types, nil
+}
+
+// Create a new resourceType and return its guid. The resource's version
+//   is bumped, since its type decides the verbs it inherits.
+func (ra *memoryResourceTypeAccessor) Insert(r, t string) (string, error) {
+	ra.s.mu.Lock()
+	defer ra.s.mu.Unlock()
+
+	guid := NewGuid()
+	if _, ok := ra.s.types[guid]; ok {
+		return "", ErrDuplicate
+	}
+	if r == t {
+		return "", ErrSelfType
+	}
+	for _, guid := range []string{r, t} {
+		if _, ok := ra.s.liveResource(guid); !ok {
+			return "", &NotFoundError{EntityResource, guid}
+		}
+	}
+	for _, rt := range ra.s.types {
+		if rt.ResourceGUID == r && rt.Type == t {
+			return "", &DuplicateError{EntityType, rt.Guid}
+		}
+	}
+	rt, now := ResourceType{guid, r, t}, Now()
+	e, err := newAuditEntry(ra.actor, now, OpCreate, EntityType, guid, r, nil, rt)
+	if err != nil {
+		return "", err
+	}
+	ra.s.types[guid] = rt
+	ra.s.touchResource(r, now)
+	ra.s.record(e)
+	return guid, nil
+}
+
+type memoryTrashAccessor struct {
+	s *MemoryStore
+}
+
+// Gets the contents of the trash, oldest deletion first.
+func (ta *memoryTrashAccessor) Get() (Trash, error) {
+	ta.s.mu.RLock()
+	defer ta.s.mu.RUnlock()
+
+	trash := Trash{make([]TrashedResource, 0), make([]TrashedVerb, 0)}
+	for guid, deleted := range ta.s.resourceDeleted {
+		trash.Resources = append(trash.Resources, TrashedResource{ta.s.resources[guid], deleted})
+	}
+	for guid, deleted := range ta.s.verbDeleted {
+		trash.Verbs = append(trash.Verbs, TrashedVerb{ta.s.verbs[guid], deleted})
+	}
+	sort.Slice(trash.Resources, func(i, j int) bool {
+		a, b := trash.Resources[i], trash.Resources[j]
+		return a.Deleted.Before(b.Deleted) || (a.Deleted.Equal(b.Deleted) && a.Guid < b.Guid)
+	})
+	sort.Slice(trash.Verbs, func(i, j int) bool {
+		a, b := trash.Verbs[i], trash.Verbs[j]
+		return a.Deleted.Before(b.Deleted) || (a.Deleted.Equal(b.Deleted) && a.Guid < b.Guid)
+	})
+	return trash, nil
+}
+
+// Permanently deletes the resources and verbs trashed before the given
+//   time, along with the verbs, type associations, suppressions and grants
+//   of purged resources, auditing each as deleted by PurgeActor.
+func (ta *memoryTrashAccessor) Purge(before time.Time) (int, error) {
+	ta.s.mu.Lock()
+	defer ta.s.mu.Unlock()
+	s := ta.s
+
+	// Find what goes, in a stable order
+	purging := make(map[string]bool)
+	for guid, deleted := range s.resourceDeleted {
+		if deleted.Before(before) {
+			purging[guid] = true
+		}
+	}
+	var resources, verbs, types, suppressions, grants []string
+	for guid := range purging {
+		resources = append(resources, guid)
+	}
+	for guid, v := range s.verbs {
+		if deleted, ok := s.verbDeleted[guid]; purging[v.ResourceGUID] || (ok && deleted.Before(before)) {
+			verbs = append(verbs, guid)
+		}
+	}
+	for guid, t := range s.types {
+		if purging[t.ResourceGUID] || purging[t.Type] {
+			types = append(types, guid)
+		}
+	}
+	for guid, v := range s.suppressions {
+		if purging[v.ResourceGUID] {
+			suppressions = append(suppressions, guid)
+		}
+	}
+	for guid, g := range s.grants {
+		if purging[g.ResourceGUID] {
+			grants = append(grants, guid)
+		}
+	}
+	for _, guids := range [][]string{resources, verbs, types, suppressions, grants} {
+		sort.Strings(guids)
+	}
+
+	now := Now()
+	var entries []AuditEntry
+	audit := func(entity, guid, resource string, values interface{}) error {
+		e, err := newAuditEntry(PurgeActor, now, OpDelete, entity, guid, resource, values, nil)
+		entries = append(entries, e)
+		return err
+	}
+	for _, guid := range grants {
+		if err := audit(EntityGrant, guid, s.grants[guid].ResourceGUID, s.grants[guid]); err != nil {
+			return 0, err
+		}
+	}
+	for _, guid := range suppressions {
+		if err := audit(EntitySuppression, guid, s.suppressions[guid].ResourceGUID, s.suppressions[guid]); err != nil {
+			return 0, err
+		}
+	}
+	for _, guid := range types {
+		if err := audit(EntityType, guid, s.types[guid].ResourceGUID, s.types[guid]); err != nil {
+			return 0, err
+		}
+	}
+	for _, guid := range verbs {
+		if err := audit(EntityVerb, guid, s.verbs[guid].ResourceGUID, s.verbs[guid]); err != nil {
+			return 0, err
+		}
+	}
+	for _, guid := range resources {
+		if err := audit(EntityResource, guid, guid, resourceValues(s.resources[guid])); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, guid := range grants {
+		delete(s.grants, guid)
+		s.grantOrder = removeGuid(s.grantOrder, guid)
+	}
+	for _, guid := range suppressions {
+		delete(s.suppressions, guid)
+	}
+	for _, guid := range types {
+		delete(s.types, guid)
+	}
+	for _, guid := range verbs {
+		s.purgeVerb(guid)
+	}
+	for _, guid := range resources {
+		delete(s.resources, guid)
+		delete(s.resourceDeleted, guid)
+		s.resourceOrder = removeGuid(s.resourceOrder, guid)
+	}
+	s.record(entries...)
+	return len(verbs) + len(resources), nil
+}
+
+type memoryAuditAccessor struct {
+	s *MemoryStore
+}
+
+// Records an audit entry at the current time and returns its guid.
+func (aa *memoryAuditAccessor) Record(e AuditEntry) (string, error) {
+	aa.s.mu.Lock()
+	defer aa.s.mu.Unlock()
+
+	e.Guid = NewAuditGuid()
+	e.Time = Now()
+	aa.s.appendAudit(e)
+	return e.Guid, nil
+}
+
+// Gets the audit entries matching the query, newest first.
+func (aa *memoryAuditAccessor) Get(q AuditQuery) ([]AuditEntry, error) {
+	aa.s.mu.RLock()
+	defer aa.s.mu.RUnlock()
+
+	entries := make([]AuditEntry, 0)
+	for _, e := range aa.s.audit {
+		if (q.Actor != "" && e.Actor != q.Actor) ||
+			(q.ResourceGUID != "" && e.ResourceGUID != q.ResourceGUID) ||
+			(!q.Since.IsZero() && e.Time.Before(q.Since)) ||
+			(!q.Until.IsZero() && !e.Time.Before(q.Until)) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		return a.Time.After(b.Time) || (a.Time.Equal(b.Time) && a.Guid > b.Guid)
+	})
+	if q.Limit > 0 && len(entries) > q.Limit {
+		entries = entries[:q.Limit]
+	}
+	return entries, nil
+}
+
+type memoryChangeAccessor struct {
+	s *MemoryStore
+}
+
+// Gets the resources and verbs that were created, updated or moved to the
+//   trash after the given time, or in the CommitWindow before it.
+func (ca *memoryChangeAccessor) Since(since time.Time) (Changes, error) {
+	ca.s.mu.RLock()
+	defer ca.s.mu.RUnlock()
+
+	changes := Changes{make([]ResourceChange, 0), make([]VerbChange, 0), since}
+	from := windowStart(since)
+	for _, r := range ca.s.resources {
+		if !r.Modified.After(from) {
+			continue
+		}
+		changes.Resources = append(changes.Resources, ResourceChange{changeKind(since, r.Created, ca.s.resourceDeleted[r.Guid]), r})
+		if r.Modified.After(changes.Last) {
+			changes.Last = r.Modified
+		}
+	}
+	for guid, modified := range ca.s.verbModified {
+		if !modified.After(from) {
+			continue
+		}
+		changes.Verbs = append(changes.Verbs, VerbChange{changeKind(since, ca.s.verbCreated[guid], ca.s.verbDeleted[guid]), modified, ca.s.verbs[guid]})
+		if modified.After(changes.Last) {
+			changes.Last = modified
+		}
+	}
+	sort.Slice(changes.Resources, func(i, j int) bool {
+		a, b := changes.Resources[i].Resource, changes.Resources[j].Resource
+		return a.Modified.Before(b.Modified) || (a.Modified.Equal(b.Modified) && a.Guid < b.Guid)
+	})
+	sort.Slice(changes.Verbs, func(i, j int) bool {
+		a, b := changes.Verbs[i], changes.Verbs[j]
+		return a.Modified.Before(b.Modified) || (a.Modified.Equal(b.Modified) && a.Verb.Guid < b.Verb.Guid)
+	})
+	return changes, nil
+}
+
+type memoryCatalogAccessor struct {
+	s     *MemoryStore
+	actor string
+}
+
+// Gets every resource outside the trash with its verbs, and the type
+//   associations between them.
+func (ca *memoryCatalogAccessor) Export() (Catalog, error) {
+	ca.s.mu.RLock()
+	defer ca.s.mu.RUnlock()
+	return ca.state().catalog(), nil
+}
+
+// Imports a catalog all at once and audits every change, the grants of
+//   the verbs it removes included; nothing is written if it is invalid.
+func (ca *memoryCatalogAccessor) Import(c Catalog, mode string) (ImportResult, error) {
+	if err := c.Validate(); err != nil {
+		return ImportResult{}, err
+	}
+
+	ca.s.mu.Lock()
+	defer ca.s.mu.Unlock()
+
+	st := ca.state()
+	p, err := planImport(st, c, mode)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	s := ca.s
+	now := Now()
+	entries, err := p.auditEntries(st, ca.actor, now)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	for _, guid := range p.deleteTypes {
+		delete(s.types, guid)
+	}
+	for _, guid := range p.deleteVerbs {
+		s.verbDeleted[guid] = now
+		s.verbModified[guid] = now
+	}
+	for _, guid := range p.deleteResources {
+		s.resourceDeleted[guid] = now
+		r := s.resources[guid]
+		r.Modified = now
+		s.resources[guid] = r
+	}
+
+	for _, r := range p.insertResources {
+		s.resources[r.Guid] = Resource{Guid: r.Guid, Name: r.Name, Description: r.Description, APIEndpoint: r.APIEndpoint, Created: now, Modified: now, Version: 1}
+		s.resourceOrder = append(s.resourceOrder, r.Guid)
+	}
+	for _, r := range p.updateResources {
+		old := s.resources[r.Guid]
+		old.Name, old.Description, old.APIEndpoint = r.Name, r.Description, r.APIEndpoint
+		old.Modified = now
+		old.Version++
+		s.resources[r.Guid] = old
+		delete(s.resourceDeleted, r.Guid)
+	}
+
+	for _, v := range p.insertVerbs {
+		s.verbs[v.Guid] = ResourceVerb{v.Guid, v.resourceGUID, v.Verb, v.Description, 1}
+		s.verbCreated[v.Guid] = now
+		s.verbModified[v.Guid] = now
+		s.verbOrder = append(s.verbOrder, v.Guid)
+	}
+	for _, v := range p.updateVerbs {
+		old := s.verbs[v.Guid]
+		s.verbs[v.Guid] = ResourceVerb{v.Guid, v.resourceGUID, v.Verb, v.Description, old.Version + 1}
+		s.verbModified[v.Guid] = now
+		delete(s.verbDeleted, v.Guid)
+	}
+
+	for _, t := range p.insertTypes {
+		s.types[t.Guid] = ResourceType{t.Guid, t.ResourceGUID, t.Type}
+	}
+	for _, t := range p.updateTypes {
+		s.types[t.Guid] = ResourceType{t.Guid, t.ResourceGUID, t.Type}
+	}
+
+	for _, guid := range p.touch {
+		s.touchResource(guid, now)
+	}
+	for _, v := range p.removeVerbs {
+		orphaned, err := s.orphanedGrants(v)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		for _, g := range orphaned {
+			e, err := newAuditEntry(ca.actor, now, OpDelete, EntityGrant, g.Guid, g.ResourceGUID, g, nil)
+			if err != nil {
+				return ImportResult{}, err
+			}
+			entries = append(entries, e)
+			delete(s.grants, g.Guid)
+			s.grantOrder = removeGuid(s.grantOrder, g.Guid)
+		}
+	}
+	s.record(entries...)
+	return p.result, nil
+}
+
+// Copies the stored rows. The caller must hold the lock.
+func (ca *memoryCatalogAccessor) state() catalogState {
+	st := newCatalogState()
+	for _, guid := range ca.s.resourceOrder {
+		r := ca.s.resources[guid]
+		_, deleted := ca.s.resourceDeleted[guid]
+		st.resources[guid] = storedResource{CatalogResource{Guid: r.Guid, Name: r.Name, Description: r.Description, APIEndpoint: r.APIEndpoint}, r.Created, deleted}
+		st.resourceOrder = append(st.resourceOrder, guid)
+	}
+	for _, guid := range ca.s.verbOrder {
+		v := ca.s.verbs[guid]
+		_, deleted := ca.s.verbDeleted[guid]
+		st.verbs[guid] = storedVerb{CatalogVerb{v.Guid, v.Verb, v.Description}, v.ResourceGUID, v.Version, deleted}
+		st.verbOrder = append(st.verbOrder, guid)
+	}
+	for guid, t := range ca.s.types {
+		st.types[guid] = CatalogType{t.Guid, t.ResourceGUID, t.Type}
+		st.typeOrder = append(st.typeOrder, guid)
+	}
+	sort.Strings(st.typeOrder)
+	return st
+}
+
+type memoryGrantAccessor struct {
+	s     *MemoryStore
+	actor string // Who the changes are recorded as made by
+}
+
+// Gets a grant by guid. The grants of resources in the trash are hidden.
+func (ga *memoryGrantAccessor) Get(guid string) (Grant, error) {
+	ga.s.mu.RLock()
+	defer ga.s.mu.RUnlock()
+
+	g, ok := ga.s.grants[guid]
+	if _, live := ga.s.liveResource(g.ResourceGUID); !ok || !live {
+		return Grant{}, &NotFoundError{EntityGrant, guid}
+	}
+	return g, nil
+}
+
+// Gets the grants matching the query in the order they were made.
+func (ga *memoryGrantAccessor) List(q GrantQuery) ([]Grant, error) {
+	ga.s.mu.RLock()
+	defer ga.s.mu.RUnlock()
+
+	grants := make([]Grant, 0)
+	for _, guid := range ga.s.grantOrder {
+		g := ga.s.grants[guid]
+		if _, live := ga.s.liveResource(g.ResourceGUID); !live {
+			continue
+		}
+		if (q.Principal == "" || q.Principal == g.Principal) && (q.Role == "" || q.Role == g.Role) && (q.ResourceGUID == "" || q.ResourceGUID == g.ResourceGUID) && (q.Verb == "" || q.Verb == g.Verb) {
+			grants = append(grants, g)
+		}
+	}
+	return grants, nil
+}
+
+// Checks that a grant is valid and not the same as another. The caller
+//   must hold the lock.
+func (ga *memoryGrantAccessor) check(g Grant) error {
+	if err := g.validate(); err != nil {
+		return err
+	}
+	if _, ok := ga.s.liveResource(g.ResourceGUID); !ok {
+		return ErrForeignKey
+	}
+	if ok, _ := hasVerb(memoryInheritance{ga.s}, g.ResourceGUID, g.Verb); !ok {
+		return ErrUnknownVerb
+	}
+	for _, other := range ga.s.grants {
+		if other.Guid != g.Guid && other.Principal == g.Principal && other.Role == g.Role && other.ResourceGUID == g.ResourceGUID && other.Verb == g.Verb {
+			return ErrDuplicate
+		}
+	}
+	return nil
+}
+
+// Makes a grant and returns its guid.
+func (ga *memoryGrantAccessor) Add(g Grant) (string, error) {
+	ga.s.mu.Lock()
+	defer ga.s.mu.Unlock()
+
+	g.Guid = NewGuid()
+	g.Version = 1
+	if _, ok := ga.s.grants[g.Guid]; ok {
+		return "", ErrDuplicate
+	}
+	if err := ga.check(g); err != nil {
+		return "", err
+	}
+	e, err := newAuditEntry(ga.actor, Now(), OpCreate, EntityGrant, g.Guid, g.ResourceGUID, nil, g)
+	if err != nil {
+		return "", err
+	}
+	ga.s.grants[g.Guid] = g
+	ga.s.grantOrder = append(ga.s.grantOrder, g.Guid)
+	ga.s.record(e)
+	return g.Guid, nil
+}
+
+// Changes who a grant is for or what it allows and bumps its version,
+//   provided g.Version is still the stored version.
+func (ga *memoryGrantAccessor) Update(g Grant) error {
+	ga.s.mu.Lock()
+	defer ga.s.mu.Unlock()
+
+	if err := ga.check(g); err != nil {
+		return err
+	}
+	old, ok := ga.s.grants[g.Guid]
+	if !ok {
+		return &NotFoundError{EntityGrant, g.Guid}
+	}
+	if old.Version != g.Version {
+		return ErrVersionMismatch
+	}
+	g.Version++
+	e, err := newAuditEntry(ga.actor, Now(), OpUpdate, EntityGrant, g.Guid, g.ResourceGUID, old, g)
+	if err != nil {
+		return err
+	}
+	ga.s.grants[g.Guid] = g
+	ga.s.record(e)
+	return nil
+}
+
+// Revokes a grant, provided version is still its stored version.
+func (ga *memoryGrantAccessor) Remove(guid string, version int) error {
+	ga.s.mu.Lock()
+	defer ga.s.mu.Unlock()
+
+	g, ok := ga.s.grants[guid]
+	if !ok {
+		return &NotFoundError{EntityGrant, guid}
+	}
+	if g.Version != version {
+		return ErrVersionMismatch
+	}
+	e, err := newAuditEntry(ga.actor, Now(), OpDelete, EntityGrant, guid, g.ResourceGUID, g, nil)
+	if err != nil {
+		return err
+	}
+	delete(ga.s.grants, guid)
+	ga.s.grantOrder = removeGuid(ga.s.grantOrder, guid)
+	ga.s.record(e)
+	return nil
+}
+
+// Decides each request, in order.
+func (ga *memoryGrantAccessor) Authorize(reqs []AccessRequest) ([]Decision, error) {
+	ga.s.mu.RLock()
+	defer ga.s.mu.RUnlock()
+
+	decisions := make([]Decision, len(reqs))
+	targets := make(map[string]accessTarget)
+	for i, req := range reqs {
+		target, ok := targets[req.ResourceGUID]
+		if !ok {
+			if _, target.live = ga.s.liveResource(req.ResourceGUID); target.live {
+				target.inheritance, _ = inherit(req.ResourceGUID, memoryInheritance{ga.s})
+			}
+			targets[req.ResourceGUID] = target
+		}
+
+		var grants []Grant
+		if target.live {
+			scope := make(map[string]bool)
+			for _, guid := range target.scope(req.ResourceGUID) {
+				scope[guid] = true
+			}
+			for _, guid := range ga.s.grantOrder {
+				if g := ga.s.grants[guid]; g.Verb == req.Verb && scope[g.ResourceGUID] {
+					grants = append(grants, g)
+				}
+			}
+		}
+		decisions[i] = decide(req, target, grants)
+	}
+	return decisions, nil
+}
+
+// Gets the grants of a removed verb's name on its resource, and on the
+//   live resources inheriting from it, that are left without a verb of
+//   that name. The caller must hold the lock, and have moved the verb to
+//   the trash.
+func (s *MemoryStore) orphanedGrants(verb ResourceVerb) ([]Grant, error) {
+	found := map[string]bool{verb.ResourceGUID: true}
+	resources := []string{verb.ResourceGUID}
+	for i := 0; i < len(resources); i++ {
+		var subtypes []ResourceType
+		for _, rt := range s.types {
+			if _, ok := s.liveResource(rt.ResourceGUID); ok && rt.Type == resources[i] && !found[rt.ResourceGUID] {
+				subtypes = append(subtypes, rt)
+			}
+		}
+		sort.Slice(subtypes, func(i, j int) bool { return subtypes[i].Guid < subtypes[j].Guid })
+		for _, rt := range subtypes {
+			if !found[rt.ResourceGUID] {
+				found[rt.ResourceGUID] = true
+				resources = append(resources, rt.ResourceGUID)
+			}
+		}
+	}
+
+	var orphaned []Grant
+	for _, resource := range resources {
+		ok, err := hasVerb(memoryInheritance{s}, resource, verb.Verb)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+		for _, guid := range s.grantOrder {
+			if g := s.grants[guid]; g.ResourceGUID == resource && g.Verb == verb.Verb {
+				orphaned = append(orphaned, g)
+			}
+		}
+	}
+	return orphaned, nil
+}
@@ -0,0 +1,88 @@
+package accessors
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestMemoryResources(t *testing.T) {
+	testResources(t, NewMemoryStore())
+}
+
+func TestMemoryListResources(t *testing.T) {
+	testListResources(t, NewMemoryStore())
+}
+
+func TestMemoryResourceVerbs(t *testing.T) {
+	testResourceVerbs(t, NewMemoryStore())
+}
+
+func TestMemoryResourceTypes(t *testing.T) {
+	testResourceTypes(t, NewMemoryStore())
+}
+
+func TestMemoryDeleteResources(t *testing.T) {
+	testDeleteResources(t, NewMemoryStore())
+}
+
+func TestMemoryTrash(t *testing.T) {
+	testTrash(t, NewMemoryStore())
+}
+
+func TestMemoryAudit(t *testing.T) {
+	testAudit(t, NewMemoryStore())
+}
+
+func TestMemoryChanges(t *testing.T) {
+	testChanges(t, NewMemoryStore())
+}
+
+func TestMemoryCatalog(t *testing.T) {
+	testCatalog(t, NewMemoryStore())
+}
+
+func TestMemoryInheritance(t *testing.T) {
+	testInheritance(t, NewMemoryStore())
+}
+
+func TestMemoryGrants(t *testing.T) {
+	testGrants(t, NewMemoryStore())
+}
+
+func TestMemoryAuthorize(t *testing.T) {
+	testAuthorize(t, NewMemoryStore())
+}
+
+func TestMemoryConstraints(t *testing.T) {
+	testConstraints(t, NewMemoryStore())
+}
+
+func TestMemoryConcurrentWrites(t *testing.T) {
+	sequentialGuids()
+	s := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Resources().Insert(Resource{Name: fmt.Sprintf("resource %d", i)})
+			s.Resources().GetAll()
+		}(i)
+	}
+	wg.Wait()
+
+	resources, _ := s.Resources().GetAll()
+	if len(resources) != 50 {
+		t.Errorf("Expected 50 resources but got %d", len(resources))
+	}
+}
+
+func BenchmarkMemoryAuthorize(b *testing.B) {
+	benchmarkAuthorize(b, NewMemoryStore())
+}
+
+func BenchmarkMemoryAuthorizeBatch(b *testing.B) {
+	benchmarkAuthorizeBatch(b, NewMemoryStore())
+}
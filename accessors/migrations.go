@@ -0,0 +1,407 @@
+package accessors
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The schema of every dialect, as ordered migration files named
+//   <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration is one versioned change to the schema.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string // SQL applying the change
+	Down    string // SQL reverting the change
+}
+
+// MigrationStatus describes how far a database's schema is migrated.
+type MigrationStatus struct {
+	Version     int                   // Version of the last applied migration, 0 if none
+	Latest      int                   // Version of the newest known migration
+	Pending     []Migration           // Migrations not yet applied, in order
+	Interrupted *InterruptedMigration // A migration that failed partway, nil if none
+}
+
+// InterruptedMigration is a migration whose script failed partway through
+//   on a database that commits every DDL statement on its own (MySQL), so
+//   the statements before the failure stay applied. Running the migration
+//   in the same direction again resumes it at the statement that failed.
+type InterruptedMigration struct {
+	Migration
+	Direction  string // "up" or "down"
+	Applied    int    // Statements of the script already applied
+	Statements int    // Statements in the script
+}
+
+// Table recording which migrations have been applied.
+const migrationsTable = `CREATE TABLE IF NOT EXISTS schemaMigrations (
+	version INTEGER NOT NULL PRIMARY KEY
+)`
+
+// Table recording how far a migration that did not run in a transaction
+//   has got. It has a row only while such a migration is running or after
+//   it has failed.
+const progressTable = `CREATE TABLE IF NOT EXISTS schemaMigrationProgress (
+	version    INTEGER     NOT NULL PRIMARY KEY,
+	direction  VARCHAR(4)  NOT NULL,
+	statements INTEGER     NOT NULL
+)`
+
+// Returns the dialect's migrations in version order.
+func Migrations(d Dialect) ([]Migration, error) {
+	dir := path.Join("migrations", d.migrations)
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for %s: %v", d.Name, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		file := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(file, "."+direction+".sql")
+		parts := strings.SplitN(base, "_", 2)
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || len(parts) != 2 {
+			return nil, fmt.Errorf("malformed migration file name %s", file)
+		}
+
+		contents, err := migrationFiles.ReadFile(path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: parts[1]}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(contents)
+		} else {
+			m.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Creates the tables recording the migrations applied, if they do not
+//   exist yet.
+func createMigrationTables(db *sql.DB) error {
+	for _, table := range []string{migrationsTable, progressTable} {
+		if _, err := db.Exec(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns whether db has the named table.
+func hasTable(db *sql.DB, d Dialect, table string) (bool, error) {
+	stmt, err := d.prepare(db, d.hasTable)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	var n int
+	err = stmt.QueryRow(table).Scan(&n)
+	return n > 0, err
+}
+
+// Returns the version of the last migration applied to db, 0 if it has
+//   never been migrated.
+func schemaVersion(db *sql.DB, d Dialect) (int, error) {
+	if ok, err := hasTable(db, d, "schemaMigrations"); err != nil || !ok {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	if err := db.QueryRow("SELECT MAX(version) FROM schemaMigrations").Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Reports which migrations have been applied to db. Only reads, so it is
+//   safe to call from health checks.
+func GetMigrationStatus(db *sql.DB, d Dialect) (MigrationStatus, error) {
+	var status MigrationStatus
+	migrations, err := Migrations(d)
+	if err != nil {
+		return status, err
+	}
+
+	status.Version, err = schemaVersion(db, d)
+	if err != nil {
+		return status, err
+	}
+
+	for _, m := range migrations {
+		status.Latest = m.Version
+		if m.Version > status.Version {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+
+	status.Interrupted, err = interrupted(db, d, migrations)
+	return status, err
+}
+
+// Returns the migration that failed partway, if any.
+func interrupted(db *sql.DB, d Dialect, migrations []Migration) (*InterruptedMigration, error) {
+	if ok, err := hasTable(db, d, "schemaMigrationProgress"); err != nil || !ok {
+		return nil, err
+	}
+
+	var i InterruptedMigration
+	err := db.QueryRow("SELECT version, direction, statements FROM schemaMigrationProgress").Scan(&i.Version, &i.Direction, &i.Applied)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, m := range migrations {
+		if m.Version == i.Version {
+			i.Migration = m
+			script := m.Up
+			if i.Direction == "down" {
+				script = m.Down
+			}
+			i.Statements = len(splitStatements(script))
+			return &i, nil
+		}
+	}
+	return nil, fmt.Errorf("interrupted migration %d is unknown to this build", i.Version)
+}
+
+// Returns an error if a migration other than m in direction was
+//   interrupted, as it must be finished first.
+func checkInterrupted(status MigrationStatus, m Migration, direction string) error {
+	i := status.Interrupted
+	if i == nil || (i.Version == m.Version && i.Direction == direction) {
+		return nil
+	}
+	return i.err()
+}
+
+// Explains that the migration must be finished.
+func (i *InterruptedMigration) err() error {
+	return fmt.Errorf("migration %d_%s was interrupted after %d of %d statements; run \"resources migrate %s\" to finish it", i.Version, i.Name, i.Applied, i.Statements, i.Direction)
+}
+
+// Applies every pending migration to db, each in its own transaction
+//   where the database allows, and returns the ones applied. An
+//   interrupted migration is resumed first.
+func MigrateUp(db *sql.DB, d Dialect) ([]Migration, error) {
+	if err := createMigrationTables(db); err != nil {
+		return nil, err
+	}
+	status, err := GetMigrationStatus(db, d)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]Migration, 0, len(status.Pending))
+	for _, m := range status.Pending {
+		if err := checkInterrupted(status, m, "up"); err != nil {
+			return applied, err
+		}
+		if err := runMigration(db, d, m, "up", "INSERT INTO schemaMigrations (version) VALUES (?)"); err != nil {
+			return applied, fmt.Errorf("migration %d_%s: %v", m.Version, m.Name, err)
+		}
+		status.Interrupted = nil
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// Reverts the last applied migration of db and returns it. It returns
+//   sql.ErrNoRows if no migration has been applied.
+func MigrateDown(db *sql.DB, d Dialect) (Migration, error) {
+	if err := createMigrationTables(db); err != nil {
+		return Migration{}, err
+	}
+	status, err := GetMigrationStatus(db, d)
+	if err != nil {
+		return Migration{}, err
+	}
+	version := status.Version
+
+	migrations, err := Migrations(d)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	for _, m := range migrations {
+		if m.Version != version {
+			continue
+		}
+		if err := checkInterrupted(status, m, "down"); err != nil {
+			return m, err
+		}
+		if err := runMigration(db, d, m, "down", "DELETE FROM schemaMigrations WHERE version=?"); err != nil {
+			return m, fmt.Errorf("migration %d_%s: %v", m.Version, m.Name, err)
+		}
+		return m, nil
+	}
+	if version == 0 {
+		if err := checkInterrupted(status, Migration{}, "down"); err != nil {
+			return Migration{}, err
+		}
+		return Migration{}, sql.ErrNoRows
+	}
+	return Migration{}, fmt.Errorf("applied migration %d is unknown to this build", version)
+}
+
+// Runs the script of migration m in direction ("up" or "down") and
+//   record, its bookkeeping statement taking the version. Where DDL can be
+//   rolled back they all run in one transaction. MySQL commits every DDL
+//   statement on its own, so there the statements run one at a time and
+//   the number applied is recorded after each; if one fails the next run
+//   resumes at it rather than repeating those already applied.
+func runMigration(db *sql.DB, d Dialect, m Migration, direction, record string) error {
+	script := m.Up
+	if direction == "down" {
+		script = m.Down
+	}
+	statements := splitStatements(script)
+	if d.ddlCommits {
+		return runStatements(db, d, m.Version, direction, statements, record)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := execTx(tx, d, record, m.Version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Runs statements outside a transaction, recording the progress of
+//   migration version in schemaMigrationProgress, then runs record and
+//   clears the progress in one transaction.
+func runStatements(db *sql.DB, d Dialect, version int, direction string, statements []string, record string) error {
+	// Resume after the statements already applied
+	stmt, err := d.prepare(db, "SELECT statements FROM schemaMigrationProgress WHERE version=? AND direction=?")
+	if err != nil {
+		return err
+	}
+	var done int
+	err = stmt.QueryRow(version, direction).Scan(&done)
+	stmt.Close()
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	for i := done; i < len(statements); i++ {
+		if _, err := db.Exec(statements[i]); err != nil {
+			return fmt.Errorf("statement %d of %d failed; the ones before it stay applied: %v", i+1, len(statements), err)
+		}
+		if err := setProgress(db, d, version, direction, i+1); err != nil {
+			return err
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := execTx(tx, d, record, version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := execTx(tx, d, "DELETE FROM schemaMigrationProgress WHERE version=?", version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Records that the first applied statements of migration version have
+//   been applied in direction.
+func setProgress(db *sql.DB, d Dialect, version int, direction string, applied int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := execTx(tx, d, "DELETE FROM schemaMigrationProgress WHERE version=?", version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := execTx(tx, d, "INSERT INTO schemaMigrationProgress (version, direction, statements) VALUES (?, ?, ?)", version, direction, applied); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Splits a migration script into its statements. Statements end with a
+//   semicolon at the end of a line, so a semicolon ending a line inside a
+//   string literal or comment would split a statement in two; see
+//   migrations/README.md.
+func splitStatements(script string) []string {
+	statements := make([]string, 0)
+	for _, stmt := range strings.Split(script, ";\n") {
+		if stmt = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(stmt), ";")); stmt != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+// Returns an error unless every known migration has been applied to db.
+//   Run at startup so the service never runs against an unexpected schema.
+func CheckSchema(db *sql.DB, d Dialect) error {
+	status, err := GetMigrationStatus(db, d)
+	if err != nil {
+		return err
+	}
+	if status.Interrupted != nil {
+		return status.Interrupted.err()
+	}
+	if status.Version != status.Latest {
+		return fmt.Errorf("database schema is at version %d but this build expects %d; run \"resources migrate up\"", status.Version, status.Latest)
+	}
+	return nil
+}
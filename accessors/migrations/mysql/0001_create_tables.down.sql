@@ -0,0 +1,3 @@
+DROP TABLE resourceTypes;
+DROP TABLE resourceVerbs;
+DROP TABLE resources;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS resources (
+	guid        VARCHAR(36)  NOT NULL PRIMARY KEY,
+	name        VARCHAR(255) NOT NULL,
+	description TEXT         NOT NULL,
+	apiEndpoint VARCHAR(255) NOT NULL
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS resourceVerbs (
+	guid         VARCHAR(36)  NOT NULL PRIMARY KEY,
+	resourceGUID VARCHAR(36)  NOT NULL,
+	name         VARCHAR(255) NOT NULL,
+	description  TEXT         NOT NULL,
+	FOREIGN KEY (resourceGUID) REFERENCES resources (guid)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS resourceTypes (
+	guid         VARCHAR(36) NOT NULL PRIMARY KEY,
+	resourceGUID VARCHAR(36) NOT NULL,
+	type         VARCHAR(36) NOT NULL,
+	FOREIGN KEY (resourceGUID) REFERENCES resources (guid),
+	FOREIGN KEY (type) REFERENCES resources (guid)
+) ENGINE=InnoDB;
@@ -0,0 +1 @@
+ALTER TABLE resourceVerbs CHANGE verb name VARCHAR(255) NOT NULL;
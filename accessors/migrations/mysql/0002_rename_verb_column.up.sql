@@ -0,0 +1 @@
+ALTER TABLE resourceVerbs CHANGE name verb VARCHAR(255) NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX resources_name ON resources;
+
+DROP INDEX resources_created ON resources;
+
+ALTER TABLE resources DROP COLUMN created;
@@ -0,0 +1,5 @@
+ALTER TABLE resources ADD COLUMN created DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6);
+
+CREATE INDEX resources_created ON resources (created, guid);
+
+CREATE INDEX resources_name ON resources (name, guid);
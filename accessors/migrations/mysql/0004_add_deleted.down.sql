@@ -0,0 +1,7 @@
+DROP INDEX resourceVerbs_deleted ON resourceVerbs;
+
+DROP INDEX resources_deleted ON resources;
+
+ALTER TABLE resourceVerbs DROP COLUMN deleted;
+
+ALTER TABLE resources DROP COLUMN deleted;
@@ -0,0 +1,7 @@
+ALTER TABLE resources ADD COLUMN deleted DATETIME(6) NULL;
+
+ALTER TABLE resourceVerbs ADD COLUMN deleted DATETIME(6) NULL;
+
+CREATE INDEX resources_deleted ON resources (deleted);
+
+CREATE INDEX resourceVerbs_deleted ON resourceVerbs (deleted);
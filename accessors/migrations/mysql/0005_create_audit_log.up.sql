@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS auditLog (
+	guid         VARCHAR(36)  NOT NULL PRIMARY KEY,
+	actor        VARCHAR(255) NOT NULL,
+	changed      DATETIME(6)  NOT NULL,
+	operation    VARCHAR(36)  NOT NULL,
+	entity       VARCHAR(36)  NOT NULL,
+	entityGUID   VARCHAR(36)  NOT NULL,
+	resourceGUID VARCHAR(36)  NOT NULL,
+	beforeValues TEXT         NULL,
+	afterValues  TEXT         NULL
+) ENGINE=InnoDB;
+
+CREATE INDEX auditLog_changed ON auditLog (changed, guid);
+
+CREATE INDEX auditLog_actor ON auditLog (actor, changed);
+
+CREATE INDEX auditLog_resource ON auditLog (resourceGUID, changed);
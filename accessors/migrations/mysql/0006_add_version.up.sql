@@ -0,0 +1,3 @@
+ALTER TABLE resources ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+ALTER TABLE resourceVerbs ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,9 @@
+DROP INDEX resourceVerbs_modified ON resourceVerbs;
+
+DROP INDEX resources_modified ON resources;
+
+ALTER TABLE resourceVerbs DROP COLUMN modified;
+
+ALTER TABLE resourceVerbs DROP COLUMN created;
+
+ALTER TABLE resources DROP COLUMN modified;
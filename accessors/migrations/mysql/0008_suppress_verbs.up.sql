@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS suppressedVerbs (
+	guid         VARCHAR(36)  NOT NULL PRIMARY KEY,
+	resourceGUID VARCHAR(36)  NOT NULL,
+	verb         VARCHAR(255) NOT NULL,
+	created      DATETIME(6)  NOT NULL,
+	FOREIGN KEY (resourceGUID) REFERENCES resources (guid)
+) ENGINE=InnoDB;
+
+CREATE UNIQUE INDEX suppressedVerbs_resource ON suppressedVerbs (resourceGUID, verb);
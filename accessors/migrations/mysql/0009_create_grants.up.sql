@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS grants (
+	guid         VARCHAR(36)  NOT NULL PRIMARY KEY,
+	principal    VARCHAR(255) NOT NULL DEFAULT '',
+	role         VARCHAR(255) NOT NULL DEFAULT '',
+	resourceGUID VARCHAR(36)  NOT NULL,
+	verb         VARCHAR(255) NOT NULL,
+	created      DATETIME(6)  NOT NULL,
+	modified     DATETIME(6)  NOT NULL,
+	version      INTEGER      NOT NULL DEFAULT 1,
+	FOREIGN KEY (resourceGUID) REFERENCES resources (guid)
+) ENGINE=InnoDB;
+
+CREATE UNIQUE INDEX grants_unique ON grants (principal, role, resourceGUID, verb);
+
+CREATE INDEX grants_resource ON grants (resourceGUID, verb);
+
+CREATE INDEX grants_role ON grants (role);
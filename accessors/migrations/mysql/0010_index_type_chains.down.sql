@@ -0,0 +1,3 @@
+ALTER TABLE resourceTypes ADD INDEX resourceTypes_resourceGUID (resourceGUID), DROP INDEX resourceTypes_resource;
+
+ALTER TABLE resourceVerbs ADD INDEX resourceVerbs_resourceGUID (resourceGUID), DROP INDEX resourceVerbs_resource;
@@ -0,0 +1,5 @@
+ALTER TABLE resourceTypes ADD INDEX resourceTypes_resource (resourceGUID, type), DROP INDEX resourceTypes_unique;
+
+ALTER TABLE resourceVerbs DROP INDEX resourceVerbs_live_verb, DROP COLUMN liveVerb;
+
+ALTER TABLE resources DROP INDEX resources_live_name, DROP COLUMN liveName;
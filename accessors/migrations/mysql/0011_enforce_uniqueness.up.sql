@@ -0,0 +1,11 @@
+DELETE duplicate FROM resourceTypes duplicate JOIN resourceTypes kept ON kept.resourceGUID=duplicate.resourceGUID AND kept.type=duplicate.type AND kept.guid<duplicate.guid;
+
+UPDATE resourceVerbs duplicate JOIN resourceVerbs kept ON kept.resourceGUID=duplicate.resourceGUID AND kept.verb=duplicate.verb AND kept.deleted IS NULL AND (kept.created<duplicate.created OR (kept.created=duplicate.created AND kept.guid<duplicate.guid)) SET duplicate.deleted=CURRENT_TIMESTAMP(6), duplicate.modified=CURRENT_TIMESTAMP(6), duplicate.version=duplicate.version+1 WHERE duplicate.deleted IS NULL;
+
+UPDATE resources duplicate JOIN resources kept ON kept.name=duplicate.name AND kept.deleted IS NULL AND (kept.created<duplicate.created OR (kept.created=duplicate.created AND kept.guid<duplicate.guid)) SET duplicate.name=CONCAT(duplicate.name, ' (', duplicate.guid, ')'), duplicate.modified=CURRENT_TIMESTAMP(6), duplicate.version=duplicate.version+1 WHERE duplicate.deleted IS NULL;
+
+ALTER TABLE resources ADD COLUMN liveName VARCHAR(255) AS (IF(deleted IS NULL, name, NULL)) VIRTUAL, ADD UNIQUE INDEX resources_live_name (liveName);
+
+ALTER TABLE resourceVerbs ADD COLUMN liveVerb VARCHAR(255) AS (IF(deleted IS NULL, verb, NULL)) VIRTUAL, ADD UNIQUE INDEX resourceVerbs_live_verb (resourceGUID, liveVerb);
+
+ALTER TABLE resourceTypes ADD UNIQUE INDEX resourceTypes_unique (resourceGUID, type), DROP INDEX resourceTypes_resource;
@@ -0,0 +1 @@
+DROP INDEX auditLog_entity ON auditLog;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS resources (
+	guid        VARCHAR(36)  NOT NULL PRIMARY KEY,
+	name        VARCHAR(255) NOT NULL,
+	description TEXT         NOT NULL,
+	apiEndpoint VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS resourceVerbs (
+	guid         VARCHAR(36)  NOT NULL PRIMARY KEY,
+	resourceGUID VARCHAR(36)  NOT NULL REFERENCES resources (guid),
+	name         VARCHAR(255) NOT NULL,
+	description  TEXT         NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS resourceTypes (
+	guid         VARCHAR(36) NOT NULL PRIMARY KEY,
+	resourceGUID VARCHAR(36) NOT NULL REFERENCES resources (guid),
+	type         VARCHAR(36) NOT NULL REFERENCES resources (guid)
+);
@@ -0,0 +1 @@
+ALTER TABLE resourceVerbs RENAME COLUMN verb TO name;
@@ -0,0 +1,5 @@
+ALTER TABLE resources ADD COLUMN created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX resources_created ON resources (created, guid);
+
+CREATE INDEX resources_name ON resources (name, guid);
@@ -0,0 +1,3 @@
+ALTER TABLE resourceVerbs DROP COLUMN version;
+
+ALTER TABLE resources DROP COLUMN version;
@@ -0,0 +1,3 @@
+ALTER TABLE resources ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE resourceVerbs ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
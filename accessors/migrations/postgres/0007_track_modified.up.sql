@@ -0,0 +1,15 @@
+ALTER TABLE resources ADD COLUMN modified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE resourceVerbs ADD COLUMN created TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE resourceVerbs ADD COLUMN modified TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE resources SET modified=COALESCE(deleted, created);
+
+UPDATE resourceVerbs SET created=(SELECT created FROM resources WHERE resources.guid=resourceVerbs.resourceGUID);
+
+UPDATE resourceVerbs SET modified=COALESCE(deleted, created);
+
+CREATE INDEX resources_modified ON resources (modified);
+
+CREATE INDEX resourceVerbs_modified ON resourceVerbs (modified);
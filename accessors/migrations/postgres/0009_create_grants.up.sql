@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS grants (
+	guid         VARCHAR(36)  NOT NULL PRIMARY KEY,
+	principal    VARCHAR(255) NOT NULL DEFAULT '',
+	role         VARCHAR(255) NOT NULL DEFAULT '',
+	resourceGUID VARCHAR(36)  NOT NULL REFERENCES resources (guid),
+	verb         VARCHAR(255) NOT NULL,
+	created      TIMESTAMP    NOT NULL,
+	modified     TIMESTAMP    NOT NULL,
+	version      INTEGER      NOT NULL DEFAULT 1
+);
+
+CREATE UNIQUE INDEX grants_unique ON grants (principal, role, resourceGUID, verb);
+
+CREATE INDEX grants_resource ON grants (resourceGUID, verb);
+
+CREATE INDEX grants_role ON grants (role);
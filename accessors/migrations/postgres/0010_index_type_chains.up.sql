@@ -0,0 +1,3 @@
+CREATE INDEX resourceVerbs_resource ON resourceVerbs (resourceGUID, deleted);
+
+CREATE INDEX resourceTypes_resource ON resourceTypes (resourceGUID, type);
@@ -0,0 +1,7 @@
+DROP INDEX resourceTypes_unique;
+
+CREATE INDEX resourceTypes_resource ON resourceTypes (resourceGUID, type);
+
+DROP INDEX resourceVerbs_live_verb;
+
+DROP INDEX resources_live_name;
@@ -0,0 +1,13 @@
+DELETE FROM resourceTypes WHERE EXISTS (SELECT 1 FROM resourceTypes kept WHERE kept.resourceGUID=resourceTypes.resourceGUID AND kept.type=resourceTypes.type AND kept.guid<resourceTypes.guid);
+
+UPDATE resourceVerbs SET deleted=CURRENT_TIMESTAMP, modified=CURRENT_TIMESTAMP, version=version+1 WHERE deleted IS NULL AND EXISTS (SELECT 1 FROM resourceVerbs kept WHERE kept.resourceGUID=resourceVerbs.resourceGUID AND kept.verb=resourceVerbs.verb AND kept.deleted IS NULL AND (kept.created<resourceVerbs.created OR (kept.created=resourceVerbs.created AND kept.guid<resourceVerbs.guid)));
+
+UPDATE resources SET name=name || ' (' || guid || ')', modified=CURRENT_TIMESTAMP, version=version+1 WHERE deleted IS NULL AND EXISTS (SELECT 1 FROM resources kept WHERE kept.name=resources.name AND kept.deleted IS NULL AND (kept.created<resources.created OR (kept.created=resources.created AND kept.guid<resources.guid)));
+
+CREATE UNIQUE INDEX resources_live_name ON resources (name) WHERE deleted IS NULL;
+
+CREATE UNIQUE INDEX resourceVerbs_live_verb ON resourceVerbs (resourceGUID, verb) WHERE deleted IS NULL;
+
+DROP INDEX resourceTypes_resource;
+
+CREATE UNIQUE INDEX resourceTypes_unique ON resourceTypes (resourceGUID, type);
@@ -0,0 +1 @@
+ALTER TABLE resourceVerbs RENAME COLUMN name TO verb;
@@ -0,0 +1,5 @@
+DROP INDEX resources_name;
+
+DROP INDEX resources_created;
+
+ALTER TABLE resources DROP COLUMN created;
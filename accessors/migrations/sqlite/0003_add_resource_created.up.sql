@@ -0,0 +1,5 @@
+ALTER TABLE resources ADD COLUMN created TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+CREATE INDEX resources_created ON resources (created, guid);
+
+CREATE INDEX resources_name ON resources (name, guid);
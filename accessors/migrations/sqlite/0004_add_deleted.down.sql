@@ -0,0 +1,7 @@
+DROP INDEX resourceVerbs_deleted;
+
+DROP INDEX resources_deleted;
+
+ALTER TABLE resourceVerbs DROP COLUMN deleted;
+
+ALTER TABLE resources DROP COLUMN deleted;
@@ -0,0 +1,7 @@
+ALTER TABLE resources ADD COLUMN deleted TIMESTAMP NULL;
+
+ALTER TABLE resourceVerbs ADD COLUMN deleted TIMESTAMP NULL;
+
+CREATE INDEX resources_deleted ON resources (deleted);
+
+CREATE INDEX resourceVerbs_deleted ON resourceVerbs (deleted);
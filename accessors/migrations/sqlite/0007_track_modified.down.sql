@@ -0,0 +1,9 @@
+DROP INDEX resourceVerbs_modified;
+
+DROP INDEX resources_modified;
+
+ALTER TABLE resourceVerbs DROP COLUMN modified;
+
+ALTER TABLE resourceVerbs DROP COLUMN created;
+
+ALTER TABLE resources DROP COLUMN modified;
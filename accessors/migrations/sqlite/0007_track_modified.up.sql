@@ -0,0 +1,15 @@
+ALTER TABLE resources ADD COLUMN modified TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+ALTER TABLE resourceVerbs ADD COLUMN created TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+ALTER TABLE resourceVerbs ADD COLUMN modified TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+UPDATE resources SET modified=COALESCE(deleted, created);
+
+UPDATE resourceVerbs SET created=(SELECT created FROM resources WHERE resources.guid=resourceVerbs.resourceGUID);
+
+UPDATE resourceVerbs SET modified=COALESCE(deleted, created);
+
+CREATE INDEX resources_modified ON resources (modified);
+
+CREATE INDEX resourceVerbs_modified ON resourceVerbs (modified);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS suppressedVerbs (
+	guid         VARCHAR(36)  NOT NULL PRIMARY KEY,
+	resourceGUID VARCHAR(36)  NOT NULL REFERENCES resources (guid),
+	verb         VARCHAR(255) NOT NULL,
+	created      TIMESTAMP    NOT NULL
+);
+
+CREATE UNIQUE INDEX suppressedVerbs_resource ON suppressedVerbs (resourceGUID, verb);
@@ -0,0 +1,3 @@
+DROP INDEX resourceTypes_resource;
+
+DROP INDEX resourceVerbs_resource;
@@ -0,0 +1 @@
+CREATE INDEX auditLog_entity ON auditLog (entity, changed);
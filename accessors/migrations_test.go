@@ -0,0 +1,225 @@
+package accessors
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestMigrationsAreOrdered(t *testing.T) {
+	for _, d := range []Dialect{MySQL, Postgres, SQLite} {
+		migrations, err := Migrations(d)
+		if err != nil {
+			t.Errorf("An unexpected error occurred reading the %s migrations: %v", d.Name, err)
+			continue
+		}
+		if len(migrations) == 0 {
+			t.Errorf("Expected %s migrations but got none", d.Name)
+		}
+		for i, m := range migrations {
+			if m.Version != i+1 {
+				t.Errorf("Expected %s migration %d but got %d_%s", d.Name, i+1, m.Version, m.Name)
+			}
+		}
+	}
+}
+
+func TestMigrateUpAndDown(t *testing.T) {
+	db, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred opening the SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	migrations, _ := Migrations(SQLite)
+	latest := migrations[len(migrations)-1].Version
+
+	if err := CheckSchema(db, SQLite); err == nil {
+		t.Errorf("Expected an error checking an unmigrated schema")
+	}
+
+	// Checking only reads
+	status, err := GetMigrationStatus(db, SQLite)
+	if err != nil || status.Version != 0 || len(status.Pending) != len(migrations) {
+		t.Errorf("Expected version 0 with every migration pending but got %+v, %v", status, err)
+	}
+	if ok, err := hasTable(db, SQLite, "schemaMigrations"); ok || err != nil {
+		t.Errorf("Expected no schemaMigrations table but got %v, %v", ok, err)
+	}
+
+	applied, err := MigrateUp(db, SQLite)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred migrating up: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Errorf("Expected %d migrations to be applied but got %d", len(migrations), len(applied))
+	}
+	if err := CheckSchema(db, SQLite); err != nil {
+		t.Errorf("An unexpected error occurred checking the schema: %v", err)
+	}
+
+	// Applying again is a no-op
+	applied, err = MigrateUp(db, SQLite)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("Expected no migrations to be applied but got %v, %v", applied, err)
+	}
+
+	m, err := MigrateDown(db, SQLite)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred migrating down: %v", err)
+	}
+	if m.Version != latest {
+		t.Errorf("Expected to revert migration %d but reverted %d", latest, m.Version)
+	}
+
+	status, err = GetMigrationStatus(db, SQLite)
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting the migration status: %v", err)
+	}
+	if status.Version != latest-1 || status.Latest != latest || len(status.Pending) != 1 {
+		t.Errorf("Unexpected migration status %+v", status)
+	}
+
+	// All the way down and back up again
+	for {
+		if _, err := MigrateDown(db, SQLite); err == sql.ErrNoRows {
+			break
+		} else if err != nil {
+			t.Fatalf("An unexpected error occurred migrating down: %v", err)
+		}
+	}
+	if _, err := MigrateUp(db, SQLite); err != nil {
+		t.Errorf("An unexpected error occurred migrating up: %v", err)
+	}
+	if err := CheckSchema(db, SQLite); err != nil {
+		t.Errorf("An unexpected error occurred checking the schema: %v", err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	statements := splitStatements("CREATE TABLE a (x INT);\n\nCREATE TABLE b (y INT);\n")
+	if len(statements) != 2 || statements[0] != "CREATE TABLE a (x INT)" || statements[1] != "CREATE TABLE b (y INT)" {
+		t.Errorf("Unexpected statements %q", statements)
+	}
+}
+
+func TestResumeInterruptedMigration(t *testing.T) {
+	db, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred opening the SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	// SQLite run as MySQL is, one statement at a time outside a transaction
+	d := SQLite
+	d.ddlCommits = true
+	migrations, _ := Migrations(d)
+	k := 0
+	for migrations[k].Name != "enforce_uniqueness" {
+		k++
+	}
+	target := migrations[k]
+	if err := createMigrationTables(db); err != nil {
+		t.Fatalf("An unexpected error occurred creating the migration tables: %v", err)
+	}
+	for _, m := range migrations[:k] {
+		if err := runMigration(db, d, m, "up", "INSERT INTO schemaMigrations (version) VALUES (?)"); err != nil {
+			t.Fatalf("An unexpected error occurred applying %d_%s: %v", m.Version, m.Name, err)
+		}
+	}
+
+	// The fifth statement of the uniqueness migration fails on an index in
+	//   its way
+	if _, err := db.Exec("CREATE TABLE blocker (x INTEGER)"); err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if _, err := db.Exec("CREATE INDEX resourceVerbs_live_verb ON blocker (x)"); err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if _, err := MigrateUp(db, d); err == nil {
+		t.Fatalf("Expected the migration to fail")
+	}
+
+	status, err := GetMigrationStatus(db, d)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred getting the migration status: %v", err)
+	}
+	i := status.Interrupted
+	if i == nil || i.Version != target.Version || i.Direction != "up" || i.Applied != 4 || i.Statements != 7 {
+		t.Fatalf("Expected migration %d interrupted after 4 of 7 statements but got %+v", target.Version, i)
+	}
+	if err := CheckSchema(db, d); err == nil {
+		t.Errorf("Expected an error checking an interrupted schema")
+	}
+	if _, err := MigrateDown(db, d); err == nil {
+		t.Errorf("Expected an error reverting past an interrupted migration")
+	}
+
+	// Once the index is out of the way the migration resumes where it failed
+	if _, err := db.Exec("DROP TABLE blocker"); err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	applied, err := MigrateUp(db, d)
+	if err != nil || len(applied) != len(migrations)-k {
+		t.Fatalf("Expected the migration and those after it to be applied but got %v, %v", applied, err)
+	}
+	if err := CheckSchema(db, d); err != nil {
+		t.Errorf("An unexpected error occurred checking the schema: %v", err)
+	}
+}
+
+// Checks that the uniqueness migration resolves the duplicates stored
+//   before it: the oldest of each keeps its name or verb, the others are
+//   renamed or trashed and repeated types are dropped.
+func TestUniquenessMigrationResolvesDuplicates(t *testing.T) {
+	db, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred opening the SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	migrations, _ := Migrations(SQLite)
+	k := 0
+	for migrations[k].Name != "enforce_uniqueness" {
+		k++
+	}
+	if err := createMigrationTables(db); err != nil {
+		t.Fatalf("An unexpected error occurred creating the migration tables: %v", err)
+	}
+	for _, m := range migrations[:k] {
+		if err := runMigration(db, SQLite, m, "up", "INSERT INTO schemaMigrations (version) VALUES (?)"); err != nil {
+			t.Fatalf("An unexpected error occurred applying %d_%s: %v", m.Version, m.Name, err)
+		}
+	}
+
+	for _, statement := range []string{
+		"INSERT INTO resources (guid, name, description, apiEndpoint, created) VALUES ('1', 'board', 'first', 'tmt.byu.edu/boards/1', '2016-01-01 00:00:00')",
+		"INSERT INTO resources (guid, name, description, apiEndpoint, created) VALUES ('2', 'board', 'second', 'tmt.byu.edu/boards/2', '2016-01-02 00:00:00')",
+		"INSERT INTO resourceVerbs (guid, resourceGUID, verb, description, created) VALUES ('3', '1', 'erase', 'first', '2016-01-01 00:00:00')",
+		"INSERT INTO resourceVerbs (guid, resourceGUID, verb, description, created) VALUES ('4', '1', 'erase', 'second', '2016-01-02 00:00:00')",
+		"INSERT INTO resourceTypes (guid, resourceGUID, type) VALUES ('5', '2', '1')",
+		"INSERT INTO resourceTypes (guid, resourceGUID, type) VALUES ('6', '2', '1')",
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("An unexpected error occurred: %v", err)
+		}
+	}
+	if _, err := MigrateUp(db, SQLite); err != nil {
+		t.Fatalf("An unexpected error occurred migrating up: %v", err)
+	}
+
+	for _, check := range []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT name FROM resources WHERE guid='1'", "board"},
+		{"SELECT name FROM resources WHERE guid='2'", "board (2)"},
+		{"SELECT guid FROM resourceVerbs WHERE deleted IS NULL", "3"},
+		{"SELECT guid FROM resourceVerbs WHERE deleted IS NOT NULL", "4"},
+		{"SELECT guid FROM resourceTypes", "5"},
+	} {
+		var got string
+		if err := db.QueryRow(check.query).Scan(&got); err != nil || got != check.expected {
+			t.Errorf("Expected %v from %q but got %v, %v", check.expected, check.query, got, err)
+		}
+	}
+}
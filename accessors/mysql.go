@@ -0,0 +1,35 @@
+package accessors
+
+import (
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL is the dialect of the production database.
+var MySQL = Dialect{
+	Name:       "mysql",
+	migrations: "mysql",
+	ddlCommits: true,
+	translate:  mysqlError,
+	hasTable:   "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema=DATABASE() AND table_name=?",
+}
+
+// MySQL server error numbers.
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlRowIsReferenced = 1451
+	mysqlNoReferencedRow = 1452
+)
+
+func mysqlError(err error) error {
+	e, ok := err.(*mysql.MySQLError)
+	if !ok {
+		return err
+	}
+	switch e.Number {
+	case mysqlDuplicateEntry:
+		return ErrDuplicate
+	case mysqlRowIsReferenced, mysqlNoReferencedRow:
+		return ErrForeignKey
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package accessors
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// Returns a store backed by the MySQL database at MYSQL_TEST_DSN, skipping
+//   the test when it is not set. The database is migrated and its tables
+//   emptied first.
+func newMySQLStore(t *testing.T) *SQLStore {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not set")
+	}
+	db, err := sql.Open(MySQL.Name, dsn)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred opening the MySQL database: %v", err)
+	}
+	if _, err := MigrateUp(db, MySQL); err != nil {
+		t.Fatalf("An unexpected error occurred migrating the MySQL database: %v", err)
+	}
+	clearTables(t, db)
+	return NewSQLStore(db, MySQL)
+}
+
+func TestMySQLResources(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testResources(t, s)
+}
+
+func TestMySQLListResources(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testListResources(t, s)
+}
+
+func TestMySQLResourceVerbs(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testResourceVerbs(t, s)
+}
+
+func TestMySQLResourceTypes(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testResourceTypes(t, s)
+}
+
+func TestMySQLDeleteResources(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testDeleteResources(t, s)
+}
+
+func TestMySQLTrash(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testTrash(t, s)
+}
+
+func TestMySQLAudit(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testAudit(t, s)
+}
+
+func TestMySQLChanges(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testChanges(t, s)
+}
+
+func TestMySQLCatalog(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testCatalog(t, s)
+}
+
+func TestMySQLInheritance(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testInheritance(t, s)
+}
+
+func TestMySQLGrants(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testGrants(t, s)
+}
+
+func TestMySQLAuthorize(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testAuthorize(t, s)
+}
+
+func TestMySQLConstraints(t *testing.T) {
+	s := newMySQLStore(t)
+	defer s.DB.Close()
+	testConstraints(t, s)
+}
@@ -0,0 +1,34 @@
+package accessors
+
+import (
+	"github.com/lib/pq"
+)
+
+// Postgres is the dialect of the campus PostgreSQL databases.
+var Postgres = Dialect{
+	Name:       "postgres",
+	migrations: "postgres",
+	bind:       bindDollar,
+	translate:  postgresError,
+	hasTable:   "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema=current_schema() AND table_name=LOWER(?)",
+}
+
+// PostgreSQL SQLSTATE codes.
+const (
+	postgresUniqueViolation     = "23505"
+	postgresForeignKeyViolation = "23503"
+)
+
+func postgresError(err error) error {
+	e, ok := err.(*pq.Error)
+	if !ok {
+		return err
+	}
+	switch e.Code {
+	case postgresUniqueViolation:
+		return ErrDuplicate
+	case postgresForeignKeyViolation:
+		return ErrForeignKey
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package accessors
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// Returns a store backed by the PostgreSQL database at POSTGRES_TEST_DSN,
+//   skipping the test when it is not set. The database is migrated and
+//   its tables emptied first.
+func newPostgresStore(t *testing.T) *SQLStore {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+	db, err := sql.Open(Postgres.Name, dsn)
+	if err != nil {
+		t.Fatalf("An unexpected error occurred opening the PostgreSQL database: %v", err)
+	}
+	if _, err := MigrateUp(db, Postgres); err != nil {
+		t.Fatalf("An unexpected error occurred migrating the PostgreSQL database: %v", err)
+	}
+	clearTables(t, db)
+	return NewSQLStore(db, Postgres)
+}
+
+func TestPostgresResources(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testResources(t, s)
+}
+
+func TestPostgresListResources(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testListResources(t, s)
+}
+
+func TestPostgresResourceVerbs(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testResourceVerbs(t, s)
+}
+
+func TestPostgresResourceTypes(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testResourceTypes(t, s)
+}
+
+func TestPostgresDeleteResources(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testDeleteResources(t, s)
+}
+
+func TestPostgresTrash(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testTrash(t, s)
+}
+
+func TestPostgresAudit(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testAudit(t, s)
+}
+
+func TestPostgresChanges(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testChanges(t, s)
+}
+
+func TestPostgresCatalog(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testCatalog(t, s)
+}
+
+func TestPostgresInheritance(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testInheritance(t, s)
+}
+
+func TestPostgresGrants(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testGrants(t, s)
+}
+
+func TestPostgresAuthorize(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testAuthorize(t, s)
+}
+
+func TestPostgresConstraints(t *testing.T) {
+	s := newPostgresStore(t)
+	defer s.DB.Close()
+	testConstraints(t, s)
+}
+
+func TestBindDollar(t *testing.T) {
+	query := bindDollar("UPDATE resources SET name=?, description=?, apiEndpoint=? WHERE guid=?")
+	expected := "UPDATE resources SET name=$1, description=$2, apiEndpoint=$3 WHERE guid=$4"
+	if query != expected {
+		t.Errorf("Expected %v but got %v", expected, query)
+	}
+}
@@ -0,0 +1,114 @@
+package accessors
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Orders in which resources can be listed.
+const (
+	SortByCreated     = "created"  // Oldest first
+	SortByCreatedDesc = "-created" // Newest first
+	SortByName        = "name"     // A to Z
+	SortByNameDesc    = "-name"    // Z to A
+)
+
+var (
+	// Returned for a cursor that is malformed or was issued for another sort.
+	ErrInvalidCursor = kindError(ErrInvalid, "accessors: invalid cursor")
+
+	// Returned for a sort that is not one of the SortBy constants.
+	ErrInvalidSort = kindError(ErrInvalid, "accessors: invalid sort")
+)
+
+// ResourceQuery selects one page of resources.
+type ResourceQuery struct {
+	Name              string // Only resources with exactly this name, if set
+	APIEndpointPrefix string // Only resources whose api endpoint starts with this, if set
+	Sort              string // One of the SortBy constants; SortByCreated if empty
+	Limit             int    // Largest number of resources to return
+	Cursor            string // Next value of the previous page, if any
+}
+
+// ResourcePage is one page of a resource listing.
+type ResourcePage struct {
+	Resources []Resource `json:"resources"`
+	Next      string     `json:"next"`  // Cursor of the following page, empty on the last page
+	Total     int        `json:"total"` // Number of resources matching the filters over all pages
+}
+
+// Position in a listing: the sort key and guid of the last resource of a
+//   page. Encoded as opaque base64 for clients.
+type resourceCursor struct {
+	Sort    string    `json:"s"`
+	Name    string    `json:"n,omitempty"`
+	Created time.Time `json:"c,omitempty"`
+	Guid    string    `json:"g"`
+}
+
+// Returns the cursor that continues a listing after r.
+func newResourceCursor(sort string, r Resource) string {
+	c := resourceCursor{Sort: sort, Guid: r.Guid}
+	if sort == SortByName || sort == SortByNameDesc {
+		c.Name = r.Name
+	} else {
+		c.Created = r.Created
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decodes a cursor issued for the given sort.
+func parseResourceCursor(sort, cursor string) (resourceCursor, error) {
+	var c resourceCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort || c.Guid == "" {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Checks the query's sort and limit, filling in the default sort.
+func (q *ResourceQuery) normalize() error {
+	switch q.Sort {
+	case "":
+		q.Sort = SortByCreated
+	case SortByCreated, SortByCreatedDesc, SortByName, SortByNameDesc:
+	default:
+		return ErrInvalidSort
+	}
+	if q.Limit < 1 {
+		return errors.New("accessors: limit must be positive")
+	}
+	return nil
+}
+
+// Whether resource a sorts before resource b.
+func resourceLess(sort string, a, b Resource) bool {
+	switch sort {
+	case SortByName:
+		return a.Name < b.Name || (a.Name == b.Name && a.Guid < b.Guid)
+	case SortByNameDesc:
+		return a.Name > b.Name || (a.Name == b.Name && a.Guid > b.Guid)
+	case SortByCreatedDesc:
+		return a.Created.After(b.Created) || (a.Created.Equal(b.Created) && a.Guid > b.Guid)
+	}
+	return a.Created.Before(b.Created) || (a.Created.Equal(b.Created) && a.Guid < b.Guid)
+}
+
+// Escapes the LIKE wildcards in s, using ! as the escape character.
+func escapeLike(s string) string {
+	escaped := make([]rune, 0, len(s))
+	for _, c := range s {
+		if c == '!' || c == '%' || c == '_' {
+			escaped = append(escaped, '!')
+		}
+		escaped = append(escaped, c)
+	}
+	return string(escaped)
+}
@@ -2,7 +2,7 @@ package accessors
 
 import (
 	"database/sql"
-	_ "github.com/go-sql-driver/mysql"
+	"time"
 )
 
 // Resource struct that reflects the resources table.
@@ -11,38 +11,74 @@ type Resource struct {
 	Name        string         `json:"name"`
 	Description string         `json:"description"`
 	APIEndpoint string         `json:"apiEndpoint"`
+	Created     time.Time      `json:"created"`
+	Modified    time.Time      `json:"modified"` // Last change to the resource or its verbs
+	Version     int            `json:"version"`  // Incremented by every change to the resource or its verbs
 	Verbs       []ResourceVerb `json:"verbs"`
 }
 
+// Dependents are the rows that refer to a resource.
+type Dependents struct {
+	Verbs []ResourceVerb `json:"verbs"`
+	Types []ResourceType `json:"types"` // Associations with the resource on either side
+}
+
+// Whether nothing refers to the resource.
+func (d Dependents) empty() bool {
+	return len(d.Verbs) == 0 && len(d.Types) == 0
+}
+
+// The columns of the resources table, in the order scanResource reads them.
+const resourceColumns = "guid, name, description, apiEndpoint, created, modified, version"
+
+// Anything a row can be scanned from: *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// Scans the resourceColumns of a row into r.
+func scanResource(row scanner, r *Resource) error {
+	return row.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint, timestamp{&r.Created}, timestamp{&r.Modified}, &r.Version)
+}
+
 type ResourceAccessor struct {
-	DB *sql.DB // Database connection
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+	Actor   string  // Who the changes are recorded as made by
 }
 
 // Returns a new resource accessor.
 func NewResourceAccessor(db *sql.DB) *ResourceAccessor {
-	return &ResourceAccessor{db}
+	return &ResourceAccessor{db, MySQL, ""}
 }
 
-// Gets the resource with the given id.
+// Gets the resource with the given id, unless it is in the trash.
 func (ra *ResourceAccessor) Get(guid string) (Resource, error) {
+	return getResource(ra.DB, ra.Dialect, guid)
+}
+
+// Gets a resource that is not in the trash, in or out of a transaction.
+func getResource(db preparer, d Dialect, guid string) (Resource, error) {
 	r := Resource{}
-	stmt, err := ra.DB.Prepare("SELECT * FROM resources WHERE guid=?")
+	stmt, err := d.prepare(db, "SELECT "+resourceColumns+" FROM resources WHERE guid=? AND deleted IS NULL")
 	if err != nil {
 		return r, err
 	}
+	defer stmt.Close()
 
 	row := stmt.QueryRow(guid)
-	err = row.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint)
-	return r, err
+	err = scanResource(row, &r)
+	return r, notFound(err, EntityResource, guid)
 }
 
-// Gets the resource with the given id.
+// Gets all resources that are not in the trash.
 func (ra *ResourceAccessor) GetAll() ([]Resource, error) {
 	resources := make([]Resource, 0)
-	stmt, err := ra.DB.Prepare("SELECT * FROM resources")
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT "+resourceColumns+" FROM resources WHERE deleted IS NULL")
 	if err != nil {
 		return resources, err
 	}
+	defer stmt.Close()
 
 	rows, err := stmt.Query()
 	if err != nil {
@@ -52,44 +88,429 @@ func (ra *ResourceAccessor) GetAll() ([]Resource, error) {
 
 	for rows.Next() {
 		var r Resource
-		rows.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint)
+		if err := scanResource(rows, &r); err != nil {
+			return resources, err
+		}
 		resources = append(resources, r)
 	}
+	return resources, rows.Err()
+}
+
+// Gets the last time any resource, or the verbs of any resource, changed,
+//   including by being moved to the trash. The audit log keeps the changes
+//   to resources since purged, so the time never goes back. Each maximum
+//   is read from an index, resources_modified or auditLog_entity, rather
+//   than by scanning. Returns the zero time if there are no resources.
+func (ra *ResourceAccessor) LastModified() (time.Time, error) {
+	var modified time.Time
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT MAX(modified) FROM (SELECT MAX(modified) AS modified FROM resources UNION ALL SELECT MAX(changed) FROM auditLog WHERE entity=? UNION ALL SELECT MAX(changed) FROM auditLog WHERE entity=?) latest")
+	if err != nil {
+		return modified, err
+	}
+	defer stmt.Close()
 
-	return resources, nil
+	err = stmt.QueryRow(EntityResource, EntityVerb).Scan(timestamp{&modified})
+	return modified, err
 }
 
-// Create a new resource.
-func (ra *ResourceAccessor) Insert(r Resource) error {
-	stmt, err := ra.DB.Prepare("INSERT INTO resources (guid, name, description, apiEndpoint) VALUES (?,?,?,?)")
+// Gets one page of the resources matching the query, along with the
+//   cursor of the next page and the number of matching resources. Trashed
+//   resources are left out.
+func (ra *ResourceAccessor) List(q ResourceQuery) (ResourcePage, error) {
+	page := ResourcePage{Resources: make([]Resource, 0)}
+	if err := q.normalize(); err != nil {
+		return page, err
+	}
+
+	// Filters
+	filters := ""
+	args := make([]interface{}, 0)
+	if q.Name != "" {
+		filters += " AND name=?"
+		args = append(args, q.Name)
+	}
+	if q.APIEndpointPrefix != "" {
+		filters += " AND apiEndpoint LIKE ? ESCAPE '!'"
+		args = append(args, escapeLike(q.APIEndpointPrefix)+"%")
+	}
+
+	// Count every match
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT COUNT(*) FROM resources WHERE deleted IS NULL"+filters)
 	if err != nil {
-		return err
+		return page, err
+	}
+	defer stmt.Close()
+	if err := stmt.QueryRow(args...).Scan(&page.Total); err != nil {
+		return page, err
 	}
 
-	_, err = stmt.Exec(NewGuid(), r.Name, r.Description, r.APIEndpoint)
-	return err
+	// Order by the sort key, then guid so that ties are stable
+	column, direction, op := "created", "ASC", ">"
+	if q.Sort == SortByName || q.Sort == SortByNameDesc {
+		column = "name"
+	}
+	if q.Sort == SortByCreatedDesc || q.Sort == SortByNameDesc {
+		direction, op = "DESC", "<"
+	}
+
+	// Continue after the cursor
+	if q.Cursor != "" {
+		c, err := parseResourceCursor(q.Sort, q.Cursor)
+		if err != nil {
+			return page, err
+		}
+		var key interface{} = c.Created
+		if column == "name" {
+			key = c.Name
+		}
+		filters += " AND (" + column + op + "? OR (" + column + "=? AND guid" + op + "?))"
+		args = append(args, key, key, c.Guid)
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	query := "SELECT " + resourceColumns + " FROM resources WHERE deleted IS NULL" + filters +
+		" ORDER BY " + column + " " + direction + ", guid " + direction + " LIMIT ?"
+	args = append(args, q.Limit+1)
+
+	pageStmt, err := ra.Dialect.prepare(ra.DB, query)
+	if err != nil {
+		return page, err
+	}
+	defer pageStmt.Close()
+
+	rows, err := pageStmt.Query(args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Resource
+		if err := scanResource(rows, &r); err != nil {
+			return page, err
+		}
+		page.Resources = append(page.Resources, r)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	if len(page.Resources) > q.Limit {
+		page.Resources = page.Resources[:q.Limit]
+		page.Next = newResourceCursor(q.Sort, page.Resources[q.Limit-1])
+	}
+	return page, nil
 }
 
-// Renames a resource with the given id to have the provided name.
+// Queries for the guid of the live resource holding a name, given the name
+//   or the guid of a trashed resource of that name.
+const (
+	resourceNamed        = "SELECT guid FROM resources WHERE name=? AND deleted IS NULL"
+	resourceNamedAsTrash = "SELECT live.guid FROM resources live JOIN resources trashed ON trashed.name=live.name WHERE trashed.guid=? AND live.deleted IS NULL"
+)
+
+// Create a new resource and return its guid. Returns a *DuplicateError if
+//   another live resource has the same name.
+func (ra *ResourceAccessor) Insert(r Resource) (string, error) {
+	guid, now := NewGuid(), Now()
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	if err := execTx(tx, ra.Dialect, "INSERT INTO resources (guid, name, description, apiEndpoint, created, modified) VALUES (?,?,?,?,?,?)", guid, r.Name, r.Description, r.APIEndpoint, now, now); err != nil {
+		tx.Rollback()
+		return "", duplicate(ra.DB, ra.Dialect, err, EntityResource, resourceNamed, r.Name)
+	}
+
+	r.Guid, r.Created = guid, now
+	if err := record(tx, ra.Dialect, ra.Actor, now, OpCreate, EntityResource, guid, guid, nil, resourceValues(r)); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	return guid, tx.Commit()
+}
+
+// Saves the name, description and api endpoint of a resource and bumps its
+//   version, provided that r.Version is still the stored version. The check
+//   and the write are one statement, so of two concurrent updates from the
+//   same version only one succeeds. Returns ErrVersionMismatch if the
+//   resource has changed since r was read, a *NotFoundError if it does not
+//   exist or is in the trash and a *DuplicateError if another live resource
+//   has the new name.
 func (ra *ResourceAccessor) Update(r Resource) error {
-	stmt, err := ra.DB.Prepare("UPDATE resources SET name=?, description=?, apiEndpoint=? WHERE guid=?")
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	before, err := getResource(tx, ra.Dialect, r.Guid)
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
-	_, err = stmt.Exec(r.Name, r.Description, r.APIEndpoint, r.Guid)
+	stmt, err := ra.Dialect.prepare(tx, "UPDATE resources SET name=?, description=?, apiEndpoint=?, version=version+1, modified=? WHERE guid=? AND version=? AND deleted IS NULL")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	now := Now()
+	result, err := stmt.Exec(r.Name, r.Description, r.APIEndpoint, now, r.Guid, r.Version)
+	if err != nil {
+		tx.Rollback()
+		return duplicate(ra.DB, ra.Dialect, ra.Dialect.err(err), EntityResource, resourceNamed, r.Name)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			// Nothing was updated; find out why
+			err = versionMismatch(tx, ra.Dialect, "SELECT COUNT(*) FROM resources WHERE guid=? AND deleted IS NULL", EntityResource, r.Guid)
+		}
+		tx.Rollback()
+		return err
+	}
+
+	r.Created = before.Created
+	if err := record(tx, ra.Dialect, ra.Actor, now, OpUpdate, EntityResource, r.Guid, r.Guid, resourceValues(before), resourceValues(r)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Moves a resource to the trash, provided version is still its stored
+//   version. If verbs or type associations still refer to it, nothing is
+//   deleted and a *DependentsError listing them is returned. Returns
+//   ErrVersionMismatch if the resource has changed since that version and
+//   a *NotFoundError if it does not exist or is already in the trash.
+func (ra *ResourceAccessor) Delete(guid string, version int) error {
+	_, err := ra.delete(guid, version, false)
 	return err
 }
 
-// Delete a resource.
-func (ra *ResourceAccessor) Delete(guid string) error {
-	stmt, err := ra.DB.Prepare("DELETE FROM resources WHERE guid=?")
+// Moves a resource to the trash together with its verbs, provided version
+//   is still its stored version. Type associations it is part of, as the
+//   resource or as the type, are kept but hidden until the resource is
+//   restored or purged. Returns the dependents.
+func (ra *ResourceAccessor) DeleteCascade(guid string, version int) (Dependents, error) {
+	return ra.delete(guid, version, true)
+}
+
+// Trashes a resource in a transaction, then its verbs if cascade is set,
+//   or refuses if it is not.
+func (ra *ResourceAccessor) delete(guid string, version int, cascade bool) (Dependents, error) {
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return Dependents{}, err
+	}
+
+	resource, err := getResource(tx, ra.Dialect, guid)
+	if err != nil {
+		tx.Rollback()
+		return Dependents{}, err
+	}
+
+	deps, err := getDependents(tx, ra.Dialect, guid)
+	if err != nil {
+		tx.Rollback()
+		return deps, err
+	}
+	if !deps.empty() && !cascade {
+		tx.Rollback()
+		return deps, &DependentsError{deps}
+	}
+
+	// The verbs share the resource's deletion time, which is how a restore
+	//   finds them.
+	deleted := Now()
+	stmt, err := ra.Dialect.prepare(tx, "UPDATE resources SET deleted=?, modified=? WHERE guid=? AND version=? AND deleted IS NULL")
+	if err != nil {
+		tx.Rollback()
+		return deps, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(deleted, deleted, guid, version)
+	if err != nil {
+		tx.Rollback()
+		return deps, ra.Dialect.err(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			// Nothing was deleted; find out why
+			err = versionMismatch(tx, ra.Dialect, "SELECT COUNT(*) FROM resources WHERE guid=? AND deleted IS NULL", EntityResource, guid)
+		}
+		tx.Rollback()
+		return deps, err
+	}
+	if len(deps.Verbs) > 0 {
+		if err := execTx(tx, ra.Dialect, "UPDATE resourceVerbs SET deleted=?, modified=? WHERE resourceGUID=? AND deleted IS NULL", deleted, deleted, guid); err != nil {
+			tx.Rollback()
+			return deps, err
+		}
+	}
+
+	for _, verb := range deps.Verbs {
+		if err := record(tx, ra.Dialect, ra.Actor, deleted, OpDelete, EntityVerb, verb.Guid, guid, verb, nil); err != nil {
+			tx.Rollback()
+			return deps, err
+		}
+	}
+	if err := record(tx, ra.Dialect, ra.Actor, deleted, OpDelete, EntityResource, guid, guid, resourceValues(resource), nil); err != nil {
+		tx.Rollback()
+		return deps, err
+	}
+	return deps, tx.Commit()
+}
+
+// Takes a resource out of the trash, along with the verbs that were trashed
+//   with it. Returns a *NotFoundError if the resource is not in the trash
+//   and a *DuplicateError if a live resource has taken its name.
+func (ra *ResourceAccessor) Restore(guid string) error {
+	tx, err := ra.DB.Begin()
 	if err != nil {
 		return err
 	}
 
-	_, err = stmt.Exec(guid)
-	return err
+	// The verbs trashed with the resource
+	verbs := make([]ResourceVerb, 0)
+	err = queryEach(tx, ra.Dialect, "SELECT "+verbColumns+" FROM resourceVerbs WHERE resourceGUID=? AND deleted=(SELECT deleted FROM resources WHERE guid=?) ORDER BY guid", func(rows *sql.Rows) error {
+		var v ResourceVerb
+		if err := scanVerb(rows, &v); err != nil {
+			return err
+		}
+		verbs = append(verbs, v)
+		return nil
+	}, guid, guid)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	restored := Now()
+	if err := execTx(tx, ra.Dialect, "UPDATE resourceVerbs SET deleted=NULL, modified=? WHERE resourceGUID=? AND deleted=(SELECT deleted FROM resources WHERE guid=?)", restored, guid, guid); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := ra.Dialect.prepare(tx, "UPDATE resources SET deleted=NULL, modified=? WHERE guid=? AND deleted IS NOT NULL")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.Exec(restored, guid)
+	if err != nil {
+		tx.Rollback()
+		return duplicate(ra.DB, ra.Dialect, ra.Dialect.err(err), EntityResource, resourceNamedAsTrash, guid)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		tx.Rollback()
+		if err == nil {
+			err = &NotFoundError{EntityResource, guid}
+		}
+		return err
+	}
+
+	resource, err := getResource(tx, ra.Dialect, guid)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, verb := range verbs {
+		if err := record(tx, ra.Dialect, ra.Actor, restored, OpRestore, EntityVerb, verb.Guid, guid, nil, verb); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := record(tx, ra.Dialect, ra.Actor, restored, OpRestore, EntityResource, guid, guid, nil, resourceValues(resource)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Gets the verbs outside the trash and the type associations that refer to
+//   a resource.
+func getDependents(tx *sql.Tx, d Dialect, guid string) (Dependents, error) {
+	deps := Dependents{make([]ResourceVerb, 0), make([]ResourceType, 0)}
+
+	stmt, err := d.prepare(tx, "SELECT "+verbColumns+" FROM resourceVerbs WHERE resourceGUID=? AND deleted IS NULL ORDER BY guid")
+	if err != nil {
+		return deps, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(guid)
+	if err != nil {
+		return deps, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v ResourceVerb
+		if err := scanVerb(rows, &v); err != nil {
+			return deps, err
+		}
+		deps.Verbs = append(deps.Verbs, v)
+	}
+	if err := rows.Err(); err != nil {
+		return deps, err
+	}
+
+	typeStmt, err := d.prepare(tx, "SELECT guid, resourceGUID, type FROM resourceTypes WHERE resourceGUID=? OR type=? ORDER BY guid")
+	if err != nil {
+		return deps, err
+	}
+	defer typeStmt.Close()
+
+	typeRows, err := typeStmt.Query(guid, guid)
+	if err != nil {
+		return deps, err
+	}
+	defer typeRows.Close()
+	for typeRows.Next() {
+		var rt ResourceType
+		if err := typeRows.Scan(&rt.Guid, &rt.ResourceGUID, &rt.Type); err != nil {
+			return deps, err
+		}
+		deps.Types = append(deps.Types, rt)
+	}
+	return deps, typeRows.Err()
+}
+
+// Runs a statement in a transaction.
+func execTx(tx *sql.Tx, d Dialect, query string, args ...interface{}) error {
+	stmt, err := d.prepare(tx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(args...)
+	return d.err(err)
+}
+
+// Explains why a versioned update changed nothing. count is a query
+//   counting the live rows of the entity with the given guid: a
+//   *NotFoundError if there are none, otherwise ErrVersionMismatch.
+func versionMismatch(db preparer, d Dialect, count, entity, guid string) error {
+	stmt, err := d.prepare(db, count)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	var n int
+	if err := stmt.QueryRow(guid).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return &NotFoundError{entity, guid}
+	}
+	return ErrVersionMismatch
 }
 
 // Helper function
@@ -105,7 +526,7 @@ func (r *Resource) Equals(other Resource) bool {
 		}
 	}
 
-	if r.Guid != other.Guid || r.Name != other.Name || r.Description != other.Description || r.APIEndpoint != other.APIEndpoint {
+	if r.Guid != other.Guid || r.Name != other.Name || r.Description != other.Description || r.APIEndpoint != other.APIEndpoint || !r.Created.Equal(other.Created) || !r.Modified.Equal(other.Modified) || r.Version != other.Version {
 		return false
 	}
 	return true
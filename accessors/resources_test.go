@@ -15,12 +15,12 @@ func TestGetResource(t *testing.T) {
 
 	ra := NewResourceAccessor(db)
 
-	expected := Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "tmt.byu.edu/resources", nil}
-	columns := []string{"guid", "name", "description", "apiEndpoint"}
+	expected := Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "tmt.byu.edu/resources", testTime, testTime, 1, nil}
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resources WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.)").
 		WithArgs("11111111-2222-3333-4444-555555555555").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
 	resource, err := ra.Get("11111111-2222-3333-4444-555555555555")
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resource %v", err)
@@ -45,14 +45,14 @@ func TestGetAllResources(t *testing.T) {
 	ra := NewResourceAccessor(db)
 
 	expected := []Resource{
-		Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "tmt.byu.edu/resources", nil},
-		Resource{"00000000-9999-8888-7777-666666666666", "testing", "for testing purposes", "tmt.byu.edu/resources", nil},
+		Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "tmt.byu.edu/resources", testTime, testTime, 1, nil},
+		Resource{"00000000-9999-8888-7777-666666666666", "testing", "for testing purposes", "tmt.byu.edu/resources", testTime, testTime, 1, nil},
 	}
-	columns := []string{"guid", "name", "description", "apiEndpoint"}
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resources").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources").
 		WithArgs().
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources\n00000000-9999-8888-7777-666666666666,testing,for testing purposes.tmt.byu.edu/resources"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1\n00000000-9999-8888-7777-666666666666,testing,for testing purposes,tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
 	resources, err := ra.GetAll()
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resource %v", err)
@@ -73,6 +73,7 @@ func TestInsertResource(t *testing.T) {
 	NewGuid = func() string {
 		return "123def"
 	}
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred creating the mock database")
@@ -81,15 +82,23 @@ func TestInsertResource(t *testing.T) {
 
 	ra := NewResourceAccessor(db)
 
+	sqlmock.ExpectBegin()
 	sqlmock.ExpectPrepare()
 	sqlmock.ExpectExec("INSERT INTO resources .+ VALUES .+").
-		WithArgs("123def", "test", "This is a test", "tmt.byu.edu/resources").
+		WithArgs("123def", "test", "This is a test", "tmt.byu.edu/resources", testTime, testTime).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
-	err = ra.Insert(Resource{"123def", "test", "This is a test", "tmt.byu.edu/resources", nil})
+	guid, err := ra.Insert(Resource{"123def", "test", "This is a test", "tmt.byu.edu/resources", testTime, testTime, 1, nil})
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resource:\n %s", err.Error())
 	}
+	if guid != "123def" {
+		t.Errorf("Expected guid 123def but got %v", guid)
+	}
 
 	if err := ra.DB.Close(); err != nil {
 		t.Errorf("An error occurred: %v", err)
@@ -97,6 +106,7 @@ func TestInsertResource(t *testing.T) {
 }
 
 func TestUpdateResource(t *testing.T) {
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred creating the mock database")
@@ -105,12 +115,22 @@ func TestUpdateResource(t *testing.T) {
 
 	ra := NewResourceAccessor(db)
 
+	sqlmock.ExpectBegin()
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectExec("UPDATE resources SET name=(.), description=(.), apiEndpoint=(.) WHERE guid=(.)").
-		WithArgs("test", "This is a test", "tmt.byu.edu/resources", "11111111-2222-3333-4444-555555555555").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET name=(.), description=(.), apiEndpoint=(.), version=version\\+1, modified=(.) WHERE guid=(.) AND version=(.)").
+		WithArgs("test", "This is a test", "tmt.byu.edu/resources", testTime, "11111111-2222-3333-4444-555555555555", 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
-	err = ra.Update(Resource{"11111111-2222-3333-4444-555555555555", "test", "This is a test", "tmt.byu.edu/resources", nil})
+	err = ra.Update(Resource{"11111111-2222-3333-4444-555555555555", "test", "This is a test", "tmt.byu.edu/resources", testTime, testTime, 1, nil})
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resource:\n %s", err.Error())
 	}
@@ -121,6 +141,7 @@ func TestUpdateResource(t *testing.T) {
 }
 
 func TestDeleteResource(t *testing.T) {
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred creating the mock database")
@@ -129,12 +150,30 @@ func TestDeleteResource(t *testing.T) {
 
 	ra := NewResourceAccessor(db)
 
+	sqlmock.ExpectBegin()
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectExec("DELETE FROM resources WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.) AND deleted IS NULL").
 		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID=(.) AND deleted IS NULL ORDER BY guid").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceTypes WHERE resourceGUID=(.) OR type=(.) ORDER BY guid").
+		WithArgs("11111111-2222-3333-4444-555555555555", "11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "type"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET deleted=(.), modified=(.) WHERE guid=(.) AND version=(.) AND deleted IS NULL").
+		WithArgs(testTime, testTime, "11111111-2222-3333-4444-555555555555", 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
-	err = ra.Delete("11111111-2222-3333-4444-555555555555")
+	err = ra.Delete("11111111-2222-3333-4444-555555555555", 1)
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resource:\n %s", err.Error())
 	}
@@ -0,0 +1,44 @@
+package accessors
+
+import (
+	"database/sql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLite is the dialect of the embedded database used for single-binary
+//   deployments and local development.
+var SQLite = Dialect{
+	Name:       "sqlite3",
+	migrations: "sqlite",
+	translate:  sqliteError,
+	hasTable:   "SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?",
+}
+
+func sqliteError(err error) error {
+	e, ok := err.(sqlite3.Error)
+	if !ok {
+		return err
+	}
+	switch e.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		return ErrDuplicate
+	case sqlite3.ErrConstraintForeignKey:
+		return ErrForeignKey
+	}
+	return err
+}
+
+// Opens the SQLite database at path, creating the file if it does not exist
+//   yet. The path ":memory:" gives a throwaway database.
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open(SQLite.Name, path+"?_foreign_keys=1")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite only allows one writer at a time, and every connection to
+	//   ":memory:" would otherwise get its own empty database.
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
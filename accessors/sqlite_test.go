@@ -0,0 +1,185 @@
+package accessors
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Returns a store backed by a fresh, migrated in-memory SQLite database.
+func newSQLiteStore(t testing.TB) *SQLStore {
+	db, err := OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred opening the SQLite database: %v", err)
+	}
+	if _, err := MigrateUp(db, SQLite); err != nil {
+		t.Fatalf("An unexpected error occurred migrating the SQLite database: %v", err)
+	}
+	return NewSQLStore(db, SQLite)
+}
+
+func TestSQLiteResources(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testResources(t, s)
+}
+
+func TestSQLiteListResources(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testListResources(t, s)
+}
+
+func TestSQLiteResourceVerbs(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testResourceVerbs(t, s)
+}
+
+func TestSQLiteResourceTypes(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testResourceTypes(t, s)
+}
+
+func TestSQLiteDeleteResources(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testDeleteResources(t, s)
+}
+
+func TestSQLiteTrash(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testTrash(t, s)
+}
+
+func TestSQLiteAudit(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testAudit(t, s)
+}
+
+// A change that cannot be recorded in the audit log is rolled back.
+func TestSQLiteUnauditedChange(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	if _, err := s.DB.Exec("DROP TABLE auditLog"); err != nil {
+		t.Fatalf("An unexpected error occurred dropping the audit log: %v", err)
+	}
+
+	if _, err := s.As("carol").Resources().Insert(Resource{Name: "board", Description: "a board", APIEndpoint: "tmt.byu.edu/boards"}); err == nil {
+		t.Errorf("Expected the insert to fail")
+	}
+	resources, err := s.Resources().GetAll()
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting the resources: %v", err)
+	}
+	if len(resources) != 0 {
+		t.Errorf("Expected no resources but got %v", resources)
+	}
+}
+
+func TestSQLiteChanges(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testChanges(t, s)
+}
+
+func TestSQLiteCatalog(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testCatalog(t, s)
+}
+
+func TestSQLiteInheritance(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testInheritance(t, s)
+}
+
+func TestSQLiteGrants(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testGrants(t, s)
+}
+
+func TestSQLiteAuthorize(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testAuthorize(t, s)
+}
+
+func TestSQLiteConstraints(t *testing.T) {
+	s := newSQLiteStore(t)
+	defer s.DB.Close()
+	testConstraints(t, s)
+}
+
+// Returns a migrated SQLite store holding n resources with two verbs each,
+//   and the resources' guids.
+func newSQLiteBenchmarkStore(b *testing.B, n int) (*SQLStore, []string) {
+	db, err := OpenSQLite(":memory:")
+	if err != nil {
+		b.Fatalf("An unexpected error occurred opening the SQLite database: %v", err)
+	}
+	if _, err := MigrateUp(db, SQLite); err != nil {
+		b.Fatalf("An unexpected error occurred migrating the SQLite database: %v", err)
+	}
+	s := NewSQLStore(db, SQLite)
+
+	sequentialGuids()
+	guids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		guid, err := s.Resources().Insert(Resource{Name: fmt.Sprintf("resource %d", i)})
+		if err != nil {
+			b.Fatalf("An unexpected error occurred inserting a resource: %v", err)
+		}
+		s.Verbs().Add(ResourceVerb{ResourceGUID: guid, Verb: "view", Description: "can view"})
+		s.Verbs().Add(ResourceVerb{ResourceGUID: guid, Verb: "edit", Description: "can edit"})
+		guids = append(guids, guid)
+	}
+	return s, guids
+}
+
+// Loads the verbs of 1000 resources one resource at a time, as
+//   GET /resources used to.
+func BenchmarkSQLiteVerbsByResource(b *testing.B) {
+	s, guids := newSQLiteBenchmarkStore(b, 1000)
+	defer s.DB.Close()
+	va := s.Verbs()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, guid := range guids {
+			if _, err := va.GetByResource(guid); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// Loads the verbs of 1000 resources in batches.
+func BenchmarkSQLiteVerbsByResources(b *testing.B) {
+	s, guids := newSQLiteBenchmarkStore(b, 1000)
+	defer s.DB.Close()
+	va := s.Verbs()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := va.GetByResources(guids); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSQLiteAuthorize(b *testing.B) {
+	s := newSQLiteStore(b)
+	defer s.DB.Close()
+	benchmarkAuthorize(b, s)
+}
+
+func BenchmarkSQLiteAuthorizeBatch(b *testing.B) {
+	s := newSQLiteStore(b)
+	defer s.DB.Close()
+	benchmarkAuthorizeBatch(b, s)
+}
@@ -0,0 +1,142 @@
+package accessors
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Store gives access to everything the resources microservice persists.
+//   The apis package depends only on this interface so that the backing
+//   storage can be swapped out (MySQL, PostgreSQL, SQLite, in-memory).
+//   Every change made through a store is recorded in the audit log in the
+//   same transaction, under the actor given to As.
+type Store interface {
+	Resources() ResourceStore
+	Verbs() ResourceVerbStore
+	Types() ResourceTypeStore
+	Trash() TrashStore
+	Audit() AuditStore
+	Changes() ChangeStore
+	Catalog() CatalogStore
+	Grants() GrantStore
+	As(actor string) Store
+}
+
+// ResourceStore persists resources.
+type ResourceStore interface {
+	Get(guid string) (Resource, error)
+	GetAll() ([]Resource, error)
+	LastModified() (time.Time, error)
+	List(q ResourceQuery) (ResourcePage, error)
+	Insert(r Resource) (string, error)
+	Update(r Resource) error
+	Delete(guid string, version int) error
+	DeleteCascade(guid string, version int) (Dependents, error)
+	Restore(guid string) error
+}
+
+// ResourceVerbStore persists the verbs associated to resources.
+type ResourceVerbStore interface {
+	Get(guid string) (ResourceVerb, error)
+	GetByResource(resource string) ([]ResourceVerb, error)
+	GetByResources(resources []string) (map[string][]ResourceVerb, error)
+	Add(r ResourceVerb) (string, error)
+	Update(guid, description string, version int) error
+	Remove(guid string, version int) error
+	Restore(guid string) error
+	GetEffective(resource string) (Inheritance, error)
+	Suppress(resource, verb string) (string, error)
+	Unsuppress(resource, verb string) error
+}
+
+// ResourceTypeStore persists the associations between a resource and its type.
+type ResourceTypeStore interface {
+	GetType(guid string) (Resource, error)
+	GetTypes(resource string) ([]ResourceTypeDetail, error)
+	GetResourcesOfType(typeGUID string) ([]Resource, error)
+	GetTypesInUse() ([]TypeInUse, error)
+	Insert(r, t string) (string, error)
+}
+
+// TrashStore lists and purges deleted resources and verbs.
+type TrashStore interface {
+	Get() (Trash, error)
+	Purge(before time.Time) (int, error)
+}
+
+// AuditStore records and queries the audit log.
+type AuditStore interface {
+	Record(e AuditEntry) (string, error)
+	Get(q AuditQuery) ([]AuditEntry, error)
+}
+
+// ChangeStore reports what changed since a point in time, for clients
+//   keeping a copy of the resources in sync.
+type ChangeStore interface {
+	Since(since time.Time) (Changes, error)
+}
+
+// CatalogStore exports and imports all resources at once.
+type CatalogStore interface {
+	Export() (Catalog, error)
+	Import(c Catalog, mode string) (ImportResult, error)
+}
+
+// GrantStore persists who may perform which verbs on which resources.
+type GrantStore interface {
+	Get(guid string) (Grant, error)
+	List(q GrantQuery) ([]Grant, error)
+	Add(g Grant) (string, error)
+	Update(g Grant) error
+	Remove(guid string, version int) error
+	Authorize(reqs []AccessRequest) ([]Decision, error)
+}
+
+// SQLStore is a Store backed by a database/sql connection.
+type SQLStore struct {
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+	Actor   string  // Who the changes are recorded as made by
+}
+
+// Returns a new store backed by the given database connection.
+func NewSQLStore(db *sql.DB, dialect Dialect) *SQLStore {
+	return &SQLStore{db, dialect, ""}
+}
+
+// Returns the store recording its changes as made by actor.
+func (s *SQLStore) As(actor string) Store {
+	return &SQLStore{s.DB, s.Dialect, actor}
+}
+
+func (s *SQLStore) Resources() ResourceStore {
+	return &ResourceAccessor{s.DB, s.Dialect, s.Actor}
+}
+
+func (s *SQLStore) Verbs() ResourceVerbStore {
+	return &ResourceVerbAccessor{s.DB, s.Dialect, s.Actor}
+}
+
+func (s *SQLStore) Types() ResourceTypeStore {
+	return &ResourceTypeAccessor{s.DB, s.Dialect, s.Actor}
+}
+
+func (s *SQLStore) Trash() TrashStore {
+	return &TrashAccessor{s.DB, s.Dialect}
+}
+
+func (s *SQLStore) Audit() AuditStore {
+	return &AuditAccessor{s.DB, s.Dialect}
+}
+
+func (s *SQLStore) Changes() ChangeStore {
+	return &ChangeAccessor{s.DB, s.Dialect}
+}
+
+func (s *SQLStore) Catalog() CatalogStore {
+	return &CatalogAccessor{s.DB, s.Dialect, s.Actor}
+}
+
+func (s *SQLStore) Grants() GrantStore {
+	return &GrantAccessor{s.DB, s.Dialect, s.Actor}
+}
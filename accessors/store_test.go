@@ -0,0 +1,1406 @@
+package accessors
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Behavioural tests shared by every Store implementation. Each backend's
+//   test file calls these with a freshly created, empty store.
+
+// Creation time of every resource made by the tests, unless a test
+//   installs its own clock.
+var testTime = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Stops the clock at testTime.
+func stopClock() {
+	Now = func() time.Time {
+		return testTime
+	}
+}
+
+// Replaces NewGuid and NewAuditGuid with deterministic, unique generators
+//   counting from 1 each and stops the clock at testTime.
+func sequentialGuids() {
+	stopClock()
+
+	var mu sync.Mutex
+	n, audits := 0, 0
+	NewGuid = func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	NewAuditGuid = func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		audits++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", audits)
+	}
+}
+
+// Exercises a ResourceStore against a fresh Store.
+func testResources(t *testing.T, s Store) {
+	sequentialGuids()
+	ra := s.Resources()
+
+	guid, err := ra.Insert(Resource{Name: "whiteboard", Description: "a whiteboard", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred inserting a resource: %v", err)
+	}
+	if guid != "00000000-0000-0000-0000-000000000001" {
+		t.Errorf("Expected guid 00000000-0000-0000-0000-000000000001 but got %v", guid)
+	}
+	if _, err := ra.Insert(Resource{Name: "room", Description: "a room", APIEndpoint: "tmt.byu.edu/rooms"}); err != nil {
+		t.Errorf("An unexpected error occurred inserting a resource: %v", err)
+	}
+
+	expected := []Resource{
+		Resource{"00000000-0000-0000-0000-000000000001", "whiteboard", "a whiteboard", "tmt.byu.edu/whiteboards", testTime, testTime, 1, nil},
+		Resource{"00000000-0000-0000-0000-000000000002", "room", "a room", "tmt.byu.edu/rooms", testTime, testTime, 1, nil},
+	}
+	resources, err := ra.GetAll()
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting resources: %v", err)
+	}
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %v but got %v", expected, resources)
+	}
+	for i := 0; i < len(resources); i++ {
+		if !expected[i].Equals(resources[i]) {
+			t.Errorf("Expected %v but got %v", expected, resources)
+		}
+	}
+
+	updated := Resource{"00000000-0000-0000-0000-000000000001", "board", "a board", "tmt.byu.edu/boards", testTime, testTime, 1, nil}
+	if err := ra.Update(updated); err != nil {
+		t.Errorf("An unexpected error occurred updating a resource: %v", err)
+	}
+	resource, err := ra.Get(updated.Guid)
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting a resource: %v", err)
+	}
+	updated.Version = 2
+	if !updated.Equals(resource) {
+		t.Errorf("Expected %v but got %v", updated, resource)
+	}
+
+	// Updates from an old version are refused
+	stale := updated
+	stale.Name, stale.Version = "chalkboard", 1
+	if err := ra.Update(stale); err != ErrVersionMismatch {
+		t.Errorf("Expected %v but got %v", ErrVersionMismatch, err)
+	}
+	if resource, _ := ra.Get(updated.Guid); resource.Name != "board" {
+		t.Errorf("Expected board but got %v", resource.Name)
+	}
+	err = ra.Update(Resource{Guid: "99999999-9999-9999-9999-999999999999", Version: 1})
+	missing := NotFoundError{EntityResource, "99999999-9999-9999-9999-999999999999"}
+	if notFound, ok := err.(*NotFoundError); !ok || *notFound != missing || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected %v but got %v", &missing, err)
+	}
+
+	// Deletes from an old version are refused
+	if err := ra.Delete(updated.Guid, 1); err != ErrVersionMismatch {
+		t.Errorf("Expected %v but got %v", ErrVersionMismatch, err)
+	}
+	if _, err := ra.DeleteCascade(updated.Guid, 1); err != ErrVersionMismatch {
+		t.Errorf("Expected %v but got %v", ErrVersionMismatch, err)
+	}
+	if err := ra.Delete(updated.Guid, 2); err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+	if _, err := ra.Get(updated.Guid); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+}
+
+// Exercises a ResourceVerbStore against a fresh Store.
+func testResourceVerbs(t *testing.T, s Store) {
+	sequentialGuids()
+	s.Resources().Insert(Resource{Name: "whiteboard", Description: "a whiteboard", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	s.Resources().Insert(Resource{Name: "room", Description: "a room", APIEndpoint: "tmt.byu.edu/rooms"})
+	va := s.Verbs()
+
+	guid, err := va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "edit", Description: "can edit"})
+	if err != nil || guid != "00000000-0000-0000-0000-000000000003" {
+		t.Errorf("Expected guid 00000000-0000-0000-0000-000000000003 but got %v, %v", guid, err)
+	}
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "view", Description: "can view"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "view", Description: "can view"})
+
+	expected := []ResourceVerb{
+		ResourceVerb{"00000000-0000-0000-0000-000000000003", "00000000-0000-0000-0000-000000000001", "edit", "can edit", 1},
+		ResourceVerb{"00000000-0000-0000-0000-000000000005", "00000000-0000-0000-0000-000000000001", "view", "can view", 1},
+	}
+	verbs, err := va.GetByResource("00000000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting verbs: %v", err)
+	}
+	if len(verbs) != len(expected) {
+		t.Fatalf("Expected %v but got %v", expected, verbs)
+	}
+	for i := 0; i < len(verbs); i++ {
+		if verbs[i] != expected[i] {
+			t.Errorf("Expected %v but got %v", expected, verbs)
+		}
+	}
+
+	// Batch loading returns the same verbs, and an entry for every resource
+	batch, err := va.GetByResources([]string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002", "99999999-9999-9999-9999-999999999999"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting verbs: %v", err)
+	}
+	if fmt.Sprint(batch["00000000-0000-0000-0000-000000000001"]) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v but got %v", expected, batch["00000000-0000-0000-0000-000000000001"])
+	}
+	if len(batch["00000000-0000-0000-0000-000000000002"]) != 1 {
+		t.Errorf("Expected 1 verb but got %v", batch["00000000-0000-0000-0000-000000000002"])
+	}
+	if verbs, ok := batch["99999999-9999-9999-9999-999999999999"]; !ok || verbs == nil || len(verbs) != 0 {
+		t.Errorf("Expected no verbs but got %v", verbs)
+	}
+
+	if err := va.Update("00000000-0000-0000-0000-000000000003", "may edit", 1); err != nil {
+		t.Errorf("An unexpected error occurred updating a verb: %v", err)
+	}
+	verb, err := va.Get("00000000-0000-0000-0000-000000000003")
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting a verb: %v", err)
+	}
+	if verb.Description != "may edit" || verb.Version != 2 {
+		t.Errorf("Expected 'may edit' at version 2 but got %v", verb)
+	}
+	if err := va.Update("00000000-0000-0000-0000-000000000003", "can edit", 1); err != ErrVersionMismatch {
+		t.Errorf("Expected %v but got %v", ErrVersionMismatch, err)
+	}
+	if err := va.Update("99999999-9999-9999-9999-999999999999", "can edit", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+
+	if err := va.Remove("00000000-0000-0000-0000-000000000003", 1); err != ErrVersionMismatch {
+		t.Errorf("Expected %v but got %v", ErrVersionMismatch, err)
+	}
+	if err := va.Remove("00000000-0000-0000-0000-000000000003", 2); err != nil {
+		t.Errorf("An unexpected error occurred removing a verb: %v", err)
+	}
+	if _, err := va.Get("00000000-0000-0000-0000-000000000003"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+}
+
+// Exercises a ResourceTypeStore against a fresh Store.
+func testResourceTypes(t *testing.T, s Store) {
+	sequentialGuids()
+
+	s.Resources().Insert(Resource{Name: "whiteboard", Description: "whiteboard type", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	s.Resources().Insert(Resource{Name: "board 1", Description: "the first board", APIEndpoint: "tmt.byu.edu/whiteboards/1"})
+	guid, err := s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Errorf("An unexpected error occurred inserting a type: %v", err)
+	}
+	if guid != "00000000-0000-0000-0000-000000000003" {
+		t.Errorf("Expected guid 00000000-0000-0000-0000-000000000003 but got %v", guid)
+	}
+
+	expected := Resource{"00000000-0000-0000-0000-000000000001", "whiteboard", "whiteboard type", "tmt.byu.edu/whiteboards", testTime, testTime, 1, nil}
+	resourceType, err := s.Types().GetType("00000000-0000-0000-0000-000000000002")
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting a type: %v", err)
+	}
+	if !expected.Equals(resourceType) {
+		t.Errorf("Expected %v but got %v", expected, resourceType)
+	}
+
+	if _, err := s.Types().GetType("00000000-0000-0000-0000-000000000001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+
+	// A resource can have several types, and a type many resources
+	s.Resources().Insert(Resource{Name: "board 2", Description: "the second board", APIEndpoint: "tmt.byu.edu/whiteboards/2"})
+	s.Resources().Insert(Resource{Name: "fixture", Description: "fixture type", APIEndpoint: "tmt.byu.edu/fixtures"})
+	s.Types().Insert("00000000-0000-0000-0000-000000000004", "00000000-0000-0000-0000-000000000001")
+	s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000005")
+
+	types, err := s.Types().GetTypes("00000000-0000-0000-0000-000000000002")
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting types: %v", err)
+	}
+	if len(types) != 2 || types[0].Guid != "00000000-0000-0000-0000-000000000003" || types[0].TypeResource.Name != "whiteboard" || types[1].Guid != "00000000-0000-0000-0000-000000000007" || types[1].Type != "00000000-0000-0000-0000-000000000005" || types[1].TypeResource.Name != "fixture" {
+		t.Errorf("Expected the whiteboard and fixture types but got %v", types)
+	}
+
+	resourceGuids := func(typeGUID string) string {
+		resources, err := s.Types().GetResourcesOfType(typeGUID)
+		if err != nil {
+			t.Errorf("An unexpected error occurred getting resources of a type: %v", err)
+		}
+		guids := make([]string, 0)
+		for _, r := range resources {
+			guids = append(guids, r.Guid[len(r.Guid)-1:])
+		}
+		return fmt.Sprint(guids)
+	}
+	typesInUse := func() string {
+		types, err := s.Types().GetTypesInUse()
+		if err != nil {
+			t.Errorf("An unexpected error occurred getting the types in use: %v", err)
+		}
+		counts := make([]string, 0)
+		for _, t := range types {
+			counts = append(counts, fmt.Sprintf("%s:%d", t.Name, t.Count))
+		}
+		return fmt.Sprint(counts)
+	}
+	if guids := resourceGuids("00000000-0000-0000-0000-000000000001"); guids != "[2 4]" {
+		t.Errorf("Expected [2 4] but got %v", guids)
+	}
+	if guids := resourceGuids("00000000-0000-0000-0000-000000000002"); guids != "[]" {
+		t.Errorf("Expected [] but got %v", guids)
+	}
+	if counts := typesInUse(); counts != "[fixture:1 whiteboard:2]" {
+		t.Errorf("Expected [fixture:1 whiteboard:2] but got %v", counts)
+	}
+
+	// Resources in the trash are left out
+	if _, err := s.Resources().DeleteCascade("00000000-0000-0000-0000-000000000004", 2); err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+	if guids := resourceGuids("00000000-0000-0000-0000-000000000001"); guids != "[2]" {
+		t.Errorf("Expected [2] but got %v", guids)
+	}
+	if counts := typesInUse(); counts != "[fixture:1 whiteboard:1]" {
+		t.Errorf("Expected [fixture:1 whiteboard:1] but got %v", counts)
+	}
+	if _, err := s.Resources().DeleteCascade("00000000-0000-0000-0000-000000000005", 1); err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+	if types, _ := s.Types().GetTypes("00000000-0000-0000-0000-000000000002"); len(types) != 1 {
+		t.Errorf("Expected only the whiteboard type but got %v", types)
+	}
+	if guids := resourceGuids("00000000-0000-0000-0000-000000000005"); guids != "[]" {
+		t.Errorf("Expected [] but got %v", guids)
+	}
+}
+
+// Follows a listing's cursors to the end and returns the guids of every
+//   page.
+func listPages(t *testing.T, ra ResourceStore, q ResourceQuery) [][]string {
+	pages := make([][]string, 0)
+	for {
+		page, err := ra.List(q)
+		if err != nil {
+			t.Errorf("An unexpected error occurred listing resources: %v", err)
+			return pages
+		}
+		guids := make([]string, 0)
+		for _, r := range page.Resources {
+			guids = append(guids, r.Guid)
+		}
+		pages = append(pages, guids)
+		if page.Next == "" || len(pages) > 10 {
+			return pages
+		}
+		q.Cursor = page.Next
+	}
+}
+
+// Exercises ResourceStore.List against a fresh Store.
+func testListResources(t *testing.T, s Store) {
+	sequentialGuids()
+	ra := s.Resources()
+
+	// Each resource is created a minute after the previous one
+	tick := 0
+	Now = func() time.Time {
+		tick++
+		return testTime.Add(time.Duration(tick) * time.Minute)
+	}
+	for _, r := range []Resource{
+		Resource{Name: "c", APIEndpoint: "tmt.byu.edu/boards/1"},
+		Resource{Name: "a", APIEndpoint: "tmt.byu.edu/rooms/1"},
+		Resource{Name: "b", APIEndpoint: "tmt.byu.edu/boards/2"},
+		Resource{Name: "ab", APIEndpoint: "tmt.byu.edu/boards_old"},
+		Resource{Name: "e", APIEndpoint: "tmt.byu.edu/rooms/2"},
+	} {
+		if _, err := ra.Insert(r); err != nil {
+			t.Errorf("An unexpected error occurred inserting a resource: %v", err)
+		}
+	}
+
+	guid := func(n int) string {
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	tests := []struct {
+		query    ResourceQuery
+		expected [][]string
+	}{
+		{ResourceQuery{Limit: 2}, [][]string{{guid(1), guid(2)}, {guid(3), guid(4)}, {guid(5)}}},
+		{ResourceQuery{Limit: 5}, [][]string{{guid(1), guid(2), guid(3), guid(4), guid(5)}}},
+		{ResourceQuery{Sort: SortByCreatedDesc, Limit: 3}, [][]string{{guid(5), guid(4), guid(3)}, {guid(2), guid(1)}}},
+		{ResourceQuery{Sort: SortByName, Limit: 2}, [][]string{{guid(2), guid(4)}, {guid(3), guid(1)}, {guid(5)}}},
+		{ResourceQuery{Sort: SortByNameDesc, Limit: 4}, [][]string{{guid(5), guid(1), guid(3), guid(4)}, {guid(2)}}},
+		{ResourceQuery{Name: "ab", Limit: 1}, [][]string{{guid(4)}}},
+		{ResourceQuery{APIEndpointPrefix: "tmt.byu.edu/boards/", Limit: 10}, [][]string{{guid(1), guid(3)}}},
+		{ResourceQuery{APIEndpointPrefix: "tmt.byu.edu/boards_", Limit: 10}, [][]string{{guid(4)}}},
+		{ResourceQuery{Name: "z", Limit: 10}, [][]string{{}}},
+	}
+	for _, test := range tests {
+		pages := listPages(t, ra, test.query)
+		if fmt.Sprint(pages) != fmt.Sprint(test.expected) {
+			t.Errorf("Expected %v but got %v for %+v", test.expected, pages, test.query)
+		}
+	}
+
+	// The total counts every match, not just the page
+	page, err := ra.List(ResourceQuery{APIEndpointPrefix: "tmt.byu.edu/rooms", Limit: 1})
+	if err != nil {
+		t.Errorf("An unexpected error occurred listing resources: %v", err)
+	}
+	if page.Total != 2 || len(page.Resources) != 1 {
+		t.Errorf("Expected 1 of 2 resources but got %d of %d", len(page.Resources), page.Total)
+	}
+	if !page.Resources[0].Created.Equal(testTime.Add(2 * time.Minute)) {
+		t.Errorf("Expected %v but got %v", testTime.Add(2*time.Minute), page.Resources[0].Created)
+	}
+
+	// Cursors only continue the sort they were issued for
+	if _, err := ra.List(ResourceQuery{Sort: SortByName, Limit: 1, Cursor: page.Next}); err != ErrInvalidCursor {
+		t.Errorf("Expected %v but got %v", ErrInvalidCursor, err)
+	}
+	if _, err := ra.List(ResourceQuery{Limit: 1, Cursor: "not a cursor"}); err != ErrInvalidCursor {
+		t.Errorf("Expected %v but got %v", ErrInvalidCursor, err)
+	}
+	if _, err := ra.List(ResourceQuery{Sort: "size", Limit: 1}); err != ErrInvalidSort {
+		t.Errorf("Expected %v but got %v", ErrInvalidSort, err)
+	}
+}
+
+// Checks that deleting a resource refuses while it has dependents, and that
+//   a cascading delete removes them in both directions.
+func testDeleteResources(t *testing.T, s Store) {
+	sequentialGuids()
+	ra := s.Resources()
+	ra.Insert(Resource{Name: "whiteboard", Description: "whiteboard type", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	ra.Insert(Resource{Name: "board 1", Description: "the first board", APIEndpoint: "tmt.byu.edu/whiteboards/1"})
+	s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase", Description: "can erase"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "draw", Description: "can draw"})
+
+	// Without cascade the dependents are listed and nothing is deleted
+	expected := Dependents{
+		[]ResourceVerb{
+			ResourceVerb{"00000000-0000-0000-0000-000000000004", "00000000-0000-0000-0000-000000000002", "erase", "can erase", 1},
+			ResourceVerb{"00000000-0000-0000-0000-000000000005", "00000000-0000-0000-0000-000000000002", "draw", "can draw", 1},
+		},
+		[]ResourceType{
+			ResourceType{"00000000-0000-0000-0000-000000000003", "00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001"},
+		},
+	}
+	var dependents *DependentsError
+	err := ra.Delete("00000000-0000-0000-0000-000000000002", 4)
+	if !errors.As(err, &dependents) {
+		t.Fatalf("Expected a *DependentsError but got %v", err)
+	}
+	if fmt.Sprint(dependents.Dependents) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v but got %v", expected, dependents.Dependents)
+	}
+	if _, err := ra.Get("00000000-0000-0000-0000-000000000002"); err != nil {
+		t.Errorf("Expected the resource to remain but got %v", err)
+	}
+
+	// Trashing the type hides its association but not the typed resource
+	deps, err := ra.DeleteCascade("00000000-0000-0000-0000-000000000001", 1)
+	if err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+	if fmt.Sprint(deps.Types) != fmt.Sprint(expected.Types) || len(deps.Verbs) != 0 {
+		t.Errorf("Expected %v but got %v", expected.Types, deps)
+	}
+	if _, err := s.Types().GetType("00000000-0000-0000-0000-000000000002"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if _, err := ra.Get("00000000-0000-0000-0000-000000000002"); err != nil {
+		t.Errorf("Expected the resource to remain but got %v", err)
+	}
+
+	// Deleting the resource removes its verbs
+	deps, err = ra.DeleteCascade("00000000-0000-0000-0000-000000000002", 4)
+	if err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+	if fmt.Sprint(deps.Verbs) != fmt.Sprint(expected.Verbs) {
+		t.Errorf("Expected %v but got %v", expected.Verbs, deps.Verbs)
+	}
+	if _, err := s.Verbs().Get("00000000-0000-0000-0000-000000000004"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if _, err := ra.Get("00000000-0000-0000-0000-000000000002"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+
+	// A resource without dependents deletes either way
+	ra.Insert(Resource{Name: "room", Description: "a room", APIEndpoint: "tmt.byu.edu/rooms"})
+	if err := ra.Delete("00000000-0000-0000-0000-000000000006", 1); err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+}
+
+// Checks that deleted resources and verbs go to the trash, can be restored
+//   and are eventually purged.
+func testTrash(t *testing.T, s Store) {
+	sequentialGuids()
+	tick := 0
+	Now = func() time.Time {
+		tick++
+		return testTime.Add(time.Duration(tick) * time.Minute)
+	}
+	ra := s.Resources()
+	va := s.Verbs()
+	ra.Insert(Resource{Name: "whiteboard", Description: "a whiteboard", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	ra.Insert(Resource{Name: "room", Description: "a room", APIEndpoint: "tmt.byu.edu/rooms"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "draw", Description: "can draw"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "view", Description: "can view"})
+
+	verbGuids := func(resource string) string {
+		verbs, err := va.GetByResource(resource)
+		if err != nil {
+			t.Errorf("An unexpected error occurred getting verbs: %v", err)
+		}
+		guids := make([]string, 0)
+		for _, v := range verbs {
+			guids = append(guids, v.Guid[len(v.Guid)-1:])
+		}
+		return fmt.Sprint(guids)
+	}
+
+	// Deleted rows disappear from normal reads
+	if err := va.Remove("00000000-0000-0000-0000-000000000003", 1); err != nil {
+		t.Errorf("An unexpected error occurred removing a verb: %v", err)
+	}
+	if _, err := va.Get("00000000-0000-0000-0000-000000000003"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if guids := verbGuids("00000000-0000-0000-0000-000000000001"); guids != "[4]" {
+		t.Errorf("Expected [4] but got %v", guids)
+	}
+	if _, err := ra.DeleteCascade("00000000-0000-0000-0000-000000000001", 4); err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+	if _, err := ra.Get("00000000-0000-0000-0000-000000000001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if resources, _ := ra.GetAll(); len(resources) != 1 {
+		t.Errorf("Expected 1 resource but got %v", resources)
+	}
+	if page, _ := ra.List(ResourceQuery{Limit: 10}); page.Total != 1 {
+		t.Errorf("Expected a total of 1 but got %d", page.Total)
+	}
+
+	// They are listed in the trash, oldest first
+	trash, err := s.Trash().Get()
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting the trash: %v", err)
+	}
+	if len(trash.Resources) != 1 || trash.Resources[0].Guid != "00000000-0000-0000-0000-000000000001" || !trash.Resources[0].Deleted.Equal(testTime.Add(7*time.Minute)) {
+		t.Errorf("Expected the whiteboard deleted at %v but got %v", testTime.Add(7*time.Minute), trash.Resources)
+	}
+	if len(trash.Verbs) != 2 || trash.Verbs[0].Guid != "00000000-0000-0000-0000-000000000003" || trash.Verbs[1].Guid != "00000000-0000-0000-0000-000000000004" {
+		t.Errorf("Expected verbs 3 and 4 but got %v", trash.Verbs)
+	}
+
+	// A trashed resource takes no new verbs and keeps its verbs in the trash
+	if _, err := va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "wipe", Description: "can wipe"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if err := va.Restore("00000000-0000-0000-0000-000000000004"); err != ErrForeignKey {
+		t.Errorf("Expected %v but got %v", ErrForeignKey, err)
+	}
+	if err := va.Restore("00000000-0000-0000-0000-000000000005"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+
+	// Restoring a resource brings back the verbs deleted with it
+	if err := ra.Restore("00000000-0000-0000-0000-000000000001"); err != nil {
+		t.Errorf("An unexpected error occurred restoring a resource: %v", err)
+	}
+	if err := ra.Restore("00000000-0000-0000-0000-000000000001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if guids := verbGuids("00000000-0000-0000-0000-000000000001"); guids != "[4]" {
+		t.Errorf("Expected [4] but got %v", guids)
+	}
+	if err := va.Restore("00000000-0000-0000-0000-000000000003"); err != nil {
+		t.Errorf("An unexpected error occurred restoring a verb: %v", err)
+	}
+	if guids := verbGuids("00000000-0000-0000-0000-000000000001"); guids != "[3 4]" {
+		t.Errorf("Expected [3 4] but got %v", guids)
+	}
+
+	// Purging removes what was deleted before the cutoff
+	s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	va.Remove("00000000-0000-0000-0000-000000000005", 1)
+	cutoff := Now()
+	ra.DeleteCascade("00000000-0000-0000-0000-000000000001", 5)
+
+	purged, err := s.Trash().Purge(cutoff)
+	if err != nil || purged != 1 {
+		t.Errorf("Expected 1 purged but got %d, %v", purged, err)
+	}
+	purged, err = s.Trash().Purge(Now())
+	if err != nil || purged != 3 {
+		t.Errorf("Expected 3 purged but got %d, %v", purged, err)
+	}
+	if trash, _ := s.Trash().Get(); len(trash.Resources) != 0 || len(trash.Verbs) != 0 {
+		t.Errorf("Expected an empty trash but got %v", trash)
+	}
+	if err := ra.Restore("00000000-0000-0000-0000-000000000001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+
+	// The purged type's association went with it
+	if err := ra.Delete("00000000-0000-0000-0000-000000000002", 4); err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+}
+
+// Exercises an AuditStore against a fresh Store.
+func testAudit(t *testing.T, s Store) {
+	sequentialGuids()
+	tick := 0
+	Now = func() time.Time {
+		tick++
+		return testTime.Add(time.Duration(tick) * time.Minute)
+	}
+	aa := s.Audit()
+
+	board := "00000000-0000-0000-0000-000000000101"
+	room := "00000000-0000-0000-0000-000000000102"
+	for _, e := range []AuditEntry{
+		{Actor: "alice", Operation: OpCreate, Entity: EntityResource, EntityGUID: board, ResourceGUID: board, After: []byte(`{"name":"board"}`)},
+		{Actor: "bob", Operation: OpCreate, Entity: EntityResource, EntityGUID: room, ResourceGUID: room, After: []byte(`{"name":"room"}`)},
+		{Actor: "alice", Operation: OpUpdate, Entity: EntityResource, EntityGUID: board, ResourceGUID: board, Before: []byte(`{"name":"board"}`), After: []byte(`{"name":"whiteboard"}`)},
+		{Actor: "bob", Operation: OpDelete, Entity: EntityVerb, EntityGUID: "00000000-0000-0000-0000-000000000103", ResourceGUID: board, Before: []byte(`{"verb":"erase"}`)},
+	} {
+		if _, err := aa.Record(e); err != nil {
+			t.Errorf("An unexpected error occurred recording an audit entry: %v", err)
+		}
+	}
+
+	// Entries come back newest first with their values intact
+	entries, err := aa.Get(AuditQuery{})
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting the audit log: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 entries but got %v", entries)
+	}
+	expected := AuditEntry{"00000000-0000-0000-0000-000000000003", "alice", testTime.Add(3 * time.Minute), OpUpdate, EntityResource, board, board, []byte(`{"name":"board"}`), []byte(`{"name":"whiteboard"}`)}
+	if e := entries[1]; e.Guid != expected.Guid || e.Actor != expected.Actor || !e.Time.Equal(expected.Time) || e.Operation != expected.Operation ||
+		e.Entity != expected.Entity || e.EntityGUID != expected.EntityGUID || e.ResourceGUID != expected.ResourceGUID ||
+		string(e.Before) != string(expected.Before) || string(e.After) != string(expected.After) {
+		t.Errorf("Expected %v but got %v", expected, e)
+	}
+	if e := entries[0]; e.After != nil {
+		t.Errorf("Expected no after values but got %s", e.After)
+	}
+
+	entryGuids := func(q AuditQuery) string {
+		entries, err := aa.Get(q)
+		if err != nil {
+			t.Errorf("An unexpected error occurred getting the audit log: %v", err)
+		}
+		guids := make([]string, 0)
+		for _, e := range entries {
+			guids = append(guids, e.Guid[len(e.Guid)-1:])
+		}
+		return fmt.Sprint(guids)
+	}
+
+	for _, test := range []struct {
+		query    AuditQuery
+		expected string
+	}{
+		{AuditQuery{}, "[4 3 2 1]"},
+		{AuditQuery{Actor: "alice"}, "[3 1]"},
+		{AuditQuery{ResourceGUID: board}, "[4 3 1]"},
+		{AuditQuery{Actor: "bob", ResourceGUID: board}, "[4]"},
+		{AuditQuery{Since: testTime.Add(2 * time.Minute)}, "[4 3 2]"},
+		{AuditQuery{Until: testTime.Add(2 * time.Minute)}, "[1]"},
+		{AuditQuery{Since: testTime.Add(2 * time.Minute), Until: testTime.Add(4 * time.Minute)}, "[3 2]"},
+		{AuditQuery{Limit: 2}, "[4 3]"},
+		{AuditQuery{Actor: "carol"}, "[]"},
+	} {
+		if guids := entryGuids(test.query); guids != test.expected {
+			t.Errorf("Expected %v for %+v but got %v", test.expected, test.query, guids)
+		}
+	}
+
+	// Changes made through the store are recorded under its actor; refused
+	//   changes are not recorded
+	ra := s.As("carol").Resources()
+	guid, err := ra.Insert(Resource{Name: "board", Description: "a board", APIEndpoint: "tmt.byu.edu/boards"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred inserting a resource: %v", err)
+	}
+	if _, err := ra.Insert(Resource{Name: "board", Description: "another board", APIEndpoint: "tmt.byu.edu/boards"}); err == nil {
+		t.Errorf("Expected a duplicate name to be refused")
+	}
+	entries, err = aa.Get(AuditQuery{Actor: "carol"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting the audit log: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Operation != OpCreate || entries[0].Entity != EntityResource || entries[0].EntityGUID != guid || entries[0].Before != nil {
+		t.Fatalf("Expected the creation of %v but got %v", guid, entries)
+	}
+	var after Resource
+	if err := json.Unmarshal(entries[0].After, &after); err != nil || after.Name != "board" {
+		t.Errorf("Expected the values of the board but got %s", entries[0].After)
+	}
+}
+
+// Checks that writes record when they happened and that the change feed
+//   reports them.
+func testChanges(t *testing.T, s Store) {
+	sequentialGuids()
+	tick := 0
+	Now = func() time.Time {
+		tick++
+		return testTime.Add(time.Duration(tick) * time.Minute)
+	}
+	ra := s.Resources()
+	va := s.Verbs()
+	ca := s.Changes()
+
+	changeGuids := func(changes Changes) string {
+		guids := make([]string, 0)
+		for _, c := range changes.Resources {
+			guids = append(guids, c.Resource.Guid[len(c.Resource.Guid)-1:]+" "+c.Change)
+		}
+		for _, c := range changes.Verbs {
+			guids = append(guids, c.Verb.Guid[len(c.Verb.Guid)-1:]+" "+c.Change)
+		}
+		return fmt.Sprint(guids)
+	}
+
+	ra.Insert(Resource{Name: "whiteboard", Description: "a whiteboard", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	ra.Insert(Resource{Name: "room", Description: "a room", APIEndpoint: "tmt.byu.edu/rooms"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+
+	// Changing a verb changes its resource
+	whiteboard, err := ra.Get("00000000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting a resource: %v", err)
+	}
+	if !whiteboard.Created.Equal(testTime.Add(time.Minute)) || !whiteboard.Modified.Equal(testTime.Add(3*time.Minute)) || whiteboard.Version != 2 {
+		t.Errorf("Expected version 2 modified at %v but got %v", testTime.Add(3*time.Minute), whiteboard)
+	}
+
+	// Everything has changed since the beginning of time
+	changes, err := ca.Since(time.Time{})
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting changes: %v", err)
+	}
+	if guids := changeGuids(changes); guids != "[2 created 1 created 3 created]" {
+		t.Errorf("Expected [2 created 1 created 3 created] but got %v", guids)
+	}
+	if !changes.Last.Equal(testTime.Add(3 * time.Minute)) {
+		t.Errorf("Expected %v but got %v", testTime.Add(3*time.Minute), changes.Last)
+	}
+	last := changes.Last
+
+	ra.Update(Resource{Guid: "00000000-0000-0000-0000-000000000002", Name: "lab", Description: "a lab", APIEndpoint: "tmt.byu.edu/labs", Version: 1})
+	va.Update("00000000-0000-0000-0000-000000000003", "can wipe clean", 1)
+	ra.Insert(Resource{Name: "projector", Description: "a projector", APIEndpoint: "tmt.byu.edu/projectors"})
+	ra.Delete("00000000-0000-0000-0000-000000000002", 2)
+
+	// Only what changed since then is reported, oldest first
+	changes, err = ca.Since(last)
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting changes: %v", err)
+	}
+	if guids := changeGuids(changes); guids != "[1 updated 4 created 2 deleted 3 updated]" {
+		t.Errorf("Expected [1 updated 4 created 2 deleted 3 updated] but got %v", guids)
+	}
+	if len(changes.Verbs) == 1 && (changes.Verbs[0].Verb.Description != "can wipe clean" || !changes.Verbs[0].Modified.Equal(testTime.Add(5*time.Minute))) {
+		t.Errorf("Expected the verb changed at %v but got %v", testTime.Add(5*time.Minute), changes.Verbs[0])
+	}
+	if !changes.Last.Equal(testTime.Add(7 * time.Minute)) {
+		t.Errorf("Expected %v but got %v", testTime.Add(7*time.Minute), changes.Last)
+	}
+	if modified, err := ra.LastModified(); err != nil || !modified.Equal(testTime.Add(7*time.Minute)) {
+		t.Errorf("Expected %v but got %v, %v", testTime.Add(7*time.Minute), modified, err)
+	}
+
+	// Nothing has changed since the last change, which is in the commit
+	//   window before it
+	changes, err = ca.Since(changes.Last)
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting changes: %v", err)
+	}
+	if guids := changeGuids(changes); guids != "[2 deleted]" || !changes.Last.Equal(testTime.Add(7*time.Minute)) {
+		t.Errorf("Expected only [2 deleted] since %v but got %v", testTime.Add(7*time.Minute), guids)
+	}
+	changes, err = ca.Since(changes.Last.Add(CommitWindow))
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting changes: %v", err)
+	}
+	if guids := changeGuids(changes); guids != "[]" {
+		t.Errorf("Expected no changes but got %v", guids)
+	}
+
+	// Purging the trash does not take the last change back; it is audited,
+	//   so it is the last change
+	before := Now()
+	s.Trash().Purge(before)
+	if modified, err := ra.LastModified(); err != nil || !modified.Equal(before.Add(time.Minute)) {
+		t.Errorf("Expected %v but got %v, %v", before.Add(time.Minute), modified, err)
+	}
+	entries, err := s.Audit().Get(AuditQuery{Actor: PurgeActor})
+	if err != nil || len(entries) != 1 || entries[0].Operation != OpDelete || entries[0].Entity != EntityResource || entries[0].After != nil {
+		t.Errorf("Expected the purged lab to be audited but got %v, %v", entries, err)
+	}
+}
+
+// Checks that the catalog exports what is stored and that imports merge
+//   into or replace it, all or nothing, auditing every change.
+func testCatalog(t *testing.T, s Store) {
+	sequentialGuids()
+	stopClock()
+	ra := s.Resources()
+	ca := s.As("importer").Catalog()
+
+	// How many of each operation on each entity the importer has made
+	operations := func() string {
+		entries, err := s.Audit().Get(AuditQuery{Actor: "importer"})
+		if err != nil {
+			t.Errorf("An unexpected error occurred getting the audit log: %v", err)
+		}
+		counts := make(map[string]int)
+		for _, e := range entries {
+			counts[e.Operation+" "+e.Entity]++
+		}
+		return fmt.Sprint(counts)
+	}
+
+	ra.Insert(Resource{Name: "whiteboard", Description: "a whiteboard", APIEndpoint: "https://tmt.byu.edu/whiteboards"})
+	ra.Insert(Resource{Name: "room", Description: "a room", APIEndpoint: "https://tmt.byu.edu/rooms"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	s.Types().Insert("00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002")
+
+	catalog, err := ca.Export()
+	if err != nil {
+		t.Errorf("An unexpected error occurred exporting: %v", err)
+	}
+	expected := Catalog{
+		[]CatalogResource{
+			CatalogResource{"00000000-0000-0000-0000-000000000001", "whiteboard", "a whiteboard", "https://tmt.byu.edu/whiteboards", []CatalogVerb{
+				CatalogVerb{"00000000-0000-0000-0000-000000000003", "erase", "can erase"},
+			}},
+			CatalogResource{"00000000-0000-0000-0000-000000000002", "room", "a room", "https://tmt.byu.edu/rooms", []CatalogVerb{}},
+		},
+		[]CatalogType{CatalogType{"00000000-0000-0000-0000-000000000004", "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"}},
+	}
+	if fmt.Sprint(catalog) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v but got %v", expected, catalog)
+	}
+
+	// Merging adds and updates and keeps the rest
+	catalog.Resources[0].Name = "dry erase board"
+	catalog.Resources = append(catalog.Resources, CatalogResource{"00000000-0000-0000-0000-000000000101", "projector", "a projector", "https://tmt.byu.edu/projectors", []CatalogVerb{
+		CatalogVerb{"00000000-0000-0000-0000-000000000102", "project", "can project"},
+	}})
+	catalog.Resources[1].Verbs = nil
+	result, err := ca.Import(Catalog{catalog.Resources[0:1], nil}, ImportMerge)
+	if err != nil {
+		t.Errorf("An unexpected error occurred importing: %v", err)
+	}
+	if expected := (ImportResult{0, 1, 1, 0}); result != expected {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+	if expected := "map[update resource:1]"; operations() != expected {
+		t.Errorf("Expected %v but got %v", expected, operations())
+	}
+	result, err = ca.Import(catalog, ImportMerge)
+	if err != nil {
+		t.Errorf("An unexpected error occurred importing: %v", err)
+	}
+	if expected := (ImportResult{2, 0, 4, 0}); result != expected {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+	if r, err := ra.Get("00000000-0000-0000-0000-000000000001"); err != nil || r.Name != "dry erase board" || r.Version != 4 {
+		t.Errorf("Expected the dry erase board at version 4 but got %v, %v", r, err)
+	}
+	if verbs, _ := s.Verbs().GetByResource("00000000-0000-0000-0000-000000000101"); len(verbs) != 1 || verbs[0].Guid != "00000000-0000-0000-0000-000000000102" || verbs[0].Version != 1 {
+		t.Errorf("Expected the project verb but got %v", verbs)
+	}
+	if expected := "map[create resource:1 create verb:1 update resource:1]"; operations() != expected {
+		t.Errorf("Expected %v but got %v", expected, operations())
+	}
+
+	// Invalid catalogs change nothing
+	for _, invalid := range []Catalog{
+		Catalog{[]CatalogResource{CatalogResource{Guid: "00000000-0000-0000-0000-000000000201", Name: "lamp"}, CatalogResource{Guid: "00000000-0000-0000-0000-000000000201"}}, nil},
+		Catalog{[]CatalogResource{CatalogResource{Guid: "00000000-0000-0000-0000-000000000201", Name: "lamp"}}, []CatalogType{CatalogType{"00000000-0000-0000-0000-000000000202", "00000000-0000-0000-0000-000000000201", "00000000-0000-0000-0000-000000000999"}}},
+		Catalog{[]CatalogResource{CatalogResource{Guid: "00000000-0000-0000-0000-000000000201", Name: "lamp"}, CatalogResource{Guid: "00000000-0000-0000-0000-000000000202", Name: "lamp"}}, nil},
+		Catalog{[]CatalogResource{CatalogResource{Guid: "00000000-0000-0000-0000-000000000201", Name: "lamp", Verbs: []CatalogVerb{
+			CatalogVerb{"00000000-0000-0000-0000-000000000202", "light", ""}, CatalogVerb{"00000000-0000-0000-0000-000000000203", "light", ""},
+		}}}, nil},
+		Catalog{[]CatalogResource{CatalogResource{Guid: "00000000-0000-0000-0000-000000000201", Name: "room"}}, nil},
+		Catalog{[]CatalogResource{CatalogResource{Guid: "201", Name: "lamp"}}, nil},
+		Catalog{[]CatalogResource{CatalogResource{Guid: "00000000-0000-0000-0000-000000000201", Name: "lamp"}}, []CatalogType{CatalogType{"00000000-0000-0000-0000-000000000202", "00000000-0000-0000-0000-000000000201", "00000000-0000-0000-0000-000000000201"}}},
+	} {
+		if _, err := ca.Import(invalid, ImportMerge); err == nil {
+			t.Errorf("Expected a *CatalogError but got %v", err)
+		} else if _, ok := err.(*CatalogError); !ok {
+			t.Errorf("Expected a *CatalogError but got %v", err)
+		}
+	}
+	if _, err := ra.Get("00000000-0000-0000-0000-000000000201"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if expected := "map[create resource:1 create verb:1 update resource:1]"; operations() != expected {
+		t.Errorf("Expected %v but got %v", expected, operations())
+	}
+	if _, err := ca.Import(catalog, "overwrite"); err != ErrInvalidImportMode {
+		t.Errorf("Expected %v but got %v", ErrInvalidImportMode, err)
+	}
+
+	// Replacing trashes everything else
+	result, err = ca.Import(Catalog{[]CatalogResource{catalog.Resources[0]}, nil}, ImportReplace)
+	if err != nil {
+		t.Errorf("An unexpected error occurred importing: %v", err)
+	}
+	if expected := (ImportResult{0, 0, 2, 4}); result != expected {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+	if resources, _ := ra.GetAll(); len(resources) != 1 || resources[0].Version != 5 {
+		t.Errorf("Expected only the dry erase board but got %v", resources)
+	}
+	if trash, _ := s.Trash().Get(); len(trash.Resources) != 2 || len(trash.Verbs) != 1 {
+		t.Errorf("Expected 2 resources and a verb in the trash but got %v", trash)
+	}
+
+	// Importing a trashed resource restores it
+	result, err = ca.Import(catalog, ImportMerge)
+	if err != nil {
+		t.Errorf("An unexpected error occurred importing: %v", err)
+	}
+	if expected := (ImportResult{1, 3, 2, 0}); result != expected {
+		t.Errorf("Expected %v but got %v", expected, result)
+	}
+	exported, _ := ca.Export()
+	if len(exported.Resources) != 3 || len(exported.Resources[2].Verbs) != 1 || len(exported.Types) != 1 {
+		t.Errorf("Expected everything back but got %v", exported)
+	}
+	if expected := "map[create resource:1 create type:1 create verb:1 delete resource:2 delete type:1 delete verb:1 restore resource:2 restore verb:1 update resource:1]"; operations() != expected {
+		t.Errorf("Expected %v but got %v", expected, operations())
+	}
+
+	// Replacing a verb away takes its grants with it
+	grant, err := s.Grants().Add(Grant{Principal: "jdoe", ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred adding a grant: %v", err)
+	}
+	board := catalog.Resources[0]
+	board.Verbs = nil
+	if _, err := ca.Import(Catalog{[]CatalogResource{board, catalog.Resources[1], catalog.Resources[2]}, catalog.Types}, ImportReplace); err != nil {
+		t.Errorf("An unexpected error occurred importing: %v", err)
+	}
+	if _, err := s.Grants().Get(grant); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if expected := "map[create resource:1 create type:1 create verb:1 delete grant:1 delete resource:2 delete type:1 delete verb:2 restore resource:2 restore verb:1 update resource:1]"; operations() != expected {
+		t.Errorf("Expected %v but got %v", expected, operations())
+	}
+}
+
+// Exercises verb inheritance through a type chain: overriding, suppressing
+//   and a chain that loops back on itself.
+func testInheritance(t *testing.T, s Store) {
+	sequentialGuids()
+	stopClock()
+	ra := s.Resources()
+	va := s.Verbs()
+
+	ra.Insert(Resource{Name: "fixture", Description: "fixture type", APIEndpoint: "tmt.byu.edu/fixtures"})
+	ra.Insert(Resource{Name: "whiteboard", Description: "whiteboard type", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	ra.Insert(Resource{Name: "board 1", Description: "the first board", APIEndpoint: "tmt.byu.edu/whiteboards/1"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "view", Description: "can view"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "repair", Description: "can repair"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase", Description: "can erase"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "view", Description: "can view the board"})
+	va.Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000003", Verb: "edit", Description: "can edit"})
+	s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	s.Types().Insert("00000000-0000-0000-0000-000000000003", "00000000-0000-0000-0000-000000000002")
+
+	// Each verb as name@defining resource<via, overridden verbs
+	effective := func(resource string) string {
+		inheritance, err := va.GetEffective(resource)
+		if err != nil {
+			t.Errorf("An unexpected error occurred getting effective verbs: %v", err)
+		}
+		verbs := make([]string, 0)
+		for _, v := range inheritance.Verbs {
+			verb := fmt.Sprintf("%s@%s", v.Verb, v.ResourceGUID[len(v.ResourceGUID)-1:])
+			if v.Inherited {
+				verb += "<" + v.Via[len(v.Via)-1:]
+			}
+			for _, o := range v.Overrides {
+				verb += "," + o[len(o)-1:]
+			}
+			verbs = append(verbs, verb)
+		}
+		return fmt.Sprint(verbs)
+	}
+
+	// The nearest definition wins
+	if verbs, expected := effective("00000000-0000-0000-0000-000000000003"), "[edit@3 erase@2<2 view@2<2,4 repair@1<2]"; verbs != expected {
+		t.Errorf("Expected %v but got %v", expected, verbs)
+	}
+	if verbs, expected := effective("00000000-0000-0000-0000-000000000002"), "[erase@2 view@2,4 repair@1<1]"; verbs != expected {
+		t.Errorf("Expected %v but got %v", expected, verbs)
+	}
+
+	// Suppressing hides an inherited verb
+	guid, err := va.Suppress("00000000-0000-0000-0000-000000000003", "repair")
+	if err != nil || guid != "00000000-0000-0000-0000-000000000011" {
+		t.Errorf("Expected guid 00000000-0000-0000-0000-000000000011 but got %v, %v", guid, err)
+	}
+	if _, err := va.Suppress("00000000-0000-0000-0000-000000000003", "repair"); err != ErrDuplicate {
+		t.Errorf("Expected %v but got %v", ErrDuplicate, err)
+	}
+	if _, err := va.Suppress("99999999-9999-9999-9999-999999999999", "repair"); err != ErrForeignKey {
+		t.Errorf("Expected %v but got %v", ErrForeignKey, err)
+	}
+	if verbs, expected := effective("00000000-0000-0000-0000-000000000003"), "[edit@3 erase@2<2 view@2<2,4]"; verbs != expected {
+		t.Errorf("Expected %v but got %v", expected, verbs)
+	}
+	if r, _ := ra.Get("00000000-0000-0000-0000-000000000003"); r.Version != 4 {
+		t.Errorf("Expected board 1 at version 4 but got %v", r)
+	}
+	inheritance, _ := va.GetEffective("00000000-0000-0000-0000-000000000003")
+	if len(inheritance.Suppressed) != 1 || inheritance.Suppressed[0] != (VerbSuppression{"00000000-0000-0000-0000-000000000011", "00000000-0000-0000-0000-000000000003", "repair"}) {
+		t.Errorf("Expected the repair suppression but got %v", inheritance.Suppressed)
+	}
+	if len(inheritance.Chain) != 2 || inheritance.Chain[0].Name != "whiteboard" || inheritance.Chain[1].Name != "fixture" {
+		t.Errorf("Expected the whiteboard and fixture types but got %v", inheritance.Chain)
+	}
+
+	// A chain looping back ends at the first resource walked twice
+	s.Types().Insert("00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000003")
+	if verbs, expected := effective("00000000-0000-0000-0000-000000000001"), "[view@1,7 repair@1 edit@3<3 erase@2<3]"; verbs != expected {
+		t.Errorf("Expected %v but got %v", expected, verbs)
+	}
+
+	// Unsuppressing brings the verb back
+	if err := va.Unsuppress("00000000-0000-0000-0000-000000000003", "repair"); err != nil {
+		t.Errorf("An unexpected error occurred unsuppressing: %v", err)
+	}
+	if err := va.Unsuppress("00000000-0000-0000-0000-000000000003", "repair"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if verbs, expected := effective("00000000-0000-0000-0000-000000000003"), "[edit@3 erase@2<2 view@2<2,4 repair@1<2]"; verbs != expected {
+		t.Errorf("Expected %v but got %v", expected, verbs)
+	}
+
+	// Types in the trash pass nothing on
+	ra.DeleteCascade("00000000-0000-0000-0000-000000000002", 4)
+	if verbs, expected := effective("00000000-0000-0000-0000-000000000003"), "[edit@3]"; verbs != expected {
+		t.Errorf("Expected %v but got %v", expected, verbs)
+	}
+}
+
+// Exercises GrantStore: grants are checked against the effective verbs of
+//   their resource and go when the verb does.
+func testGrants(t *testing.T, s Store) {
+	sequentialGuids()
+	stopClock()
+	ga := s.Grants()
+
+	s.Resources().Insert(Resource{Name: "whiteboard", Description: "whiteboard type", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	s.Resources().Insert(Resource{Name: "board 1", Description: "the first board", APIEndpoint: "tmt.byu.edu/whiteboards/1"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "edit", Description: "can edit"})
+	s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+
+	// Inherited verbs can be granted too
+	guid, err := ga.Add(Grant{Principal: "jdoe", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase"})
+	if err != nil || guid != "00000000-0000-0000-0000-000000000006" {
+		t.Errorf("Expected guid 00000000-0000-0000-0000-000000000006 but got %v, %v", guid, err)
+	}
+	ga.Add(Grant{Role: "staff", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "edit"})
+	ga.Add(Grant{Role: "staff", ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase"})
+
+	for _, invalid := range []struct {
+		grant    Grant
+		expected error
+	}{
+		{Grant{Principal: "jdoe", Role: "staff", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "edit"}, ErrInvalidGrant},
+		{Grant{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "edit"}, ErrInvalidGrant},
+		{Grant{Principal: "jdoe", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "paint"}, ErrUnknownVerb},
+		{Grant{Principal: "jdoe", ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "edit"}, ErrUnknownVerb},
+		{Grant{Principal: "jdoe", ResourceGUID: "99999999-9999-9999-9999-999999999999", Verb: "edit"}, ErrForeignKey},
+		{Grant{Principal: "jdoe", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase"}, ErrDuplicate},
+	} {
+		if _, err := ga.Add(invalid.grant); err != invalid.expected {
+			t.Errorf("Expected %v for %v but got %v", invalid.expected, invalid.grant, err)
+		}
+	}
+
+	expected := Grant{"00000000-0000-0000-0000-000000000006", "jdoe", "", "00000000-0000-0000-0000-000000000002", "erase", 1}
+	if grant, err := ga.Get("00000000-0000-0000-0000-000000000006"); err != nil || grant != expected {
+		t.Errorf("Expected %v but got %v, %v", expected, grant, err)
+	}
+	grantGuids := func(q GrantQuery) string {
+		grants, err := ga.List(q)
+		if err != nil {
+			t.Errorf("An unexpected error occurred listing grants: %v", err)
+		}
+		guids := make([]string, 0)
+		for _, g := range grants {
+			guids = append(guids, g.Guid[len(g.Guid)-1:])
+		}
+		return fmt.Sprint(guids)
+	}
+	for _, c := range []struct {
+		query    GrantQuery
+		expected string
+	}{
+		{GrantQuery{}, "[6 7 8]"},
+		{GrantQuery{Role: "staff"}, "[7 8]"},
+		{GrantQuery{ResourceGUID: "00000000-0000-0000-0000-000000000002"}, "[6 7]"},
+		{GrantQuery{Verb: "erase", Principal: "jdoe"}, "[6]"},
+	} {
+		if guids := grantGuids(c.query); guids != c.expected {
+			t.Errorf("Expected %v for %v but got %v", c.expected, c.query, guids)
+		}
+	}
+
+	// Updates are checked like additions and against the version
+	expected.Principal, expected.Role, expected.Verb = "", "staff", "edit"
+	if err := ga.Update(expected); err != ErrDuplicate {
+		t.Errorf("Expected %v but got %v", ErrDuplicate, err)
+	}
+	expected.Role = "faculty"
+	if err := ga.Update(expected); err != nil {
+		t.Errorf("An unexpected error occurred updating a grant: %v", err)
+	}
+	if err := ga.Update(expected); err != ErrVersionMismatch {
+		t.Errorf("Expected %v but got %v", ErrVersionMismatch, err)
+	}
+	expected.Version = 2
+	if grant, _ := ga.Get("00000000-0000-0000-0000-000000000006"); grant != expected {
+		t.Errorf("Expected %v but got %v", expected, grant)
+	}
+
+	// Removing a verb removes its grants, also where it was inherited
+	ga.Add(Grant{Principal: "jdoe", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase"})
+	s.Verbs().Remove("00000000-0000-0000-0000-000000000003", 1)
+	if guids := grantGuids(GrantQuery{}); guids != "[6 7]" {
+		t.Errorf("Expected [6 7] but got %v", guids)
+	}
+	entries, err := s.Audit().Get(AuditQuery{})
+	deleted := 0
+	for _, e := range entries {
+		if e.Operation == OpDelete && e.Entity == EntityGrant {
+			deleted++
+		}
+	}
+	if err != nil || deleted != 2 {
+		t.Errorf("Expected 2 grant deletions but got %d, %v", deleted, err)
+	}
+
+	// Revoking deletes the grant
+	if err := ga.Remove("00000000-0000-0000-0000-000000000007", 2); err != ErrVersionMismatch {
+		t.Errorf("Expected %v but got %v", ErrVersionMismatch, err)
+	}
+	if err := ga.Remove("00000000-0000-0000-0000-000000000007", 1); err != nil {
+		t.Errorf("An unexpected error occurred removing a grant: %v", err)
+	}
+	if err := ga.Remove("00000000-0000-0000-0000-000000000007", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+
+	// The grants of a trashed resource are hidden until it is purged
+	s.Resources().DeleteCascade("00000000-0000-0000-0000-000000000002", 3)
+	if _, err := ga.Get("00000000-0000-0000-0000-000000000006"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+	if _, err := s.Trash().Purge(Now().Add(time.Second)); err != nil {
+		t.Errorf("An unexpected error occurred purging: %v", err)
+	}
+
+	// Removing a verb leaves the grants of unrelated resources, trashed or not
+	lamp, _ := s.Resources().Insert(Resource{Name: "lamp", Description: "a lamp", APIEndpoint: "tmt.byu.edu/lamps/1"})
+	projector, _ := s.Resources().Insert(Resource{Name: "projector", Description: "a projector", APIEndpoint: "tmt.byu.edu/projectors/1"})
+	lampVerb, _ := s.Verbs().Add(ResourceVerb{ResourceGUID: lamp, Verb: "view", Description: "can view"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: projector, Verb: "view", Description: "can view"})
+	kept, err := ga.Add(Grant{Principal: "jdoe", ResourceGUID: projector, Verb: "view"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred adding a grant: %v", err)
+	}
+	r, _ := s.Resources().Get(projector)
+	s.Resources().DeleteCascade(projector, r.Version)
+	if err := s.Verbs().Remove(lampVerb, 1); err != nil {
+		t.Errorf("An unexpected error occurred removing a verb: %v", err)
+	}
+	s.Resources().Restore(projector)
+	if _, err := ga.Get(kept); err != nil {
+		t.Errorf("Expected the projector grant to remain but got %v", err)
+	}
+}
+
+// Checks that access is decided by the nearest grant to the principal or
+//   one of its roles, on the resource or along its type chain.
+func testAuthorize(t *testing.T, s Store) {
+	sequentialGuids()
+	stopClock()
+	ga := s.Grants()
+
+	s.Resources().Insert(Resource{Name: "whiteboard", Description: "whiteboard type", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	s.Resources().Insert(Resource{Name: "board 1", Description: "the first board", APIEndpoint: "tmt.byu.edu/whiteboards/1"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "view", Description: "can view"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "edit", Description: "can edit"})
+	s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	s.Verbs().Suppress("00000000-0000-0000-0000-000000000002", "view")
+
+	ga.Add(Grant{Role: "staff", ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase"})
+	ga.Add(Grant{Principal: "jdoe", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase"})
+	ga.Add(Grant{Role: "staff", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "edit"})
+	ga.Add(Grant{Principal: "jdoe", ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "view"})
+	ga.Add(Grant{Role: "staff", ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase"})
+
+	// Summarizes a decision as its outcome and the last digits of its
+	//   grant's guid, or its reason
+	summary := func(d Decision) string {
+		if d.Grant == nil {
+			return d.Decision + " " + d.Reason
+		}
+		return d.Decision + " " + strings.TrimLeft(d.Grant.Guid[24:], "0")
+	}
+
+	cases := []struct {
+		request  AccessRequest
+		expected string
+	}{
+		{AccessRequest{"jdoe", []string{"staff"}, "00000000-0000-0000-0000-000000000002", "erase"}, "allow 9"},
+		{AccessRequest{"asmith", []string{"staff"}, "00000000-0000-0000-0000-000000000002", "erase"}, "allow 12"},
+		{AccessRequest{"", []string{"faculty", "staff"}, "00000000-0000-0000-0000-000000000001", "erase"}, "allow 8"},
+		{AccessRequest{"asmith", []string{"staff"}, "00000000-0000-0000-0000-000000000002", "edit"}, "allow 10"},
+		{AccessRequest{"asmith", nil, "00000000-0000-0000-0000-000000000002", "edit"}, "deny no grant matches"},
+		{AccessRequest{"jdoe", nil, "00000000-0000-0000-0000-000000000001", "view"}, "allow 11"},
+		{AccessRequest{"jdoe", nil, "00000000-0000-0000-0000-000000000002", "view"}, "deny the resource has no such verb"},
+		{AccessRequest{"jdoe", nil, "00000000-0000-0000-0000-000000000002", "paint"}, "deny the resource has no such verb"},
+		{AccessRequest{"jdoe", []string{"staff"}, "99999999-9999-9999-9999-999999999999", "erase"}, "deny the resource does not exist"},
+	}
+	requests := make([]AccessRequest, 0, len(cases))
+	for _, c := range cases {
+		requests = append(requests, c.request)
+	}
+	decisions, err := ga.Authorize(requests)
+	if err != nil || len(decisions) != len(cases) {
+		t.Fatalf("Expected %d decisions but got %v, %v", len(cases), decisions, err)
+	}
+	for i, c := range cases {
+		if got := summary(decisions[i]); got != c.expected {
+			t.Errorf("Expected %v for %v but got %v", c.expected, c.request, got)
+		}
+	}
+
+	// A grant on the type still applies once the resource's own is revoked
+	ga.Remove("00000000-0000-0000-0000-000000000012", 1)
+	decisions, _ = ga.Authorize(requests[1:2])
+	if got := summary(decisions[0]); got != "allow 8" {
+		t.Errorf("Expected allow 8 but got %v", got)
+	}
+
+	// Nothing is allowed on a trashed resource
+	s.Resources().DeleteCascade("00000000-0000-0000-0000-000000000002", 4)
+	decisions, _ = ga.Authorize(requests[:1])
+	if got := summary(decisions[0]); got != "deny the resource does not exist" {
+		t.Errorf("Expected deny the resource does not exist but got %v", got)
+	}
+}
+
+// Makes a whiteboard type with two verbs and n boards of that type, each
+//   with a verb of its own, grants to a role on the type and to a
+//   principal on each board, and returns the boards' guids.
+func newAuthorizeBenchmark(b *testing.B, s Store, n int) []string {
+	sequentialGuids()
+	whiteboard, err := s.Resources().Insert(Resource{Name: "whiteboard"})
+	if err != nil {
+		b.Fatalf("An unexpected error occurred inserting a resource: %v", err)
+	}
+	s.Verbs().Add(ResourceVerb{ResourceGUID: whiteboard, Verb: "view", Description: "can view"})
+	s.Verbs().Add(ResourceVerb{ResourceGUID: whiteboard, Verb: "erase", Description: "can erase"})
+	s.Grants().Add(Grant{Role: "staff", ResourceGUID: whiteboard, Verb: "view"})
+
+	boards := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		board, err := s.Resources().Insert(Resource{Name: fmt.Sprintf("board %d", i)})
+		if err != nil {
+			b.Fatalf("An unexpected error occurred inserting a resource: %v", err)
+		}
+		s.Verbs().Add(ResourceVerb{ResourceGUID: board, Verb: "edit", Description: "can edit"})
+		s.Types().Insert(board, whiteboard)
+		if _, err := s.Grants().Add(Grant{Principal: fmt.Sprintf("user%d", i), ResourceGUID: board, Verb: "erase"}); err != nil {
+			b.Fatalf("An unexpected error occurred adding a grant: %v", err)
+		}
+		boards = append(boards, board)
+	}
+	return boards
+}
+
+// Decides one request at a time, alternating between a grant on the board
+//   and one inherited from its type.
+func benchmarkAuthorize(b *testing.B, s Store) {
+	boards := newAuthorizeBenchmark(b, s, 1000)
+	ga := s.Grants()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		j := i % len(boards)
+		req := AccessRequest{fmt.Sprintf("user%d", j), []string{"staff"}, boards[j], "erase"}
+		if i%2 == 1 {
+			req.Verb = "view"
+		}
+		decisions, err := ga.Authorize([]AccessRequest{req})
+		if err != nil || decisions[0].Decision != DecisionAllow {
+			b.Fatalf("Expected allow but got %v, %v", decisions, err)
+		}
+	}
+}
+
+// Decides batches of 100 requests on 10 boards.
+func benchmarkAuthorizeBatch(b *testing.B, s Store) {
+	boards := newAuthorizeBenchmark(b, s, 1000)
+	ga := s.Grants()
+
+	requests := make([]AccessRequest, 0, 100)
+	for i := 0; i < 100; i++ {
+		j := i % 10
+		requests = append(requests, AccessRequest{fmt.Sprintf("user%d", j), []string{"staff"}, boards[j], []string{"view", "erase", "edit"}[i%3]})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ga.Authorize(requests); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Checks that constraint violations come back as ErrDuplicate and
+//   ErrForeignKey.
+func testConstraints(t *testing.T, s Store) {
+	sequentialGuids()
+	s.Resources().Insert(Resource{Name: "whiteboard", Description: "whiteboard type", APIEndpoint: "tmt.byu.edu/whiteboards"})
+	s.Resources().Insert(Resource{Name: "board 1", Description: "the first board", APIEndpoint: "tmt.byu.edu/whiteboards/1"})
+
+	// Verbs and types must refer to existing resources
+	_, err := s.Verbs().Add(ResourceVerb{ResourceGUID: "99999999-9999-9999-9999-999999999999", Verb: "edit", Description: "can edit"})
+	if expected := (NotFoundError{EntityResource, "99999999-9999-9999-9999-999999999999"}); !errors.As(err, new(*NotFoundError)) || *err.(*NotFoundError) != expected {
+		t.Errorf("Expected %v but got %v", expected, err)
+	}
+	_, err = s.Types().Insert("00000000-0000-0000-0000-000000000002", "99999999-9999-9999-9999-999999999999")
+	if expected := (NotFoundError{EntityResource, "99999999-9999-9999-9999-999999999999"}); !errors.As(err, new(*NotFoundError)) || *err.(*NotFoundError) != expected {
+		t.Errorf("Expected %v but got %v", expected, err)
+	}
+
+	// Nor can a resource be its own type, or have a type in the trash
+	if _, err := s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000002"); err != ErrSelfType {
+		t.Errorf("Expected %v but got %v", ErrSelfType, err)
+	}
+	trashed, _ := s.Resources().Insert(Resource{Name: "old board", Description: "a trashed board", APIEndpoint: "tmt.byu.edu/whiteboards/old"})
+	s.Resources().Delete(trashed, 1)
+	if _, err := s.Types().Insert("00000000-0000-0000-0000-000000000002", trashed); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected %v but got %v", ErrNotFound, err)
+	}
+
+	// A resource that is still referred to cannot be deleted
+	boardType, err := s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	if err != nil {
+		t.Errorf("An unexpected error occurred inserting a type: %v", err)
+	}
+	if err := s.Resources().Delete("00000000-0000-0000-0000-000000000001", 1); !errors.Is(err, ErrForeignKey) {
+		t.Errorf("Expected %v but got %v", ErrForeignKey, err)
+	}
+
+	// Live resources have unique names, resources unique verbs and types
+	_, err = s.Resources().Insert(Resource{Name: "whiteboard", Description: "another whiteboard", APIEndpoint: "tmt.byu.edu/boards"})
+	if expected := (DuplicateError{EntityResource, "00000000-0000-0000-0000-000000000001"}); !errors.As(err, new(*DuplicateError)) || *err.(*DuplicateError) != expected {
+		t.Errorf("Expected %v but got %v", &expected, err)
+	}
+	board := Resource{Guid: "00000000-0000-0000-0000-000000000002", Name: "whiteboard", Description: "the first board", APIEndpoint: "tmt.byu.edu/whiteboards/1", Version: 2}
+	if err := s.Resources().Update(board); !errors.Is(err, ErrDuplicate) || !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected %v but got %v", ErrDuplicate, err)
+	}
+	verb, err := s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase", Description: "can erase"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred adding a verb: %v", err)
+	}
+	_, err = s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase", Description: "can erase again"})
+	if expected := (DuplicateError{EntityVerb, verb}); !errors.As(err, new(*DuplicateError)) || *err.(*DuplicateError) != expected {
+		t.Errorf("Expected %v but got %v", &expected, err)
+	}
+	_, err = s.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	if expected := (DuplicateError{EntityType, boardType}); !errors.As(err, new(*DuplicateError)) || *err.(*DuplicateError) != expected {
+		t.Errorf("Expected %v but got %v", &expected, err)
+	}
+
+	// Trashed ones do not count, but cannot be restored over a live one
+	if err := s.Verbs().Remove(verb, 1); err != nil {
+		t.Errorf("An unexpected error occurred removing a verb: %v", err)
+	}
+	again, err := s.Verbs().Add(ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase", Description: "can erase again"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred adding a verb: %v", err)
+	}
+	err = s.Verbs().Restore(verb)
+	if expected := (DuplicateError{EntityVerb, again}); !errors.As(err, new(*DuplicateError)) || *err.(*DuplicateError) != expected {
+		t.Errorf("Expected %v but got %v", &expected, err)
+	}
+	rooms, err := s.Resources().Insert(Resource{Name: "room", Description: "room type", APIEndpoint: "tmt.byu.edu/rooms"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred inserting a resource: %v", err)
+	}
+	if err := s.Resources().Delete(rooms, 1); err != nil {
+		t.Errorf("An unexpected error occurred deleting a resource: %v", err)
+	}
+	newRooms, err := s.Resources().Insert(Resource{Name: "room", Description: "room type", APIEndpoint: "tmt.byu.edu/rooms"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred inserting a resource: %v", err)
+	}
+	err = s.Resources().Restore(rooms)
+	if expected := (DuplicateError{EntityResource, newRooms}); !errors.As(err, new(*DuplicateError)) || *err.(*DuplicateError) != expected {
+		t.Errorf("Expected %v but got %v", &expected, err)
+	}
+
+	// Guids are unique
+	NewGuid = func() string {
+		return "00000000-0000-0000-0000-000000000001"
+	}
+	_, err = s.Resources().Insert(Resource{Name: "duplicate", Description: "a duplicate", APIEndpoint: "tmt.byu.edu/duplicates"})
+	if err != ErrDuplicate {
+		t.Errorf("Expected %v but got %v", ErrDuplicate, err)
+	}
+}
+
+// Empties the tables of a database shared with other test runs.
+func clearTables(t *testing.T, db *sql.DB) {
+	for _, table := range []string{"auditLog", "resourceTypes", "resourceVerbs", "resources"} {
+		if _, err := db.Exec("DELETE FROM " + table); err != nil {
+			t.Fatalf("An unexpected error occurred clearing %s: %v", table, err)
+		}
+	}
+}
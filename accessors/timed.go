@@ -0,0 +1,286 @@
+package accessors
+
+import (
+	"time"
+)
+
+// TimedStore is a Store that times every call to the Store it wraps and
+//   reports it to Observe, named by accessor ("resources", "verbs",
+//   "types", "trash", "audit", "changes", "catalog" or "grants") and
+//   method.
+type TimedStore struct {
+	Store   Store
+	Observe func(accessor, method string, took time.Duration)
+}
+
+// Returns a store timing the calls to s.
+func NewTimedStore(s Store, observe func(accessor, method string, took time.Duration)) *TimedStore {
+	return &TimedStore{s, observe}
+}
+
+// Reports a call that started at start.
+func (s *TimedStore) since(accessor, method string, start time.Time) {
+	s.Observe(accessor, method, time.Since(start))
+}
+
+func (s *TimedStore) Resources() ResourceStore {
+	return timedResources{s, s.Store.Resources()}
+}
+
+func (s *TimedStore) Verbs() ResourceVerbStore {
+	return timedVerbs{s, s.Store.Verbs()}
+}
+
+func (s *TimedStore) Types() ResourceTypeStore {
+	return timedTypes{s, s.Store.Types()}
+}
+
+func (s *TimedStore) Trash() TrashStore {
+	return timedTrash{s, s.Store.Trash()}
+}
+
+func (s *TimedStore) Audit() AuditStore {
+	return timedAudit{s, s.Store.Audit()}
+}
+
+func (s *TimedStore) Changes() ChangeStore {
+	return timedChanges{s, s.Store.Changes()}
+}
+
+func (s *TimedStore) Catalog() CatalogStore {
+	return timedCatalog{s, s.Store.Catalog()}
+}
+
+func (s *TimedStore) Grants() GrantStore {
+	return timedGrants{s, s.Store.Grants()}
+}
+
+// Returns a store timing the calls to s.As(actor).
+func (s *TimedStore) As(actor string) Store {
+	return &TimedStore{s.Store.As(actor), s.Observe}
+}
+
+type timedResources struct {
+	s *TimedStore
+	a ResourceStore
+}
+
+func (t timedResources) Get(guid string) (Resource, error) {
+	defer t.s.since("resources", "Get", time.Now())
+	return t.a.Get(guid)
+}
+
+func (t timedResources) GetAll() ([]Resource, error) {
+	defer t.s.since("resources", "GetAll", time.Now())
+	return t.a.GetAll()
+}
+
+func (t timedResources) LastModified() (time.Time, error) {
+	defer t.s.since("resources", "LastModified", time.Now())
+	return t.a.LastModified()
+}
+
+func (t timedResources) List(q ResourceQuery) (ResourcePage, error) {
+	defer t.s.since("resources", "List", time.Now())
+	return t.a.List(q)
+}
+
+func (t timedResources) Insert(r Resource) (string, error) {
+	defer t.s.since("resources", "Insert", time.Now())
+	return t.a.Insert(r)
+}
+
+func (t timedResources) Update(r Resource) error {
+	defer t.s.since("resources", "Update", time.Now())
+	return t.a.Update(r)
+}
+
+func (t timedResources) Delete(guid string, version int) error {
+	defer t.s.since("resources", "Delete", time.Now())
+	return t.a.Delete(guid, version)
+}
+
+func (t timedResources) DeleteCascade(guid string, version int) (Dependents, error) {
+	defer t.s.since("resources", "DeleteCascade", time.Now())
+	return t.a.DeleteCascade(guid, version)
+}
+
+func (t timedResources) Restore(guid string) error {
+	defer t.s.since("resources", "Restore", time.Now())
+	return t.a.Restore(guid)
+}
+
+type timedVerbs struct {
+	s *TimedStore
+	a ResourceVerbStore
+}
+
+func (t timedVerbs) Get(guid string) (ResourceVerb, error) {
+	defer t.s.since("verbs", "Get", time.Now())
+	return t.a.Get(guid)
+}
+
+func (t timedVerbs) GetByResource(resource string) ([]ResourceVerb, error) {
+	defer t.s.since("verbs", "GetByResource", time.Now())
+	return t.a.GetByResource(resource)
+}
+
+func (t timedVerbs) GetByResources(resources []string) (map[string][]ResourceVerb, error) {
+	defer t.s.since("verbs", "GetByResources", time.Now())
+	return t.a.GetByResources(resources)
+}
+
+func (t timedVerbs) Add(r ResourceVerb) (string, error) {
+	defer t.s.since("verbs", "Add", time.Now())
+	return t.a.Add(r)
+}
+
+func (t timedVerbs) Update(guid, description string, version int) error {
+	defer t.s.since("verbs", "Update", time.Now())
+	return t.a.Update(guid, description, version)
+}
+
+func (t timedVerbs) Remove(guid string, version int) error {
+	defer t.s.since("verbs", "Remove", time.Now())
+	return t.a.Remove(guid, version)
+}
+
+func (t timedVerbs) Restore(guid string) error {
+	defer t.s.since("verbs", "Restore", time.Now())
+	return t.a.Restore(guid)
+}
+
+func (t timedVerbs) GetEffective(resource string) (Inheritance, error) {
+	defer t.s.since("verbs", "GetEffective", time.Now())
+	return t.a.GetEffective(resource)
+}
+
+func (t timedVerbs) Suppress(resource, verb string) (string, error) {
+	defer t.s.since("verbs", "Suppress", time.Now())
+	return t.a.Suppress(resource, verb)
+}
+
+func (t timedVerbs) Unsuppress(resource, verb string) error {
+	defer t.s.since("verbs", "Unsuppress", time.Now())
+	return t.a.Unsuppress(resource, verb)
+}
+
+type timedTypes struct {
+	s *TimedStore
+	a ResourceTypeStore
+}
+
+func (t timedTypes) GetType(guid string) (Resource, error) {
+	defer t.s.since("types", "GetType", time.Now())
+	return t.a.GetType(guid)
+}
+
+func (t timedTypes) GetTypes(resource string) ([]ResourceTypeDetail, error) {
+	defer t.s.since("types", "GetTypes", time.Now())
+	return t.a.GetTypes(resource)
+}
+
+func (t timedTypes) GetResourcesOfType(typeGUID string) ([]Resource, error) {
+	defer t.s.since("types", "GetResourcesOfType", time.Now())
+	return t.a.GetResourcesOfType(typeGUID)
+}
+
+func (t timedTypes) GetTypesInUse() ([]TypeInUse, error) {
+	defer t.s.since("types", "GetTypesInUse", time.Now())
+	return t.a.GetTypesInUse()
+}
+
+func (t timedTypes) Insert(r, rt string) (string, error) {
+	defer t.s.since("types", "Insert", time.Now())
+	return t.a.Insert(r, rt)
+}
+
+type timedTrash struct {
+	s *TimedStore
+	a TrashStore
+}
+
+func (t timedTrash) Get() (Trash, error) {
+	defer t.s.since("trash", "Get", time.Now())
+	return t.a.Get()
+}
+
+func (t timedTrash) Purge(before time.Time) (int, error) {
+	defer t.s.since("trash", "Purge", time.Now())
+	return t.a.Purge(before)
+}
+
+type timedAudit struct {
+	s *TimedStore
+	a AuditStore
+}
+
+func (t timedAudit) Record(e AuditEntry) (string, error) {
+	defer t.s.since("audit", "Record", time.Now())
+	return t.a.Record(e)
+}
+
+func (t timedAudit) Get(q AuditQuery) ([]AuditEntry, error) {
+	defer t.s.since("audit", "Get", time.Now())
+	return t.a.Get(q)
+}
+
+type timedChanges struct {
+	s *TimedStore
+	a ChangeStore
+}
+
+func (t timedChanges) Since(since time.Time) (Changes, error) {
+	defer t.s.since("changes", "Since", time.Now())
+	return t.a.Since(since)
+}
+
+type timedCatalog struct {
+	s *TimedStore
+	a CatalogStore
+}
+
+func (t timedCatalog) Export() (Catalog, error) {
+	defer t.s.since("catalog", "Export", time.Now())
+	return t.a.Export()
+}
+
+func (t timedCatalog) Import(c Catalog, mode string) (ImportResult, error) {
+	defer t.s.since("catalog", "Import", time.Now())
+	return t.a.Import(c, mode)
+}
+
+type timedGrants struct {
+	s *TimedStore
+	a GrantStore
+}
+
+func (t timedGrants) Get(guid string) (Grant, error) {
+	defer t.s.since("grants", "Get", time.Now())
+	return t.a.Get(guid)
+}
+
+func (t timedGrants) List(q GrantQuery) ([]Grant, error) {
+	defer t.s.since("grants", "List", time.Now())
+	return t.a.List(q)
+}
+
+func (t timedGrants) Add(g Grant) (string, error) {
+	defer t.s.since("grants", "Add", time.Now())
+	return t.a.Add(g)
+}
+
+func (t timedGrants) Update(g Grant) error {
+	defer t.s.since("grants", "Update", time.Now())
+	return t.a.Update(g)
+}
+
+func (t timedGrants) Remove(guid string, version int) error {
+	defer t.s.since("grants", "Remove", time.Now())
+	return t.a.Remove(guid, version)
+}
+
+func (t timedGrants) Authorize(reqs []AccessRequest) ([]Decision, error) {
+	defer t.s.since("grants", "Authorize", time.Now())
+	return t.a.Authorize(reqs)
+}
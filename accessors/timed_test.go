@@ -0,0 +1,44 @@
+package accessors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimedStore(t *testing.T) {
+	var calls []string
+	s := NewTimedStore(NewMemoryStore(), func(accessor, method string, took time.Duration) {
+		if took < 0 {
+			t.Errorf("Expected a positive duration but got %v", took)
+		}
+		calls = append(calls, accessor+"."+method)
+	})
+
+	// Calls go through to the wrapped store
+	guid, err := s.Resources().Insert(Resource{Name: "Whiteboard", Description: "A whiteboard", APIEndpoint: "https://tmt.byu.edu/whiteboards"})
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if _, err := s.Verbs().Add(ResourceVerb{ResourceGUID: guid, Verb: "erase", Description: "Erase it"}); err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	if r, err := s.Resources().Get(guid); err != nil || r.Name != "Whiteboard" {
+		t.Errorf("Expected Whiteboard but got %v %v", r, err)
+	}
+
+	// Errors are passed on, and the call is still timed
+	if _, err := s.Resources().Get("missing"); err == nil {
+		t.Errorf("Expected an error but got none")
+	}
+
+	expected := []string{"resources.Insert", "verbs.Add", "resources.Get", "resources.Get"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected %v but got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected %v but got %v", expected, calls)
+			break
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package accessors
+
+import (
+	"database/sql"
+	"time"
+)
+
+// A resource in the trash.
+type TrashedResource struct {
+	Resource
+	Deleted time.Time `json:"deleted"`
+}
+
+// A verb in the trash.
+type TrashedVerb struct {
+	ResourceVerb
+	Deleted time.Time `json:"deleted"`
+}
+
+// Trash holds everything that has been deleted but not yet purged, oldest
+//   deletion first.
+type Trash struct {
+	Resources []TrashedResource `json:"resources"`
+	Verbs     []TrashedVerb     `json:"verbs"`
+}
+
+type TrashAccessor struct {
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+}
+
+// Returns a new trash accessor.
+func NewTrashAccessor(db *sql.DB) *TrashAccessor {
+	return &TrashAccessor{db, MySQL}
+}
+
+// Gets the contents of the trash.
+func (ta *TrashAccessor) Get() (Trash, error) {
+	trash := Trash{make([]TrashedResource, 0), make([]TrashedVerb, 0)}
+
+	stmt, err := ta.Dialect.prepare(ta.DB, "SELECT "+resourceColumns+", deleted FROM resources WHERE deleted IS NOT NULL ORDER BY deleted, guid")
+	if err != nil {
+		return trash, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query()
+	if err != nil {
+		return trash, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r TrashedResource
+		if err := rows.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint, timestamp{&r.Created}, timestamp{&r.Modified}, &r.Version, timestamp{&r.Deleted}); err != nil {
+			return trash, err
+		}
+		trash.Resources = append(trash.Resources, r)
+	}
+	if err := rows.Err(); err != nil {
+		return trash, err
+	}
+
+	verbStmt, err := ta.Dialect.prepare(ta.DB, "SELECT "+verbColumns+", deleted FROM resourceVerbs WHERE deleted IS NOT NULL ORDER BY deleted, guid")
+	if err != nil {
+		return trash, err
+	}
+	defer verbStmt.Close()
+
+	verbRows, err := verbStmt.Query()
+	if err != nil {
+		return trash, err
+	}
+	defer verbRows.Close()
+
+	for verbRows.Next() {
+		var v TrashedVerb
+		if err := verbRows.Scan(&v.Guid, &v.ResourceGUID, &v.Verb, &v.Description, &v.Version, timestamp{&v.Deleted}); err != nil {
+			return trash, err
+		}
+		trash.Verbs = append(trash.Verbs, v)
+	}
+	return trash, verbRows.Err()
+}
+
+// Who the audit log records purges as made by: the service purges the
+//   trash on its own rather than for a user.
+const PurgeActor = "trash purge"
+
+// Permanently deletes the resources and verbs trashed before the given
+//   time, in one transaction, and returns how many were deleted. The verbs,
+//   type associations, suppressions and grants of a purged resource go
+//   with it. Each row deleted is recorded in the audit log as deleted by
+//   PurgeActor.
+func (ta *TrashAccessor) Purge(before time.Time) (int, error) {
+	tx, err := ta.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	now := Now()
+	var entries []AuditEntry
+	audit := func(entity, guid, resource string, values interface{}) error {
+		e, err := newAuditEntry(PurgeActor, now, OpDelete, entity, guid, resource, values, nil)
+		entries = append(entries, e)
+		return err
+	}
+
+	purged := 0
+	for _, purge := range []struct {
+		columns string
+		from    string // The table and which of its rows to purge
+		args    []interface{}
+		count   bool
+		audit   func(rows *sql.Rows) error
+	}{
+		{grantColumns, "grants WHERE resourceGUID IN (SELECT guid FROM resources WHERE deleted<?)", []interface{}{before}, false, func(rows *sql.Rows) error {
+			var g Grant
+			if err := scanGrant(rows, &g); err != nil {
+				return err
+			}
+			return audit(EntityGrant, g.Guid, g.ResourceGUID, g)
+		}},
+		{"guid, resourceGUID, verb", "suppressedVerbs WHERE resourceGUID IN (SELECT guid FROM resources WHERE deleted<?)", []interface{}{before}, false, func(rows *sql.Rows) error {
+			var v VerbSuppression
+			if err := rows.Scan(&v.Guid, &v.ResourceGUID, &v.Verb); err != nil {
+				return err
+			}
+			return audit(EntitySuppression, v.Guid, v.ResourceGUID, v)
+		}},
+		{"guid, resourceGUID, type", "resourceTypes WHERE resourceGUID IN (SELECT guid FROM resources WHERE deleted<?) OR type IN (SELECT guid FROM resources WHERE deleted<?)", []interface{}{before, before}, false, func(rows *sql.Rows) error {
+			var t ResourceType
+			if err := rows.Scan(&t.Guid, &t.ResourceGUID, &t.Type); err != nil {
+				return err
+			}
+			return audit(EntityType, t.Guid, t.ResourceGUID, t)
+		}},
+		{verbColumns, "resourceVerbs WHERE deleted<? OR resourceGUID IN (SELECT guid FROM resources WHERE deleted<?)", []interface{}{before, before}, true, func(rows *sql.Rows) error {
+			var v ResourceVerb
+			if err := rows.Scan(&v.Guid, &v.ResourceGUID, &v.Verb, &v.Description, &v.Version); err != nil {
+				return err
+			}
+			return audit(EntityVerb, v.Guid, v.ResourceGUID, v)
+		}},
+		{resourceColumns, "resources WHERE deleted<?", []interface{}{before}, true, func(rows *sql.Rows) error {
+			var r Resource
+			if err := rows.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint, timestamp{&r.Created}, timestamp{&r.Modified}, &r.Version); err != nil {
+				return err
+			}
+			return audit(EntityResource, r.Guid, r.Guid, resourceValues(r))
+		}},
+	} {
+		if err := queryEach(tx, ta.Dialect, "SELECT "+purge.columns+" FROM "+purge.from+" ORDER BY guid", purge.audit, purge.args...); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		stmt, err := ta.Dialect.prepare(tx, "DELETE FROM "+purge.from)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		result, err := stmt.Exec(purge.args...)
+		stmt.Close()
+		if err != nil {
+			tx.Rollback()
+			return 0, ta.Dialect.err(err)
+		}
+		if purge.count {
+			n, err := result.RowsAffected()
+			if err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+			purged += int(n)
+		}
+	}
+	if err := recordEntries(tx, ta.Dialect, entries...); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return purged, tx.Commit()
+}
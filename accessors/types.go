@@ -2,40 +2,200 @@ package accessors
 
 import (
 	"database/sql"
-	_ "github.com/go-sql-driver/mysql"
 )
 
+// Returned when a resource would be made its own type.
+var ErrSelfType = kindError(ErrInvalid, "accessors: a resource cannot be its own type")
+
+// ResourceType struct that reflects the resourceTypes table. It records
+//   that the resource ResourceGUID is of the type described by the
+//   resource Type.
+type ResourceType struct {
+	Guid         string `json:"guid"`
+	ResourceGUID string `json:"resourceGUID"`
+	Type         string `json:"type"`
+}
+
+// A type association together with the resource that is the type.
+type ResourceTypeDetail struct {
+	ResourceType
+	TypeResource Resource `json:"typeResource"`
+}
+
+// A resource that is the type of other resources, with how many it is the
+//   type of.
+type TypeInUse struct {
+	Resource
+	Count int `json:"count"`
+}
+
+// The resourceColumns, qualified for queries joining resources to
+//   resourceTypes.
+const joinedResourceColumns = "resources.guid, resources.name, resources.description, resources.apiEndpoint, resources.created, resources.modified, resources.version"
+
 type ResourceTypeAccessor struct {
-	DB *sql.DB // Database connection
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+	Actor   string  // Who the changes are recorded as made by
 }
 
 // Returns a new resource accessor.
 func NewResourceTypeAccessor(db *sql.DB) *ResourceTypeAccessor {
-	return &ResourceTypeAccessor{db}
+	return &ResourceTypeAccessor{db, MySQL, ""}
 }
 
 // Returns the resource type information for the given resource.
 //   For example, if the guid of a whiteboard is given, it will return
-//   information about the whiteboard resource type.
+//   information about the whiteboard resource type. A resource with
+//   several types returns the first by association guid; GetTypes returns
+//   them all.
 func (ra *ResourceTypeAccessor) GetType(guid string) (Resource, error) {
 	r := Resource{}
-	stmt, err := ra.DB.Prepare("SELECT guid, name, description, apiEndpoint FROM resources JOIN resourceTypes ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID=?")
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT "+joinedResourceColumns+" FROM resources JOIN resourceTypes ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID=? AND resources.deleted IS NULL AND resourceTypes.resourceGUID IN (SELECT guid FROM resources WHERE deleted IS NULL) ORDER BY resourceTypes.guid")
 	if err != nil {
 		return r, err
 	}
+	defer stmt.Close()
 
 	row := stmt.QueryRow(guid)
-	err = row.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint)
-	return r, err
+	err = scanResource(row, &r)
+	return r, notFound(err, EntityType, guid)
+}
+
+// Returns every type of the given resource, each with its association,
+//   ordered by association guid. Types in the trash are left out, as are
+//   all types of a resource in the trash.
+func (ra *ResourceTypeAccessor) GetTypes(resource string) ([]ResourceTypeDetail, error) {
+	types := make([]ResourceTypeDetail, 0)
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT resourceTypes.guid, resourceTypes.resourceGUID, resourceTypes.type, "+joinedResourceColumns+" FROM resourceTypes JOIN resources ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID=? AND resources.deleted IS NULL AND resourceTypes.resourceGUID IN (SELECT guid FROM resources WHERE deleted IS NULL) ORDER BY resourceTypes.guid")
+	if err != nil {
+		return types, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(resource)
+	if err != nil {
+		return types, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t ResourceTypeDetail
+		r := &t.TypeResource
+		if err := rows.Scan(&t.Guid, &t.ResourceGUID, &t.Type, &r.Guid, &r.Name, &r.Description, &r.APIEndpoint, timestamp{&r.Created}, timestamp{&r.Modified}, &r.Version); err != nil {
+			return types, err
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+// Returns the resources of the given type that are not in the trash, in
+//   the order they were created. For example, if the guid of the
+//   whiteboard type is given, it returns every whiteboard. A type in the
+//   trash has no resources.
+func (ra *ResourceTypeAccessor) GetResourcesOfType(typeGUID string) ([]Resource, error) {
+	resources := make([]Resource, 0)
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT DISTINCT "+joinedResourceColumns+" FROM resources JOIN resourceTypes ON resources.guid=resourceTypes.resourceGUID WHERE resourceTypes.type=? AND resources.deleted IS NULL AND resourceTypes.type IN (SELECT guid FROM resources WHERE deleted IS NULL) ORDER BY resources.created, resources.guid")
+	if err != nil {
+		return resources, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(typeGUID)
+	if err != nil {
+		return resources, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Resource
+		if err := scanResource(rows, &r); err != nil {
+			return resources, err
+		}
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
 }
 
-// Create a new resourceType.
-func (ra *ResourceTypeAccessor) Insert(r, t string) error {
-	stmt, err := ra.DB.Prepare("INSERT INTO resourceTypes (guid, resourceGUID, type) VALUES (?,?,?)")
+// Returns every resource outside the trash that is the type of at least
+//   one other resource outside the trash, with how many, ordered by name.
+func (ra *ResourceTypeAccessor) GetTypesInUse() ([]TypeInUse, error) {
+	types := make([]TypeInUse, 0)
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT "+joinedResourceColumns+", COUNT(DISTINCT resourceTypes.resourceGUID) FROM resources JOIN resourceTypes ON resources.guid=resourceTypes.type WHERE resources.deleted IS NULL AND resourceTypes.resourceGUID IN (SELECT guid FROM resources WHERE deleted IS NULL) GROUP BY "+joinedResourceColumns+" ORDER BY resources.name, resources.guid")
+	if err != nil {
+		return types, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query()
+	if err != nil {
+		return types, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t TypeInUse
+		r := &t.Resource
+		if err := rows.Scan(&r.Guid, &r.Name, &r.Description, &r.APIEndpoint, timestamp{&r.Created}, timestamp{&r.Modified}, &r.Version, &t.Count); err != nil {
+			return types, err
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+// Create a new resourceType and return its guid. The resource's version
+//   is bumped, since its type decides the verbs it inherits. Returns
+//   ErrSelfType if the resource would be its own type, a *NotFoundError if
+//   either resource does not exist or is in the trash and a
+//   *DuplicateError if the resource already has the type.
+func (ra *ResourceTypeAccessor) Insert(r, t string) (string, error) {
+	if r == t {
+		return "", ErrSelfType
+	}
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	if err := checkLive(tx, ra.Dialect, r, t); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	guid, now := NewGuid(), Now()
+	if err := execTx(tx, ra.Dialect, "INSERT INTO resourceTypes (guid, resourceGUID, type) VALUES (?,?,?)", guid, r, t); err != nil {
+		tx.Rollback()
+		return "", duplicate(ra.DB, ra.Dialect, err, EntityType, "SELECT guid FROM resourceTypes WHERE resourceGUID=? AND type=?", r, t)
+	}
+	if err := execTx(tx, ra.Dialect, "UPDATE resources SET version=version+1, modified=? WHERE guid=?", now, r); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	if err := record(tx, ra.Dialect, ra.Actor, now, OpCreate, EntityType, guid, r, nil, ResourceType{guid, r, t}); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	return guid, tx.Commit()
+}
+
+// Checks, in a transaction, that each of the resources is outside the
+//   trash. Returns a *NotFoundError naming the first that is not.
+func checkLive(tx *sql.Tx, d Dialect, guids ...string) error {
+	stmt, err := d.prepare(tx, "SELECT COUNT(*) FROM resources WHERE guid=? AND deleted IS NULL")
 	if err != nil {
 		return err
 	}
+	defer stmt.Close()
 
-	_, err = stmt.Exec(NewGuid(), r, t)
-	return err
+	for _, guid := range guids {
+		var count int
+		if err := stmt.QueryRow(guid).Scan(&count); err != nil {
+			return err
+		}
+		if count == 0 {
+			return &NotFoundError{EntityResource, guid}
+		}
+	}
+	return nil
 }
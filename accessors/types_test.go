@@ -15,12 +15,12 @@ func TestGetType(t *testing.T) {
 
 	ra := NewResourceTypeAccessor(db)
 
-	expected := Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "tmt.byu.edu/resources", nil}
-	columns := []string{"guid", "name", "description", "apiEndpoint"}
+	expected := Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "tmt.byu.edu/resources", testTime, testTime, 1, nil}
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT guid, name, description, apiEndpoint FROM resources JOIN resourceTypes ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources JOIN resourceTypes ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID=(.)").
 		WithArgs("11111111-2222-3333-2222-111111111111").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
 	resource, err := ra.GetType("11111111-2222-3333-2222-111111111111")
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resource %v", err)
@@ -39,6 +39,7 @@ func TestInsertType(t *testing.T) {
 	NewGuid = func() string {
 		return "123def"
 	}
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred creating the mock database")
@@ -47,12 +48,34 @@ func TestInsertType(t *testing.T) {
 
 	ra := NewResourceTypeAccessor(db)
 
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-2222-3333-2222-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("55555555-6666-7777-8888-999999999999").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 	sqlmock.ExpectPrepare()
 	sqlmock.ExpectExec("INSERT INTO resourceTypes (.+) VALUES (.+)").
 		WithArgs("123def", "11111111-2222-3333-2222-111111111111", "55555555-6666-7777-8888-999999999999").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET version=version\\+1, modified=(.) WHERE guid=(.)").
+		WithArgs(testTime, "11111111-2222-3333-2222-111111111111").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
-	ra.Insert("11111111-2222-3333-2222-111111111111", "55555555-6666-7777-8888-999999999999")
+	guid, err := ra.Insert("11111111-2222-3333-2222-111111111111", "55555555-6666-7777-8888-999999999999")
+	if err != nil {
+		t.Errorf("An unexpected error occurred while inserting a type: %v", err)
+	}
+	if guid != "123def" {
+		t.Errorf("Expected guid 123def but got %v", guid)
+	}
 
 	if err := ra.DB.Close(); err != nil {
 		t.Errorf("An error occurred: %v", err)
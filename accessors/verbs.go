@@ -2,7 +2,8 @@ package accessors
 
 import (
 	"database/sql"
-	_ "github.com/go-sql-driver/mysql"
+	"strings"
+	"time"
 )
 
 // Resource struct that reflects the resources table.
@@ -11,36 +12,54 @@ type ResourceVerb struct {
 	ResourceGUID string `json:"resourceGUID"`
 	Verb         string `json:"verb"`
 	Description  string `json:"description"`
+	Version      int    `json:"version"` // Incremented by every update
+}
+
+// The columns of the resourceVerbs table, in the order scanVerb reads them.
+const verbColumns = "guid, resourceGUID, verb, description, version"
+
+// Scans the verbColumns of a row into v.
+func scanVerb(row scanner, v *ResourceVerb) error {
+	return row.Scan(&v.Guid, &v.ResourceGUID, &v.Verb, &v.Description, &v.Version)
 }
 
 type ResourceVerbAccessor struct {
-	DB *sql.DB // Database connection
+	DB      *sql.DB // Database connection
+	Dialect Dialect // SQL dialect of the database
+	Actor   string  // Who the changes are recorded as made by
 }
 
 // Returns a new resource verb accessor.
 func NewResourceVerbAccessor(db *sql.DB) *ResourceVerbAccessor {
-	return &ResourceVerbAccessor{db}
+	return &ResourceVerbAccessor{db, MySQL, ""}
 }
 
 func (ra *ResourceVerbAccessor) Get(guid string) (ResourceVerb, error) {
+	return getVerb(ra.DB, ra.Dialect, guid)
+}
+
+// Gets a verb that is not in the trash, in or out of a transaction.
+func getVerb(db preparer, d Dialect, guid string) (ResourceVerb, error) {
 	var r ResourceVerb
-	stmt, err := ra.DB.Prepare("SELECT * FROM resourceVerbs WHERE guid=?")
+	stmt, err := d.prepare(db, "SELECT "+verbColumns+" FROM resourceVerbs WHERE guid=? AND deleted IS NULL")
 	if err != nil {
 		return r, err
 	}
+	defer stmt.Close()
 
 	row := stmt.QueryRow(guid)
-	err = row.Scan(&r.Guid, &r.ResourceGUID, &r.Verb, &r.Description)
-	return r, err
+	err = scanVerb(row, &r)
+	return r, notFound(err, EntityVerb, guid)
 }
 
 // Gets all verbs associated to a given resource by that resource's guid.
 func (ra *ResourceVerbAccessor) GetByResource(resource string) ([]ResourceVerb, error) {
 	verbs := make([]ResourceVerb, 0)
-	stmt, err := ra.DB.Prepare("SELECT * FROM resourceVerbs WHERE resourceGUID=?")
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT "+verbColumns+" FROM resourceVerbs WHERE resourceGUID=? AND deleted IS NULL")
 	if err != nil {
 		return verbs, err
 	}
+	defer stmt.Close()
 
 	rows, err := stmt.Query(resource)
 	if err != nil {
@@ -50,43 +69,286 @@ func (ra *ResourceVerbAccessor) GetByResource(resource string) ([]ResourceVerb,
 
 	for rows.Next() {
 		var r ResourceVerb
-		rows.Scan(&r.Guid, &r.ResourceGUID, &r.Verb, &r.Description)
+		if err := scanVerb(rows, &r); err != nil {
+			return verbs, err
+		}
 		verbs = append(verbs, r)
 	}
+	return verbs, rows.Err()
+}
+
+// Largest number of guids bound to one IN list; SQLite allows at most 999
+//   parameters in a statement.
+const maxInList = 500
 
+// Gets the verbs of several resources at once, keyed by resource guid.
+//   Every given resource has an entry, empty if it has no verbs. The verbs
+//   are loaded with one query per maxInList resources rather than one per
+//   resource.
+func (ra *ResourceVerbAccessor) GetByResources(resources []string) (map[string][]ResourceVerb, error) {
+	verbs := make(map[string][]ResourceVerb, len(resources))
+	for _, guid := range resources {
+		verbs[guid] = make([]ResourceVerb, 0)
+	}
+
+	for start := 0; start < len(resources); start += maxInList {
+		end := start + maxInList
+		if end > len(resources) {
+			end = len(resources)
+		}
+		if err := ra.getByResources(resources[start:end], verbs); err != nil {
+			return nil, err
+		}
+	}
 	return verbs, nil
 }
 
-// Associate a new verb to a resource.
-func (ra *ResourceVerbAccessor) Add(r ResourceVerb) error {
-	stmt, err := ra.DB.Prepare("INSERT INTO resourceVerbs (guid, resourceGUID, name, description) VALUES (?,?,?,?)")
+// Adds the verbs of the given resources to verbs with a single query.
+func (ra *ResourceVerbAccessor) getByResources(resources []string, verbs map[string][]ResourceVerb) error {
+	args := make([]interface{}, len(resources))
+	for i, guid := range resources {
+		args[i] = guid
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(resources)), ",")
+
+	stmt, err := ra.Dialect.prepare(ra.DB, "SELECT "+verbColumns+" FROM resourceVerbs WHERE resourceGUID IN ("+placeholders+") AND deleted IS NULL")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(args...)
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 
-	_, err = stmt.Exec(NewGuid(), r.ResourceGUID, r.Verb, r.Description)
-	return err
+	for rows.Next() {
+		var r ResourceVerb
+		if err := scanVerb(rows, &r); err != nil {
+			return err
+		}
+		verbs[r.ResourceGUID] = append(verbs[r.ResourceGUID], r)
+	}
+	return rows.Err()
 }
 
-// Update the description for a verb on a resource type. The guid passed in
-//   is the guid of the resource/verb association.
-func (ra *ResourceVerbAccessor) Update(guid, description string) error {
-	stmt, err := ra.DB.Prepare("UPDATE resourceVerbs SET description=? WHERE guid=?")
+// Queries for the guid of the live verb a resource has of a name, given
+//   the resource and the name or the guid of a trashed verb of that name.
+const (
+	verbNamed        = "SELECT guid FROM resourceVerbs WHERE resourceGUID=? AND verb=? AND deleted IS NULL"
+	verbNamedAsTrash = "SELECT live.guid FROM resourceVerbs live JOIN resourceVerbs trashed ON trashed.resourceGUID=live.resourceGUID AND trashed.verb=live.verb WHERE trashed.guid=? AND live.deleted IS NULL"
+)
+
+// Associate a new verb to a resource and return the association's guid.
+//   Returns a *NotFoundError if the resource does not exist or is in the
+//   trash and a *DuplicateError if it already has a verb of that name.
+func (ra *ResourceVerbAccessor) Add(r ResourceVerb) (string, error) {
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+
+	// The foreign key cannot tell a trashed resource apart
+	if err := checkLive(tx, ra.Dialect, r.ResourceGUID); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	stmt, err := ra.Dialect.prepare(tx, "INSERT INTO resourceVerbs (guid, resourceGUID, verb, description, created, modified) VALUES (?,?,?,?,?,?)")
+	if err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	defer stmt.Close()
+
+	guid, now := NewGuid(), Now()
+	if _, err = stmt.Exec(guid, r.ResourceGUID, r.Verb, r.Description, now, now); err != nil {
+		tx.Rollback()
+		return "", duplicate(ra.DB, ra.Dialect, ra.Dialect.err(err), EntityVerb, verbNamed, r.ResourceGUID, r.Verb)
+	}
+	if err := touchResource(tx, ra.Dialect, guid, now); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+
+	r.Guid, r.Version = guid, 1
+	if err := record(tx, ra.Dialect, ra.Actor, now, OpCreate, EntityVerb, guid, r.ResourceGUID, nil, r); err != nil {
+		tx.Rollback()
+		return "", err
+	}
+	return guid, tx.Commit()
+}
+
+// Update the description for a verb on a resource type and bump its
+//   version, provided that version is still the stored version. The guid
+//   passed in is the guid of the resource/verb association. Returns
+//   ErrVersionMismatch if the verb has changed since that version and
+//   a *NotFoundError if it does not exist or is in the trash.
+func (ra *ResourceVerbAccessor) Update(guid, description string, version int) error {
+	tx, err := ra.DB.Begin()
 	if err != nil {
 		return err
 	}
 
-	_, err = stmt.Exec(description, guid)
-	return err
+	before, err := getVerb(tx, ra.Dialect, guid)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := ra.Dialect.prepare(tx, "UPDATE resourceVerbs SET description=?, version=version+1, modified=? WHERE guid=? AND version=? AND deleted IS NULL")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	now := Now()
+	result, err := stmt.Exec(description, now, guid, version)
+	if err != nil {
+		tx.Rollback()
+		return ra.Dialect.err(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			// Nothing was updated; find out why
+			err = versionMismatch(tx, ra.Dialect, "SELECT COUNT(*) FROM resourceVerbs WHERE guid=? AND deleted IS NULL", EntityVerb, guid)
+		}
+		tx.Rollback()
+		return err
+	}
+
+	if err := touchResource(tx, ra.Dialect, guid, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	after := before
+	after.Description, after.Version = description, before.Version+1
+	if err := record(tx, ra.Dialect, ra.Actor, now, OpUpdate, EntityVerb, guid, before.ResourceGUID, before, after); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Disassociate a verb from a resource type by moving it to the trash. The
+//   grants of the verb go with it, on its resource and on those that
+//   inherited it, unless they still have a verb of that name. The verb is
+//   only removed if version is still its stored version; returns
+//   ErrVersionMismatch if it has changed since and a *NotFoundError if the
+//   verb does not exist or is already in the trash.
+func (ra *ResourceVerbAccessor) Remove(guid string, version int) error {
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	verb, err := getVerb(tx, ra.Dialect, guid)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	stmt, err := ra.Dialect.prepare(tx, "UPDATE resourceVerbs SET deleted=?, modified=? WHERE guid=? AND version=? AND deleted IS NULL")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	now := Now()
+	result, err := stmt.Exec(now, now, guid, version)
+	if err != nil {
+		tx.Rollback()
+		return ra.Dialect.err(err)
+	}
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			// Nothing was removed; find out why
+			err = versionMismatch(tx, ra.Dialect, "SELECT COUNT(*) FROM resourceVerbs WHERE guid=? AND deleted IS NULL", EntityVerb, guid)
+		}
+		tx.Rollback()
+		return err
+	}
+
+	if err := touchResource(tx, ra.Dialect, guid, now); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := deleteOrphanedGrants(tx, ra.Dialect, ra.Actor, now, verb); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := record(tx, ra.Dialect, ra.Actor, now, OpDelete, EntityVerb, guid, verb.ResourceGUID, verb, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
-// Disassociate a verb from a resource type.
-func (ra *ResourceVerbAccessor) Remove(guid string) error {
-	stmt, err := ra.DB.Prepare("DELETE FROM resourceVerbs WHERE guid=?")
+// Takes a verb out of the trash. Returns a *NotFoundError if the verb is
+//   not in the trash, ErrForeignKey if its resource is and a
+//   *DuplicateError if the resource has since been given a verb of its
+//   name.
+func (ra *ResourceVerbAccessor) Restore(guid string) error {
+	tx, err := ra.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := ra.Dialect.prepare(tx, "UPDATE resourceVerbs SET deleted=NULL, modified=? WHERE guid=? AND deleted IS NOT NULL AND resourceGUID IN (SELECT guid FROM resources WHERE deleted IS NULL)")
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
+	defer stmt.Close()
+
+	now := Now()
+	result, err := stmt.Exec(now, guid)
+	if err != nil {
+		tx.Rollback()
+		return duplicate(ra.DB, ra.Dialect, ra.Dialect.err(err), EntityVerb, verbNamedAsTrash, guid)
+	}
+	n, err := result.RowsAffected()
+	if err == nil && n > 0 {
+		err = touchResource(tx, ra.Dialect, guid, now)
+	}
+	if err == nil && n > 0 {
+		var verb ResourceVerb
+		if verb, err = getVerb(tx, ra.Dialect, guid); err == nil {
+			err = record(tx, ra.Dialect, ra.Actor, now, OpRestore, EntityVerb, guid, verb.ResourceGUID, nil, verb)
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if n > 0 {
+		return tx.Commit()
+	}
+	tx.Rollback()
+
+	// Nothing was restored; find out why
+	countStmt, err := ra.Dialect.prepare(ra.DB, "SELECT COUNT(*) FROM resourceVerbs WHERE guid=? AND deleted IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	defer countStmt.Close()
+
+	var count int
+	if err := countStmt.QueryRow(guid).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return &NotFoundError{EntityVerb, guid}
+	}
+	return ErrForeignKey
+}
 
-	_, err = stmt.Exec(guid)
-	return err
+// Bumps the version and modification time of the resource a verb belongs
+//   to, since its verbs are part of it.
+func touchResource(tx *sql.Tx, d Dialect, verb string, now time.Time) error {
+	return execTx(tx, d, "UPDATE resources SET version=version+1, modified=? WHERE guid=(SELECT resourceGUID FROM resourceVerbs WHERE guid=?)", now, verb)
 }
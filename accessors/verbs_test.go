@@ -15,12 +15,12 @@ func TestGetResourceVerb(t *testing.T) {
 
 	ra := NewResourceVerbAccessor(db)
 
-	expected := ResourceVerb{"11111111-2222-3333-4444-555555555555", "11111111-1111-1111-1111-111111111111", "test", "allows testing"}
-	columns := []string{"guid", "resourceGUID", "verb", "description"}
+	expected := ResourceVerb{"11111111-2222-3333-4444-555555555555", "11111111-1111-1111-1111-111111111111", "test", "allows testing", 1}
+	columns := []string{"guid", "resourceGUID", "verb", "description", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resourceVerbs WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE guid=(.)").
 		WithArgs("11111111-1111-1111-1111-111111111111").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,test,allows testing"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,test,allows testing,1"))
 	resourceVerb, err := ra.Get("11111111-1111-1111-1111-111111111111")
 	if err != nil {
 		t.Errorf("An unexpected error occurred while getting a resourceVerb %v", err)
@@ -45,14 +45,14 @@ func TestGetResourceVerbsByResource(t *testing.T) {
 	ra := NewResourceVerbAccessor(db)
 
 	expected := []ResourceVerb{
-		ResourceVerb{"11111111-2222-3333-4444-555555555555", "11111111-1111-1111-1111-111111111111", "test", "allows testing"},
-		ResourceVerb{"00000000-9999-8888-7777-666666666666", "11111111-1111-1111-1111-111111111111", "create", "allows creation of tests"},
+		ResourceVerb{"11111111-2222-3333-4444-555555555555", "11111111-1111-1111-1111-111111111111", "test", "allows testing", 1},
+		ResourceVerb{"00000000-9999-8888-7777-666666666666", "11111111-1111-1111-1111-111111111111", "create", "allows creation of tests", 1},
 	}
-	columns := []string{"guid", "resourceGUID", "verb", "description"}
+	columns := []string{"guid", "resourceGUID", "verb", "description", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resourceVerbs WHERE resourceGUID=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID=(.)").
 		WithArgs("11111111-1111-1111-1111-111111111111").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,test,allows testing\n00000000-9999-8888-7777-666666666666,11111111-1111-1111-1111-111111111111,create,allows creation of tests"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,test,allows testing,1\n00000000-9999-8888-7777-666666666666,11111111-1111-1111-1111-111111111111,create,allows creation of tests,1"))
 	resourceVerbs, err := ra.GetByResource("11111111-1111-1111-1111-111111111111")
 	if err != nil {
 		t.Errorf("An unexpected error occurred while getting a resourceVerb %v", err)
@@ -69,10 +69,59 @@ func TestGetResourceVerbsByResource(t *testing.T) {
 	}
 }
 
+func TestGetResourceVerbsByResources(t *testing.T) {
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred creating the mock database")
+		return
+	}
+
+	ra := NewResourceVerbAccessor(db)
+
+	expected := map[string][]ResourceVerb{
+		"11111111-1111-1111-1111-111111111111": []ResourceVerb{
+			ResourceVerb{"11111111-2222-3333-4444-555555555555", "11111111-1111-1111-1111-111111111111", "test", "allows testing", 1},
+		},
+		"22222222-2222-2222-2222-222222222222": []ResourceVerb{
+			ResourceVerb{"00000000-9999-8888-7777-666666666666", "22222222-2222-2222-2222-222222222222", "create", "allows creation of tests", 1},
+		},
+		"33333333-3333-3333-3333-333333333333": []ResourceVerb{},
+	}
+	columns := []string{"guid", "resourceGUID", "verb", "description", "version"}
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID IN \\((.),(.),(.)\\)").
+		WithArgs("11111111-1111-1111-1111-111111111111", "22222222-2222-2222-2222-222222222222", "33333333-3333-3333-3333-333333333333").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,test,allows testing,1\n00000000-9999-8888-7777-666666666666,22222222-2222-2222-2222-222222222222,create,allows creation of tests,1"))
+	resourceVerbs, err := ra.GetByResources([]string{"11111111-1111-1111-1111-111111111111", "22222222-2222-2222-2222-222222222222", "33333333-3333-3333-3333-333333333333"})
+	if err != nil {
+		t.Errorf("An unexpected error occurred while getting resourceVerbs %v", err)
+	}
+
+	if len(resourceVerbs) != len(expected) {
+		t.Errorf("Expected %v but got %v", expected, resourceVerbs)
+	}
+	for guid, verbs := range expected {
+		if len(resourceVerbs[guid]) != len(verbs) {
+			t.Errorf("Expected %v but got %v", expected, resourceVerbs)
+			continue
+		}
+		for i := 0; i < len(verbs); i++ {
+			if resourceVerbs[guid][i] != verbs[i] {
+				t.Errorf("Expected %v but got %v", expected, resourceVerbs)
+			}
+		}
+	}
+
+	if err := ra.DB.Close(); err != nil {
+		t.Errorf("An error occurred: %v", err)
+	}
+}
+
 func TestInsertResourceVerb(t *testing.T) {
 	NewGuid = func() string {
 		return "123def"
 	}
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred creating the mock database")
@@ -81,15 +130,31 @@ func TestInsertResourceVerb(t *testing.T) {
 
 	ra := NewResourceVerbAccessor(db)
 
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-1111-1111-1111-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).FromCSVString("1"))
 	sqlmock.ExpectPrepare()
 	sqlmock.ExpectExec("INSERT INTO resourceVerbs .+ VALUES .+").
-		WithArgs("123def", "11111111-1111-1111-1111-111111111111", "test", "allows testing").
+		WithArgs("123def", "11111111-1111-1111-1111-111111111111", "test", "allows testing", testTime, testTime).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET version=version\\+1, modified=(.) WHERE guid=\\(SELECT resourceGUID FROM resourceVerbs WHERE guid=(.)\\)").
+		WithArgs(testTime, "123def").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
-	err = ra.Add(ResourceVerb{ResourceGUID: "11111111-1111-1111-1111-111111111111", Verb: "test", Description: "allows testing"})
+	guid, err := ra.Add(ResourceVerb{ResourceGUID: "11111111-1111-1111-1111-111111111111", Verb: "test", Description: "allows testing"})
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resourceVerb:\n %s", err.Error())
 	}
+	if guid != "123def" {
+		t.Errorf("Expected guid 123def but got %v", guid)
+	}
 
 	if err := ra.DB.Close(); err != nil {
 		t.Errorf("An error occurred: %v", err)
@@ -97,6 +162,7 @@ func TestInsertResourceVerb(t *testing.T) {
 }
 
 func TestUpdateResourceVerb(t *testing.T) {
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred creating the mock database")
@@ -105,12 +171,25 @@ func TestUpdateResourceVerb(t *testing.T) {
 
 	ra := NewResourceVerbAccessor(db)
 
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,test,allows testing,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resourceVerbs SET description=(.), version=version\\+1, modified=(.) WHERE guid=(.) AND version=(.)").
+		WithArgs("This is a test", testTime, "11111111-2222-3333-4444-555555555555", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectExec("UPDATE resourceVerbs SET description=(.) WHERE guid=(.)").
-		WithArgs("This is a test", "11111111-2222-3333-4444-555555555555").
+	sqlmock.ExpectExec("UPDATE resources SET version=version\\+1, modified=(.) WHERE guid=\\(SELECT resourceGUID FROM resourceVerbs WHERE guid=(.)\\)").
+		WithArgs(testTime, "11111111-2222-3333-4444-555555555555").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
-	err = ra.Update("11111111-2222-3333-4444-555555555555", "This is a test")
+	err = ra.Update("11111111-2222-3333-4444-555555555555", "This is a test", 1)
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resourceVerb:\n %s", err.Error())
 	}
@@ -121,6 +200,7 @@ func TestUpdateResourceVerb(t *testing.T) {
 }
 
 func TestDeleteResourceVerb(t *testing.T) {
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred creating the mock database")
@@ -129,12 +209,33 @@ func TestDeleteResourceVerb(t *testing.T) {
 
 	ra := NewResourceVerbAccessor(db)
 
+	sqlmock.ExpectBegin()
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectExec("DELETE FROM resourceVerbs WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE guid=(.) AND deleted IS NULL").
 		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-1111-1111-1111-111111111111,test,allows testing,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resourceVerbs SET deleted=(.), modified=(.) WHERE guid=(.) AND version=(.) AND deleted IS NULL").
+		WithArgs(testTime, testTime, "11111111-2222-3333-4444-555555555555", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET version=version\\+1, modified=(.) WHERE guid=\\(SELECT resourceGUID FROM resourceVerbs WHERE guid=(.)\\)").
+		WithArgs(testTime, "11111111-2222-3333-4444-555555555555").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT verb FROM resourceVerbs WHERE guid=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"verb"}).FromCSVString("test"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT DISTINCT resourceGUID FROM grants WHERE verb=(.)").
+		WithArgs("test").
+		WillReturnRows(sqlmock.NewRows([]string{"resourceGUID"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
-	err = ra.Remove("11111111-2222-3333-4444-555555555555")
+	err = ra.Remove("11111111-2222-3333-4444-555555555555", 1)
 	if err != nil {
 		t.Error("An unexpected error occurred while getting a resourceVerb:\n %s", err.Error())
 	}
@@ -2,14 +2,88 @@ package apis
 
 import (
 	"database/sql"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	rules "github.com/byu-oit-ssengineering/tmt-resources/rules"
 	"os"
 )
 
 type Api struct {
-	DB *sql.DB
+	Store accessors.Store
 }
 
+// Creates the api with the storage selected by the STORAGE environment
+//   variable, waiting for its database to accept connections. Database
+//   backed storage must be fully migrated. The verb pattern is read from
+//   VERB_PATTERN. Calls to the store are timed, and the database's
+//   connection pool is added to the metrics. Fails with ErrNoActor unless
+//   Actor is set, as changes could not be audited.
 func New() (*Api, error) {
+	if Actor == nil {
+		return &Api{nil}, ErrNoActor
+	}
+	if err := rules.LoadVerbPattern(); err != nil {
+		return &Api{nil}, err
+	}
+
+	db, dialect, err := OpenDB()
+	if err != nil {
+		return &Api{nil}, err
+	}
+
+	// In-memory storage
+	if db == nil {
+		return &Api{timed(accessors.NewMemoryStore())}, nil
+	}
+
+	if err := waitForDB(db); err != nil {
+		db.Close()
+		return &Api{nil}, err
+	}
+
+	// A SQLite database is local to this service, so its schema is created
+	//   and kept up to date on startup.
+	if dialect.Name == accessors.SQLite.Name {
+		if _, err := accessors.MigrateUp(db, dialect); err != nil {
+			db.Close()
+			return &Api{nil}, err
+		}
+	}
+
+	if err := accessors.CheckSchema(db, dialect); err != nil {
+		db.Close()
+		return &Api{nil}, err
+	}
+
+	if err := registerDBStats(db); err != nil {
+		db.Close()
+		return &Api{nil}, err
+	}
+
+	return &Api{timed(accessors.NewSQLStore(db, dialect))}, nil
+}
+
+// Opens the database selected by the STORAGE environment variable:
+//   "mysql" (the default), "postgres", "sqlite" or "memory". For "memory"
+//   there is no database and the returned *sql.DB is nil.
+func OpenDB() (*sql.DB, accessors.Dialect, error) {
+	switch storage := os.Getenv("STORAGE"); storage {
+	case "", "mysql":
+	case "memory":
+		return nil, accessors.Dialect{}, nil
+	case "sqlite":
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = "resources.db"
+		}
+		db, err := accessors.OpenSQLite(path)
+		return db, accessors.SQLite, err
+	case "postgres":
+		db, err := sql.Open(accessors.Postgres.Name, os.Getenv("POSTGRES_DSN"))
+		return db, accessors.Postgres, err
+	default:
+		return nil, accessors.Dialect{}, fmt.Errorf("unknown STORAGE %q", storage)
+	}
 
 	// To connect to the database
 	user := os.Getenv("DB_USER")
@@ -21,10 +95,6 @@ func New() (*Api, error) {
 	// Create DSN
 	dsn := user + ":" + pass + "@tcp(" + host + ":" + port + ")/" + name
 
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return &Api{nil}, err
-	}
-
-	return &Api{db}, nil
+	db, err := sql.Open(accessors.MySQL.Name, dsn)
+	return db, accessors.MySQL, err
 }
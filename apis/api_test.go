@@ -0,0 +1,55 @@
+package apis
+
+import (
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+// Creation time of the resources in the mock database rows.
+var testTime = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// The tests make their changes as testActor.
+const testActor = "tester"
+
+func init() {
+	Actor = testUser
+}
+
+// Returns testActor as the verified user of every request.
+func testUser(c *eden.Context) (string, bool) {
+	return testActor, true
+}
+
+// Stops the accessors' clock at testTime.
+func stopClock() {
+	accessors.Now = func() time.Time {
+		return testTime
+	}
+}
+
+// Calls an api handler with the given request and returns the recorded
+//   response, so tests can check status codes and headers as well as the
+//   body.
+func callHandler(handler func(*eden.Context), method, target, contentType, body string, params httprouter.Params) *httptest.ResponseRecorder {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return callHandlerWithHeader(handler, method, target, header, body, params)
+}
+
+// Like callHandler, with the given request headers.
+func callHandlerWithHeader(handler func(*eden.Context), method, target string, header http.Header, body string, params httprouter.Params) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	handler(&eden.Context{Request: req, Response: w, Params: params})
+	return w
+}
@@ -0,0 +1,107 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"strconv"
+	"time"
+)
+
+// Returns the user eden.Authorize verified as making a request, and
+//   whether there is one. Changes are recorded in the audit log as made by
+//   this user, so it must be set for the service to start; a change
+//   requested without a verified user is refused.
+var Actor func(c *eden.Context) (string, bool)
+
+// New fails with ErrNoActor until Actor is set.
+var ErrNoActor = errors.New("apis: Actor must be set to return the user eden.Authorize verified")
+
+// Returns the store making changes as the user sending the request. If
+//   the request has no verified user, responds with a 401 and returns
+//   false.
+func (a *Api) as(c *eden.Context) (accessors.Store, bool) {
+	if Actor != nil {
+		if actor, ok := Actor(c); ok && actor != "" {
+			return a.Store.As(actor), true
+		}
+	}
+	respondFailure(c, 401, "The request has no verified user to record its changes under")
+	return nil, false
+}
+
+// Get the audit log, newest entry first.
+// GET /audit?actor=:actor&since=:time&until=:time&limit=:limit
+//   since and until are RFC 3339 times; since is inclusive and until
+//   exclusive.
+func (a *Api) GetAudit(c *eden.Context) {
+	q, ok := auditQuery(c)
+	if !ok {
+		return
+	}
+
+	entries, err := a.Store.Audit().Get(q)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving the audit log")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", entries})
+}
+
+// Get the changes made to a resource and to its verbs and type
+//   associations, newest first.
+// GET /resources/:guid/history?actor=:actor&since=:time&until=:time&limit=:limit
+func (a *Api) GetResourceHistory(c *eden.Context) {
+	q, ok := auditQuery(c)
+	if !ok {
+		return
+	}
+	if q.ResourceGUID, ok = guidParam(c, 0); !ok {
+		return
+	}
+
+	entries, err := a.Store.Audit().Get(q)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving the resource's history")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", entries})
+}
+
+// Parses the actor, time range and limit of an audit log request. Responds
+//   with a 400 and returns false if they are invalid.
+func auditQuery(c *eden.Context) (accessors.AuditQuery, bool) {
+	params := c.Request.URL.Query()
+	q := accessors.AuditQuery{Actor: params.Get("actor"), Limit: defaultPageSize}
+
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{
+		{"since", &q.Since},
+		{"until", &q.Until},
+	} {
+		if value := params.Get(param.name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				respondFailure(c, 400, fmt.Sprintf("%s must be an RFC 3339 time such as 2016-01-01T00:00:00Z", param.name))
+				return q, false
+			}
+			*param.value = t.UTC()
+		}
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		var err error
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit < 1 || q.Limit > maxPageSize {
+			respondFailure(c, 400, fmt.Sprintf("limit must be a number from 1 to %d", maxPageSize))
+			return q, false
+		}
+	}
+	return q, true
+}
@@ -0,0 +1,156 @@
+package apis
+
+import (
+	"encoding/json"
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"net/http/httptest"
+	"testing"
+)
+
+type testResponseAudit struct {
+	Status string
+	Data   []accessors.AuditEntry
+}
+
+// Returns the last digit of the guid of each entry in an audit response.
+func auditGuids(t *testing.T, w *httptest.ResponseRecorder) string {
+	var output testResponseAudit
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Errorf(err.Error())
+	}
+	guids := make([]string, 0)
+	for _, e := range output.Data {
+		guids = append(guids, e.Guid[len(e.Guid)-1:])
+	}
+	return fmt.Sprint(guids)
+}
+
+func TestAuditWithMemoryStore(t *testing.T) {
+	stopClock()
+	n, audits := 0, 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	accessors.NewAuditGuid = func() string {
+		audits++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", audits)
+	}
+	actor := "alice"
+	Actor = func(c *eden.Context) (string, bool) {
+		return actor, true
+	}
+	defer func() { Actor = testUser }()
+	api := &Api{accessors.NewMemoryStore()}
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000001"}}
+
+	// Every write is recorded, with the actor that made it
+	callHandler(api.InsertResource, "POST", "/resources", "application/json", `{"name": "board", "description": "a board", "apiEndpoint": "https://tmt.byu.edu/boards"}`, nil)
+	actor = "bob"
+	callHandler(api.UpdateResource, "PUT", "/resources/00000000-0000-0000-0000-000000000001", "application/json", `{"name": "whiteboard"}`, params)
+	actor = "alice"
+	callHandler(api.AddVerb, "POST", "/verbs", "application/json", `{"resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase", "description": "can erase"}`, nil)
+	actor = "bob"
+	if w := callHandler(api.DeleteResource, "DELETE", "/resources/00000000-0000-0000-0000-000000000001?cascade=true", "", "", params); w.Code != 200 {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The history of the resource includes its verbs, newest first
+	w := callHandler(api.GetResourceHistory, "GET", "/resources/00000000-0000-0000-0000-000000000001/history", "", "", params)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	var output testResponseAudit
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(output.Data) != 5 {
+		t.Fatalf("Expected 5 entries but got %v", output.Data)
+	}
+	expected := []struct {
+		actor, operation, entity, entityGUID string
+	}{
+		{"bob", accessors.OpDelete, accessors.EntityResource, "00000000-0000-0000-0000-000000000001"},
+		{"bob", accessors.OpDelete, accessors.EntityVerb, "00000000-0000-0000-0000-000000000002"},
+		{"alice", accessors.OpCreate, accessors.EntityVerb, "00000000-0000-0000-0000-000000000002"},
+		{"bob", accessors.OpUpdate, accessors.EntityResource, "00000000-0000-0000-0000-000000000001"},
+		{"alice", accessors.OpCreate, accessors.EntityResource, "00000000-0000-0000-0000-000000000001"},
+	}
+	for i, e := range output.Data {
+		if e.Actor != expected[i].actor || e.Operation != expected[i].operation || e.Entity != expected[i].entity || e.EntityGUID != expected[i].entityGUID || !e.Time.Equal(testTime) {
+			t.Errorf("Expected %v but got %v", expected[i], e)
+		}
+	}
+
+	// Updates record the values on both sides
+	var before, after accessors.Resource
+	if err := json.Unmarshal(output.Data[3].Before, &before); err != nil {
+		t.Errorf(err.Error())
+	}
+	if err := json.Unmarshal(output.Data[3].After, &after); err != nil {
+		t.Errorf(err.Error())
+	}
+	if before.Name != "board" || after.Name != "whiteboard" || after.Description != "a board" {
+		t.Errorf("Expected the name to change from board to whiteboard but got %v and %v", before, after)
+	}
+	if output.Data[4].Before != nil || output.Data[0].After != nil {
+		t.Errorf("Expected no values before a create or after a delete but got %s and %s", output.Data[4].Before, output.Data[0].After)
+	}
+
+	// The audit log is filtered by actor and time
+	for _, test := range []struct {
+		target   string
+		expected string
+	}{
+		{"/audit", "[5 4 3 2 1]"},
+		{"/audit?actor=alice", "[3 1]"},
+		{"/audit?actor=bob&limit=2", "[5 4]"},
+		{"/audit?since=2016-01-01T00:00:00Z", "[5 4 3 2 1]"},
+		{"/audit?until=2016-01-01T00:00:00Z", "[]"},
+		{"/audit?since=2015-12-31T17:00:00-07:00&until=2016-01-01T00:00:01Z", "[5 4 3 2 1]"},
+	} {
+		w := callHandler(api.GetAudit, "GET", test.target, "", "", nil)
+		if w.Code != 200 {
+			t.Errorf("Expected 200 for %s but got %d", test.target, w.Code)
+		}
+		if guids := auditGuids(t, w); guids != test.expected {
+			t.Errorf("Expected %v for %s but got %v", test.expected, test.target, guids)
+		}
+	}
+
+	for _, target := range []string{"/audit?since=yesterday", "/audit?until=2016-01-01", "/audit?limit=0", "/audit?limit=1001"} {
+		if w := callHandler(api.GetAudit, "GET", target, "", "", nil); w.Code != 400 {
+			t.Errorf("Expected 400 for %s but got %d", target, w.Code)
+		}
+	}
+}
+
+func TestActor(t *testing.T) {
+	defer func() { Actor = testUser }()
+	api := &Api{accessors.NewMemoryStore()}
+	body := `{"name": "board", "description": "a board", "apiEndpoint": "https://tmt.byu.edu/boards"}`
+
+	// Changes are refused without a verified user
+	for _, actor := range []func(c *eden.Context) (string, bool){
+		nil,
+		func(c *eden.Context) (string, bool) { return "", false },
+		func(c *eden.Context) (string, bool) { return "", true },
+	} {
+		Actor = actor
+		if w := callHandler(api.InsertResource, "POST", "/resources", "application/json", body, nil); w.Code != 401 {
+			t.Errorf("Expected 401 but got %d", w.Code)
+		}
+	}
+	if entries, _ := api.Store.Audit().Get(accessors.AuditQuery{}); len(entries) != 0 {
+		t.Errorf("Expected no audit entries but got %v", entries)
+	}
+
+	// The service does not start without a way to tell who the user is
+	Actor = nil
+	if _, err := New(); err != ErrNoActor {
+		t.Errorf("Expected %v but got %v", ErrNoActor, err)
+	}
+}
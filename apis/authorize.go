@@ -0,0 +1,104 @@
+package apis
+
+import (
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"mime"
+)
+
+// Most requests decided by one call to POST /authorize/batch.
+const maxBatchSize = 100
+
+// The body of POST /authorize/batch.
+type accessBatch struct {
+	Requests []accessors.AccessRequest `json:"requests"`
+}
+
+// Reads a JSON request body into v with readJSON. If the body is not JSON
+//   or cannot be read the client is sent a 400 or 415 response naming the
+//   problem, and false is returned.
+func readAccessJSON(c *eden.Context, v interface{}) bool {
+	mediaType, _, err := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	if err != nil && c.Request.Header.Get("Content-Type") != "" {
+		respondFailure(c, 400, "Malformed Content-Type header")
+		return false
+	}
+	if mediaType != "application/json" {
+		respondFailure(c, 415, fmt.Sprintf("Unsupported Content-Type %q; use application/json", mediaType))
+		return false
+	}
+
+	if err := readJSON(c, v); err != nil {
+		respondFailure(c, 400, err.Error())
+		return false
+	}
+	return true
+}
+
+// Checks that a request names a resource, a verb and someone to decide
+//   for. If not the client is sent a 400 response, and false is returned.
+func validAccessRequest(c *eden.Context, req accessors.AccessRequest) bool {
+	switch {
+	case req.ResourceGUID == "":
+		respondFailure(c, 400, `Missing field "resourceGUID"`)
+	case req.Verb == "":
+		respondFailure(c, 400, `Missing field "verb"`)
+	case req.Principal == "" && len(req.Roles) == 0:
+		respondFailure(c, 400, `A request needs a "principal", "roles" or both`)
+	default:
+		return true
+	}
+	return false
+}
+
+// Decide whether a principal, holding the given roles, may perform a verb
+//   on a resource.
+// POST /authorize with a JSON body {"principal", "roles", "resourceGUID", "verb"}
+//   The verb may be the resource's own or inherited. Responds with
+//   {"decision": "allow" or "deny", "grant", "reason"}; an allowed request
+//   comes with the grant that allows it.
+func (a *Api) Authorize(c *eden.Context) {
+	var req accessors.AccessRequest
+	if !readAccessJSON(c, &req) || !validAccessRequest(c, req) {
+		return
+	}
+
+	decisions, err := a.Store.Grants().Authorize([]accessors.AccessRequest{req})
+	if err != nil {
+		respondError(c, err, "An error occurred while deciding the request")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", decisions[0]})
+}
+
+// Decide many requests at once.
+// POST /authorize/batch with a JSON body {"requests": [...]}
+//   Each request is as for POST /authorize; at most 100 are accepted.
+//   Responds with a decision per request, in order.
+func (a *Api) AuthorizeBatch(c *eden.Context) {
+	var batch accessBatch
+	if !readAccessJSON(c, &batch) {
+		return
+	}
+	if len(batch.Requests) > maxBatchSize {
+		respondFailure(c, 400, fmt.Sprintf("A batch holds at most %d requests", maxBatchSize))
+		return
+	}
+	for _, req := range batch.Requests {
+		if !validAccessRequest(c, req) {
+			return
+		}
+	}
+
+	decisions, err := a.Store.Grants().Authorize(batch.Requests)
+	if err != nil {
+		respondError(c, err, "An error occurred while deciding the requests")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", decisions})
+}
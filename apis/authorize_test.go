@@ -0,0 +1,95 @@
+package apis
+
+import (
+	"encoding/json"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"strings"
+	"testing"
+)
+
+type testResponseDecision struct {
+	Status string
+	Data   accessors.Decision
+}
+
+type testResponseDecisions struct {
+	Status string
+	Data   []accessors.Decision
+}
+
+func TestAuthorizeWithMemoryStore(t *testing.T) {
+	stopClock()
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	api.Store.Resources().Insert(accessors.Resource{Name: "whiteboard", APIEndpoint: "https://tmt.byu.edu/whiteboards"})
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	api.Store.Grants().Add(accessors.Grant{Role: "staff", ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase"})
+
+	// An allowed request comes with its grant
+	w := callHandler(api.Authorize, "POST", "/authorize", "application/json", `{"principal": "jdoe", "roles": ["staff"], "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"}`, nil)
+	var decision testResponseDecision
+	if err := json.Unmarshal(w.Body.Bytes(), &decision); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if w.Code != 200 || decision.Data.Decision != "allow" || decision.Data.Grant == nil || decision.Data.Grant.Guid != "00000000-0000-0000-0000-000000000003" {
+		t.Errorf("Expected grant 00000000-0000-0000-0000-000000000003 to allow the request but got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A denied one says why
+	w = callHandler(api.Authorize, "POST", "/authorize", "application/json", `{"principal": "jdoe", "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"}`, nil)
+	if !strings.Contains(w.Body.String(), `"decision":"deny"`) || strings.Contains(w.Body.String(), `"grant"`) {
+		t.Errorf("Expected a deny without a grant but got %s", w.Body.String())
+	}
+
+	for _, c := range []struct {
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"application/x-www-form-urlencoded", "principal=jdoe&resourceGUID=00000000-0000-0000-0000-000000000001&verb=erase", 415},
+		{"application/json", `{"principal": "jdoe", "verb": "erase"}`, 400},
+		{"application/json", `{"principal": "jdoe", "resourceGUID": "00000000-0000-0000-0000-000000000001"}`, 400},
+		{"application/json", `{"resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"}`, 400},
+		{"application/json", `{"principal": "jdoe", "group": "staff", "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"}`, 400},
+	} {
+		if w := callHandler(api.Authorize, "POST", "/authorize", c.contentType, c.body, nil); w.Code != c.expected {
+			t.Errorf("Expected %d for %s but got %d: %s", c.expected, c.body, w.Code, w.Body.String())
+		}
+	}
+
+	// Bodies are read as they are for the other endpoints
+	w = callHandler(api.Authorize, "POST", "/authorize", "application/json", `{"principal": "jdoe", "group": "staff", "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"}`, nil)
+	if !strings.Contains(w.Body.String(), `Unknown field \"group\"`) {
+		t.Errorf("Expected the unknown field to be named but got %s", w.Body.String())
+	}
+
+	// A batch is decided in order
+	w = callHandler(api.AuthorizeBatch, "POST", "/authorize/batch", "application/json", `{"requests": [
+		{"roles": ["staff"], "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"},
+		{"roles": ["staff"], "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "paint"},
+		{"roles": ["staff"], "resourceGUID": "99999999-9999-9999-9999-999999999999", "verb": "erase"}
+	]}`, nil)
+	var decisions testResponseDecisions
+	if err := json.Unmarshal(w.Body.Bytes(), &decisions); err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := []string{"allow granted", "deny the resource has no such verb", "deny the resource does not exist"}
+	got := make([]string, 0)
+	for _, d := range decisions.Data {
+		got = append(got, d.Decision+" "+d.Reason)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v but got %v", expected, got)
+	}
+
+	// Batches are limited in size
+	requests := strings.TrimSuffix(strings.Repeat(`{"roles": ["staff"], "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"},`, maxBatchSize+1), ",")
+	if w := callHandler(api.AuthorizeBatch, "POST", "/authorize/batch", "application/json", `{"requests": [`+requests+`]}`, nil); w.Code != 400 {
+		t.Errorf("Expected 400 but got %d", w.Code)
+	}
+}
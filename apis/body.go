@@ -0,0 +1,189 @@
+package apis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Largest request body accepted by the write endpoints.
+const maxBodySize = 1 << 20
+
+// A field accepted in the body of a write request. Form encoded bodies use
+//   the form name, JSON bodies use the key of the matching JSON tag.
+type field struct {
+	form string
+	json string
+}
+
+// Body fields of the resource endpoints.
+var resourceFields = []field{
+	{"name", "name"},
+	{"description", "description"},
+	{"api", "apiEndpoint"},
+}
+
+// Body fields of the verb endpoints.
+var verbFields = []field{
+	{"resourceGUID", "resourceGUID"},
+	{"verb", "verb"},
+	{"description", "description"},
+}
+
+// Body fields of PUT /verbs/:guid; only the description can change.
+var verbUpdateFields = []field{
+	{"description", "description"},
+}
+
+// Body fields of the resource type endpoints.
+var typeFields = []field{
+	{"resource", "resource"},
+	{"type", "type"},
+}
+
+// Body fields of the grant endpoints. A grant has a principal or a role,
+//   not both, so only the others are required.
+var grantFields = []field{
+	{"principal", "principal"},
+	{"role", "role"},
+	{"resourceGUID", "resourceGUID"},
+	{"verb", "verb"},
+}
+
+// The grantFields every new grant needs.
+var grantRequiredFields = grantFields[2:]
+
+// Reads the given fields from a form encoded or JSON request body into a
+//   map keyed by JSON name; fields that were not sent are absent from the
+//   map. If the body cannot be read the client is sent a 400 or 415
+//   response naming the problem, and ok is false.
+func readBody(c *eden.Context, fields []field) (body map[string]string, ok bool) {
+	contentType := c.Request.Header.Get("Content-Type")
+	mediaType := ""
+	if contentType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(contentType); err != nil {
+			respondFailure(c, 400, "Malformed Content-Type header")
+			return nil, false
+		}
+	}
+
+	var err error
+	switch mediaType {
+	case "application/json":
+		body, err = readJSONFields(c, fields)
+	case "", "application/x-www-form-urlencoded":
+		body, err = readForm(c, fields)
+	default:
+		respondFailure(c, 415, fmt.Sprintf("Unsupported Content-Type %q; use application/json or application/x-www-form-urlencoded", mediaType))
+		return nil, false
+	}
+
+	if err != nil {
+		respondFailure(c, 400, err.Error())
+		return nil, false
+	}
+	return body, true
+}
+
+// Reads a single JSON value from the request body into v, rejecting
+//   unknown keys of structs and trailing data.
+func readJSON(c *eden.Context, v interface{}) error {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(c.Response, c.Request.Body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("Unable to read request body: %v", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if key := strings.TrimPrefix(err.Error(), "json: unknown field "); key != err.Error() {
+			return fmt.Errorf("Unknown field %s", key)
+		}
+		return fmt.Errorf("Request body must be a JSON object: %v", err)
+	}
+	if dec.More() {
+		return fmt.Errorf("Request body must contain a single JSON object")
+	}
+	return nil
+}
+
+// Reads a JSON object, rejecting unknown keys, non-string values and
+//   trailing data.
+func readJSONFields(c *eden.Context, fields []field) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := readJSON(c, &raw); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, f := range fields {
+		known[f.json] = true
+	}
+
+	// Report problems in a stable order
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	body := make(map[string]string)
+	for _, key := range keys {
+		if !known[key] {
+			return nil, fmt.Errorf("Unknown field %q", key)
+		}
+		var value string
+		if err := json.Unmarshal(raw[key], &value); err != nil {
+			return nil, fmt.Errorf("Field %q must be a string", key)
+		}
+		body[key] = value
+	}
+	return body, nil
+}
+
+// Reads a form encoded body, rejecting fields given more than once.
+func readForm(c *eden.Context, fields []field) (map[string]string, error) {
+	if err := c.Request.ParseForm(); err != nil {
+		return nil, fmt.Errorf("Unable to parse form: %v", err)
+	}
+
+	body := make(map[string]string)
+	for _, f := range fields {
+		values, ok := c.Request.Form[f.form]
+		if !ok {
+			continue
+		}
+		if len(values) != 1 {
+			return nil, fmt.Errorf("Field %q must be given exactly once", f.form)
+		}
+		body[f.json] = values[0]
+	}
+	return body, nil
+}
+
+// Checks that every named field is present in body. If one is missing the
+//   client is sent a 400 response naming it, and false is returned.
+func requireFields(c *eden.Context, body map[string]string, fields []field) bool {
+	for _, f := range fields {
+		if _, ok := body[f.json]; !ok {
+			respondFailure(c, 400, fmt.Sprintf("Missing field %q", fieldName(c, f)))
+			return false
+		}
+	}
+	return true
+}
+
+// Name of a field as the client would have sent it.
+func fieldName(c *eden.Context, f field) string {
+	if mediaType, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type")); mediaType == "application/json" {
+		return f.json
+	}
+	return f.form
+}
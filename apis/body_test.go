@@ -0,0 +1,100 @@
+package apis
+
+import (
+	"encoding/json"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"strings"
+	"testing"
+)
+
+func TestInsertResourceJSON(t *testing.T) {
+	stopClock()
+	accessors.NewGuid = func() string {
+		return "11111111-2222-3333-4444-555555555555"
+	}
+	api := &Api{accessors.NewMemoryStore()}
+
+	w := callHandler(api.InsertResource, "POST", "/resources", "application/json; charset=utf-8",
+		`{"name": "test", "description": "This is a test", "apiEndpoint": "https://tmt.byu.edu/resources"}`, nil)
+	if w.Code != 201 {
+		t.Fatalf("Expected 201 but got %d: %s", w.Code, w.Body.String())
+	}
+
+	expected := accessors.Resource{"11111111-2222-3333-4444-555555555555", "test", "This is a test", "https://tmt.byu.edu/resources", testTime, testTime, 1, nil}
+	resource, err := api.Store.Resources().Get("11111111-2222-3333-4444-555555555555")
+	if err != nil {
+		t.Errorf("An unexpected error occurred getting the resource: %v", err)
+	}
+	if !expected.Equals(resource) {
+		t.Errorf("Expected %v but got %v", expected, resource)
+	}
+}
+
+func TestUpdateResourceJSON(t *testing.T) {
+	stopClock()
+	accessors.NewGuid = func() string {
+		return "11111111-2222-3333-4444-555555555555"
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	api.Store.Resources().Insert(accessors.Resource{Name: "test", Description: "This is a test", APIEndpoint: "https://tmt.byu.edu/resources"})
+
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "11111111-2222-3333-4444-555555555555"}}
+	w := callHandler(api.UpdateResource, "PUT", "/resources/11111111-2222-3333-4444-555555555555", "application/json", `{"description": "changed"}`, params)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+
+	expected := accessors.Resource{"11111111-2222-3333-4444-555555555555", "test", "changed", "https://tmt.byu.edu/resources", testTime, testTime, 2, nil}
+	resource, _ := api.Store.Resources().Get("11111111-2222-3333-4444-555555555555")
+	if !expected.Equals(resource) {
+		t.Errorf("Expected %v but got %v", expected, resource)
+	}
+}
+
+func TestWriteBodyErrors(t *testing.T) {
+	api := &Api{accessors.NewMemoryStore()}
+
+	tests := []struct {
+		handler     func(*eden.Context)
+		contentType string
+		body        string
+		code        int
+		message     string
+	}{
+		{api.InsertResource, "text/plain", "name=test", 415, "Unsupported Content-Type"},
+		{api.InsertResource, "application/x-www-form-urlencoded", "name=test&name=again&description=a%20test&api=x", 400, `Field "name" must be given exactly once`},
+		{api.InsertResource, "application/json", `{"name": "test", "colour": "red"}`, 400, `Unknown field "colour"`},
+		{api.InsertResource, "application/json", `{"name": 7}`, 400, `Field "name" must be a string`},
+		{api.InsertResource, "application/json", `["name"]`, 400, "must be a JSON object"},
+		{api.InsertResource, "application/json", `{"name": "a"} {"name": "b"}`, 400, "single JSON object"},
+		{api.AddGrant, "application/json", `{"principal": "jdoe", "resourceGUID": "11111111-2222-3333-4444-555555555555"}`, 400, `Missing field "verb"`},
+	}
+
+	for _, test := range tests {
+		w := callHandler(test.handler, "POST", "/", test.contentType, test.body, nil)
+		if w.Code != test.code {
+			t.Errorf("%s %s: expected %d but got %d", test.contentType, test.body, test.code, w.Code)
+		}
+		var output eden.Response
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Errorf("%s %s: %v", test.contentType, test.body, err)
+			continue
+		}
+		data, _ := output.Data.(map[string]interface{})
+		if message, _ := data["message"].(string); !strings.Contains(message, test.message) {
+			t.Errorf("%s %s: expected an error containing %q but got %v", test.contentType, test.body, test.message, output.Data)
+		}
+	}
+}
+
+func TestUpdateVerbRejectsOtherFields(t *testing.T) {
+	api := &Api{accessors.NewMemoryStore()}
+
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "11111111-2222-3333-4444-555555555555"}}
+	w := callHandler(api.UpdateVerb, "PUT", "/verbs/11111111-2222-3333-4444-555555555555", "application/json", `{"verb": "edit"}`, params)
+	if w.Code != 400 {
+		t.Errorf("Expected 400 but got %d", w.Code)
+	}
+}
@@ -0,0 +1,106 @@
+package apis
+
+import (
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"mime"
+	"net/http"
+)
+
+// Largest catalog accepted by POST /import.
+const maxCatalogSize = 32 << 20
+
+// Body of the 400 response to an invalid catalog, listing every problem.
+type invalidCatalog struct {
+	Message  string   `json:"message"`
+	Problems []string `json:"problems"`
+}
+
+// Export every resource outside the trash with its verbs, and the type
+//   associations between them, as a file for POST /import.
+// GET /export?format=json|yaml|csv
+func (a *Api) ExportCatalog(c *eden.Context) {
+	format := c.Request.URL.Query().Get("format")
+	if format == "" {
+		format = FormatJSON
+	}
+	contentType, ok := formatTypes[format]
+	if !ok {
+		respondFailure(c, 400, "format must be json, yaml or csv")
+		return
+	}
+
+	catalog, err := a.Store.Catalog().Export()
+	if err != nil {
+		respondError(c, err, "An error occurred while exporting resources")
+		return
+	}
+	data, err := encodeCatalog(format, catalog)
+	if err != nil {
+		respondError(c, err, "An error occurred while exporting resources")
+		return
+	}
+
+	// Respond with the file
+	c.Response.Header().Set("Content-Type", contentType)
+	c.Response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "resources."+format))
+	c.Response.WriteHeader(200)
+	c.Response.Write(data)
+}
+
+// Import a catalog exported by GET /export in one transaction.
+// POST /import?mode=merge|replace
+//   The Content-Type of the body gives its format: application/json,
+//   application/x-yaml or text/csv. merge (the default) creates and
+//   updates what is in the catalog; replace also moves everything else to
+//   the trash. Nothing is written unless the whole catalog is valid,
+//   including the field rules of the resource and verb requests; if it is
+//   not, the response is a 400 listing every problem. Every change is
+//   audited as made by the caller.
+func (a *Api) ImportCatalog(c *eden.Context) {
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	mode := c.Request.URL.Query().Get("mode")
+	if mode == "" {
+		mode = accessors.ImportMerge
+	}
+	if mode != accessors.ImportMerge && mode != accessors.ImportReplace {
+		respondFailure(c, 400, "mode must be merge or replace")
+		return
+	}
+
+	format := FormatJSON
+	if contentType := c.Request.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			respondFailure(c, 400, "Malformed Content-Type header")
+			return
+		}
+		var ok bool
+		if format, ok = formatOfMediaType(mediaType); !ok {
+			respondFailure(c, 415, fmt.Sprintf("Unsupported Content-Type %q; use application/json, application/x-yaml or text/csv", mediaType))
+			return
+		}
+	}
+
+	catalog, err := ReadCatalog(http.MaxBytesReader(c.Response, c.Request.Body, maxCatalogSize), format)
+	if err != nil {
+		respondFailure(c, 400, err.Error())
+		return
+	}
+
+	result, err := store.Catalog().Import(catalog, mode)
+	if e, ok := err.(*accessors.CatalogError); ok {
+		respondFailure(c, 400, invalidCatalog{"The catalog is invalid", e.Problems})
+		return
+	} else if err != nil {
+		respondError(c, err, "An error occurred while importing resources")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", result})
+}
@@ -0,0 +1,147 @@
+package apis
+
+import (
+	"encoding/json"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"testing"
+)
+
+type testResponseImport struct {
+	Status string
+	Data   accessors.ImportResult
+}
+
+type testResponseProblems struct {
+	Status string
+	Data   invalidCatalog
+}
+
+// Returns an api whose store holds a whiteboard with a verb, and a room
+//   that is the whiteboard's type.
+func newCatalogApi() *Api {
+	stopClock()
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	api.Store.Resources().Insert(accessors.Resource{Name: "whiteboard", Description: "a whiteboard, with \"quotes\"", APIEndpoint: "https://tmt.byu.edu/whiteboards"})
+	api.Store.Resources().Insert(accessors.Resource{Name: "room", Description: "a room,\nwith lines", APIEndpoint: "https://tmt.byu.edu/rooms"})
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	api.Store.Types().Insert("00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002")
+	return api
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		format      string
+		contentType string
+	}{
+		{"json", "application/json"},
+		{"yaml", "application/x-yaml"},
+		{"csv", "text/csv"},
+	} {
+		source := newCatalogApi()
+		w := callHandler(source.ExportCatalog, "GET", "/export?format="+test.format, "", "", nil)
+		if w.Code != 200 || w.Header().Get("Content-Type") != test.contentType {
+			t.Errorf("Expected 200 with %s but got %d and %s", test.contentType, w.Code, w.Header().Get("Content-Type"))
+		}
+
+		// Importing into an empty store copies everything, guids included
+		target := &Api{accessors.NewMemoryStore()}
+		imported := callHandler(target.ImportCatalog, "POST", "/import", test.contentType, w.Body.String(), nil)
+		if imported.Code != 200 {
+			t.Fatalf("Expected 200 for %s but got %d: %s", test.format, imported.Code, imported.Body.String())
+		}
+		var output testResponseImport
+		if err := json.Unmarshal(imported.Body.Bytes(), &output); err != nil {
+			t.Errorf(err.Error())
+		}
+		if expected := (accessors.ImportResult{4, 0, 0, 0}); output.Data != expected {
+			t.Errorf("Expected %v for %s but got %v", expected, test.format, output.Data)
+		}
+		if entries, _ := target.Store.Audit().Get(accessors.AuditQuery{Actor: testActor}); len(entries) != 4 {
+			t.Errorf("Expected 4 changes audited for %s but got %v", test.format, entries)
+		}
+		expected, _ := source.Store.Catalog().Export()
+		copied, _ := target.Store.Catalog().Export()
+		if fmt.Sprint(copied) != fmt.Sprint(expected) {
+			t.Errorf("Expected %v for %s but got %v", expected, test.format, copied)
+		}
+
+		// Importing again changes nothing
+		imported = callHandler(target.ImportCatalog, "POST", "/import?mode=replace", test.contentType, w.Body.String(), nil)
+		if err := json.Unmarshal(imported.Body.Bytes(), &output); err != nil {
+			t.Errorf(err.Error())
+		}
+		if expected := (accessors.ImportResult{0, 0, 4, 0}); output.Data != expected {
+			t.Errorf("Expected %v for %s but got %v", expected, test.format, output.Data)
+		}
+	}
+}
+
+func TestImportInvalidCatalog(t *testing.T) {
+	api := newCatalogApi()
+
+	// Every problem is listed and nothing is written
+	w := callHandler(api.ImportCatalog, "POST", "/import", "application/json", `{"resources": [
+		{"guid": "00000000-0000-0000-0000-000000000101", "name": "", "verbs": [{"guid": "00000000-0000-0000-0000-000000000101", "verb": "dim"}]}
+	], "types": [{"guid": "00000000-0000-0000-0000-000000000102", "resourceGUID": "00000000-0000-0000-0000-000000000101"}]}`, nil)
+	if w.Code != 400 {
+		t.Fatalf("Expected 400 but got %d: %s", w.Code, w.Body.String())
+	}
+	var output testResponseProblems
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Errorf(err.Error())
+	}
+	if len(output.Data.Problems) != 6 {
+		t.Errorf("Expected 6 problems but got %v", output.Data.Problems)
+	}
+	if resources, _ := api.Store.Resources().GetAll(); len(resources) != 2 {
+		t.Errorf("Expected 2 resources but got %v", resources)
+	}
+
+	// Replacing must not leave type associations dangling
+	w = callHandler(api.ImportCatalog, "POST", "/import?mode=replace", "application/json", `{"resources": [], "types": [{"guid": "00000000-0000-0000-0000-000000000004", "resourceGUID": "00000000-0000-0000-0000-000000000001", "type": "00000000-0000-0000-0000-000000000002"}]}`, nil)
+	if w.Code != 400 {
+		t.Errorf("Expected 400 but got %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, test := range []struct {
+		target      string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"/import?mode=overwrite", "application/json", `{}`, 400},
+		{"/import", "application/xml", `<resources/>`, 415},
+		{"/import", "application/json", `{"resources": [], "extra": true}`, 400},
+		{"/import", "application/json", `{} {}`, 400},
+		{"/import", "application/x-yaml", "resources: []\nextra: true\n", 400},
+		{"/import", "text/csv", "kind,guid,colour\n", 400},
+		{"/import", "text/csv", "kind,guid\nlamp,00000000-0000-0000-0000-000000000101\n", 400},
+		{"/import", "text/csv", "kind,guid,resourceGUID,verb\nverb,00000000-0000-0000-0000-000000000101,00000000-0000-0000-0000-000000000999,dim\n", 400},
+		{"/import", "application/json", `{"resources": [{"guid": "101", "name": "lamp", "description": "a lamp", "apiEndpoint": "https://tmt.byu.edu/lamps"}]}`, 400},
+		{"/import", "application/json", `{"resources": [{"guid": "00000000-0000-0000-0000-000000000101", "name": "lamp", "description": "a lamp", "apiEndpoint": "tmt.byu.edu/lamps"}]}`, 400},
+		{"/import", "application/json", `{"resources": [{"guid": "00000000-0000-0000-0000-000000000101", "name": "lamp", "description": "a lamp", "apiEndpoint": "https://tmt.byu.edu/lamps", "verbs": [{"guid": "00000000-0000-0000-0000-000000000102", "verb": "Turn On", "description": "can turn on"}]}]}`, 400},
+		{"/import", "text/csv", "guid,kind,name,description,apiEndpoint\n00000000-0000-0000-0000-000000000101,resource,lamp,a lamp,https://tmt.byu.edu/lamps\n", 200},
+	} {
+		if w := callHandler(api.ImportCatalog, "POST", test.target, test.contentType, test.body, nil); w.Code != test.expected {
+			t.Errorf("Expected %d for %s but got %d: %s", test.expected, test.body, w.Code, w.Body.String())
+		}
+	}
+
+	if w := callHandler(api.ExportCatalog, "GET", "/export?format=xml", "", "", nil); w.Code != 400 {
+		t.Errorf("Expected 400 but got %d", w.Code)
+	}
+}
+
+func TestFormatOfFile(t *testing.T) {
+	for name, expected := range map[string]string{"resources.json": "json", "prod.YML": "yaml", "dev.yaml": "yaml", "catalog.csv": "csv", "resources": ""} {
+		if format, _ := FormatOfFile(name); format != expected {
+			t.Errorf("Expected %q for %s but got %q", expected, name, format)
+		}
+	}
+}
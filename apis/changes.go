@@ -0,0 +1,126 @@
+package apis
+
+import (
+	"encoding/base64"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A page of the change feed, with the token to ask for the next changes.
+type changeFeed struct {
+	accessors.Changes
+	Next string `json:"next"`
+}
+
+// Returns the sync token of a point in time. The token also names the
+//   changes in the commit window before it that the client has seen, so
+//   that they are not sent again.
+func syncToken(t time.Time, seen []string) string {
+	fields := append([]string{t.UTC().Format(time.RFC3339Nano)}, seen...)
+	return base64.RawURLEncoding.EncodeToString([]byte(strings.Join(fields, " ")))
+}
+
+// Returns the point in time of a sync token and the changes it has seen.
+func parseSyncToken(token string) (time.Time, map[string]bool, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return time.Time{}, nil, false
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return time.Time{}, nil, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, fields[0])
+	if err != nil {
+		return time.Time{}, nil, false
+	}
+	seen := make(map[string]bool)
+	for _, key := range fields[1:] {
+		seen[key] = true
+	}
+	return t, seen, true
+}
+
+// Names a change of an entity in a sync token.
+func changeKey(guid string, modified time.Time) string {
+	return guid + "@" + strconv.FormatInt(modified.UnixNano(), 10)
+}
+
+// Drops the changes a client has already seen and returns the keys of
+//   those in the commit window before the newest change, for its next
+//   token.
+func dedupChanges(changes *accessors.Changes, seen map[string]bool) []string {
+	window := changes.Last.Add(-accessors.CommitWindow)
+	recent := make([]string, 0)
+	remember := func(key string, modified time.Time) {
+		if modified.After(window) {
+			recent = append(recent, key)
+		}
+	}
+
+	resources := make([]accessors.ResourceChange, 0, len(changes.Resources))
+	for _, change := range changes.Resources {
+		key := changeKey(change.Resource.Guid, change.Resource.Modified)
+		remember(key, change.Resource.Modified)
+		if !seen[key] {
+			resources = append(resources, change)
+		}
+	}
+	verbs := make([]accessors.VerbChange, 0, len(changes.Verbs))
+	for _, change := range changes.Verbs {
+		key := changeKey(change.Verb.Guid, change.Modified)
+		remember(key, change.Modified)
+		if !seen[key] {
+			verbs = append(verbs, change)
+		}
+	}
+	changes.Resources, changes.Verbs = resources, verbs
+	return recent
+}
+
+// Gets the resources and verbs created, updated or deleted since a sync
+//   token, oldest first.
+// GET /resources/changes?since=:token
+//   Without a token every resource and verb is returned, trashed ones as
+//   deleted. The response holds a token to pass as since next time; the
+//   ETag of GET /resources is a token too. Changes that commit late are
+//   still sent, as long as they commit within the commit window.
+//   Deletions are only known while they are in the trash, so a token older
+//   than TRASH_RETENTION gets a 410 and the client must download the whole
+//   catalog again.
+func (a *Api) GetChanges(c *eden.Context) {
+	ca := a.Store.Changes()
+
+	var since time.Time
+	var seen map[string]bool
+	if token := c.Request.URL.Query().Get("since"); token != "" {
+		var ok bool
+		if since, seen, ok = parseSyncToken(token); !ok {
+			respondFailure(c, 400, "Invalid sync token")
+			return
+		}
+
+		retention, err := durationEnv("TRASH_RETENTION", defaultTrashRetention)
+		if err != nil {
+			respondError(c, err, "An error occurred while retrieving changes")
+			return
+		}
+		if !since.IsZero() && since.Before(accessors.Now().Add(-retention)) {
+			respondFailure(c, 410, "The sync token has expired; get all resources again")
+			return
+		}
+	}
+
+	changes, err := ca.Since(since)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving changes")
+		return
+	}
+	recent := dedupChanges(&changes, seen)
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", changeFeed{changes, syncToken(changes.Last, recent)}})
+}
@@ -0,0 +1,202 @@
+package apis
+
+import (
+	"encoding/json"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+type testResponseChanges struct {
+	Status string
+	Data   struct {
+		Resources []accessors.ResourceChange
+		Verbs     []accessors.VerbChange
+		Next      string
+	}
+}
+
+// Returns the changes in a change feed response.
+func changeFeedGuids(t *testing.T, w *httptest.ResponseRecorder) (string, string) {
+	var output testResponseChanges
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Errorf(err.Error())
+	}
+	guids := make([]string, 0)
+	for _, c := range output.Data.Resources {
+		guids = append(guids, c.Resource.Guid[len(c.Resource.Guid)-1:]+" "+c.Change)
+	}
+	for _, c := range output.Data.Verbs {
+		guids = append(guids, c.Verb.Guid[len(c.Verb.Guid)-1:]+" "+c.Change)
+	}
+	return fmt.Sprint(guids), output.Data.Next
+}
+
+func TestSyncToken(t *testing.T) {
+	token := syncToken(testTime.Add(time.Nanosecond), []string{"a@1", "b@2"})
+	if since, seen, ok := parseSyncToken(token); !ok || !since.Equal(testTime.Add(time.Nanosecond)) || fmt.Sprint(seen) != "map[a@1:true b@2:true]" {
+		t.Errorf("Expected %v having seen [a@1 b@2] but got %v and %v", testTime.Add(time.Nanosecond), since, seen)
+	}
+	if since, seen, ok := parseSyncToken(syncToken(testTime, nil)); !ok || !since.Equal(testTime) || len(seen) != 0 {
+		t.Errorf("Expected %v having seen nothing but got %v and %v", testTime, since, seen)
+	}
+	for _, token := range []string{"yesterday", "2016-01-01T00:00:00Z", "eWVzdGVyZGF5", ""} {
+		if _, _, ok := parseSyncToken(token); ok {
+			t.Errorf("Expected %s to be invalid", token)
+		}
+	}
+}
+
+func TestConditionalGetWithMemoryStore(t *testing.T) {
+	stopClock()
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000001"}}
+	callHandler(api.InsertResource, "POST", "/resources", "application/json", `{"name": "board", "description": "a board", "apiEndpoint": "https://tmt.byu.edu/boards"}`, nil)
+
+	w := callHandler(api.GetResource, "GET", "/resources/00000000-0000-0000-0000-000000000001", "", "", params)
+	if w.Code != 200 || w.Header().Get("ETag") != `"1"` || w.Header().Get("Last-Modified") != "Fri, 01 Jan 2016 00:00:00 GMT" {
+		t.Errorf("Expected 200 with validators but got %d and %v", w.Code, w.Header())
+	}
+	list := callHandler(api.GetAllResources, "GET", "/resources", "", "", nil)
+	if list.Code != 200 || list.Header().Get("ETag") == "" {
+		t.Errorf("Expected 200 with an ETag but got %d and %v", list.Code, list.Header())
+	}
+
+	for _, test := range []struct {
+		header   string
+		value    string
+		expected int
+	}{
+		{"If-None-Match", `"1"`, 304},
+		{"If-None-Match", `W/"1"`, 304},
+		{"If-None-Match", `"0", "1"`, 304},
+		{"If-None-Match", "*", 304},
+		{"If-None-Match", `"0"`, 200},
+		{"If-Modified-Since", "Fri, 01 Jan 2016 00:00:00 GMT", 304},
+		{"If-Modified-Since", "Thu, 31 Dec 2015 23:59:59 GMT", 200},
+		{"If-Modified-Since", "yesterday", 200},
+	} {
+		header := http.Header{}
+		header.Set(test.header, test.value)
+		w := callHandlerWithHeader(api.GetResource, "GET", "/resources/00000000-0000-0000-0000-000000000001", header, "", params)
+		if w.Code != test.expected {
+			t.Errorf("Expected %d for %s: %s but got %d", test.expected, test.header, test.value, w.Code)
+		}
+		if test.expected == 304 && w.Body.Len() != 0 {
+			t.Errorf("Expected no body but got %s", w.Body.String())
+		}
+	}
+
+	// If-None-Match wins over If-Modified-Since
+	header := http.Header{}
+	header.Set("If-None-Match", `"0"`)
+	header.Set("If-Modified-Since", "Fri, 01 Jan 2016 00:00:00 GMT")
+	if w := callHandlerWithHeader(api.GetResource, "GET", "/resources/00000000-0000-0000-0000-000000000001", header, "", params); w.Code != 200 {
+		t.Errorf("Expected 200 but got %d", w.Code)
+	}
+
+	// The list is current until any resource changes
+	header = http.Header{}
+	header.Set("If-None-Match", list.Header().Get("ETag"))
+	if w := callHandlerWithHeader(api.GetAllResources, "GET", "/resources", header, "", nil); w.Code != 304 {
+		t.Errorf("Expected 304 but got %d", w.Code)
+	}
+	accessors.Now = func() time.Time {
+		return testTime.Add(time.Minute)
+	}
+	callHandler(api.AddVerb, "POST", "/verbs", "application/json", `{"resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase", "description": "can erase"}`, nil)
+	if w := callHandlerWithHeader(api.GetAllResources, "GET", "/resources", header, "", nil); w.Code != 200 {
+		t.Errorf("Expected 200 but got %d", w.Code)
+	}
+	header = http.Header{}
+	header.Set("If-None-Match", `"1"`)
+	if w := callHandlerWithHeader(api.GetResource, "GET", "/resources/00000000-0000-0000-0000-000000000001", header, "", params); w.Code != 200 || w.Header().Get("ETag") != `"2"` {
+		t.Errorf("Expected 200 with version 2 but got %d and %v", w.Code, w.Header())
+	}
+}
+
+func TestChangesWithMemoryStore(t *testing.T) {
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	tick := 0
+	accessors.Now = func() time.Time {
+		tick++
+		return testTime.Add(time.Duration(tick) * time.Minute)
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	changesParams := httprouter.Params{httprouter.Param{Key: "guid", Value: "changes"}}
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000001"}}
+
+	callHandler(api.InsertResource, "POST", "/resources", "application/json", `{"name": "board", "description": "a board", "apiEndpoint": "https://tmt.byu.edu/boards"}`, nil)
+	callHandler(api.AddVerb, "POST", "/verbs", "application/json", `{"resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase", "description": "can erase"}`, nil)
+
+	// Without a token everything is returned; GetResource serves the feed
+	w := callHandler(api.GetResource, "GET", "/resources/changes", "", "", changesParams)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	guids, next := changeFeedGuids(t, w)
+	if guids != "[1 created 2 created]" {
+		t.Errorf("Expected [1 created 2 created] but got %v", guids)
+	}
+
+	// The token picks up where the last response left off
+	callHandler(api.InsertResource, "POST", "/resources", "application/json", `{"name": "room", "description": "a room", "apiEndpoint": "https://tmt.byu.edu/rooms"}`, nil)
+	callHandler(api.DeleteResource, "DELETE", "/resources/00000000-0000-0000-0000-000000000001?cascade=true", "", "", params)
+	w = callHandler(api.GetChanges, "GET", "/resources/changes?since="+next, "", "", nil)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	if guids, next = changeFeedGuids(t, w); guids != "[3 created 1 deleted 2 deleted]" {
+		t.Errorf("Expected [3 created 1 deleted 2 deleted] but got %v", guids)
+	}
+	w = callHandler(api.GetChanges, "GET", "/resources/changes?since="+next, "", "", nil)
+	if guids, _ := changeFeedGuids(t, w); w.Code != 200 || guids != "[]" {
+		t.Errorf("Expected 200 and no changes but got %d and %v", w.Code, guids)
+	}
+
+	// Changes that commit late are sent once, if within the commit window
+	accessors.Now = func() time.Time {
+		return testTime.Add(time.Duration(tick)*time.Minute - time.Second)
+	}
+	callHandler(api.InsertResource, "POST", "/resources", "application/json", `{"name": "lab", "description": "a lab", "apiEndpoint": "https://tmt.byu.edu/labs"}`, nil)
+	w = callHandler(api.GetChanges, "GET", "/resources/changes?since="+next, "", "", nil)
+	if guids, next = changeFeedGuids(t, w); w.Code != 200 || guids != "[4 created]" {
+		t.Errorf("Expected 200 and [4 created] but got %d and %v", w.Code, guids)
+	}
+	w = callHandler(api.GetChanges, "GET", "/resources/changes?since="+next, "", "", nil)
+	if guids, _ := changeFeedGuids(t, w); w.Code != 200 || guids != "[]" {
+		t.Errorf("Expected 200 and no changes but got %d and %v", w.Code, guids)
+	}
+
+	// The ETag of the list is a sync token too
+	list := callHandler(api.GetAllResources, "GET", "/resources", "", "", nil)
+	last, _, _ := parseSyncToken(next)
+	if list.Header().Get("ETag") != `W/"`+syncToken(last, nil)+`"` {
+		t.Errorf("Expected %s but got %s", `W/"`+syncToken(last, nil)+`"`, list.Header().Get("ETag"))
+	}
+
+	if w := callHandler(api.GetChanges, "GET", "/resources/changes?since=yesterday", "", "", nil); w.Code != 400 {
+		t.Errorf("Expected 400 but got %d", w.Code)
+	}
+
+	// Tokens older than the trash are gone
+	os.Setenv("TRASH_RETENTION", "1m")
+	defer os.Unsetenv("TRASH_RETENTION")
+	if w := callHandler(api.GetChanges, "GET", "/resources/changes?since="+syncToken(testTime, nil), "", "", nil); w.Code != 410 {
+		t.Errorf("Expected 410 but got %d", w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	rules "github.com/byu-oit-ssengineering/tmt-resources/rules"
+	"strings"
+	"unicode"
+)
+
+// Matches a guid: 32 hex digits in groups of 8, 4, 4, 4 and 12.
+var guidPattern = rules.GuidPattern
+
+// Returns the i-th path parameter, which must be a guid. If it is not the
+//   client is sent a 400 response, and ok is false.
+func guidParam(c *eden.Context, i int) (guid string, ok bool) {
+	guid = c.Params[i].Value
+	if !guidPattern.MatchString(guid) {
+		respondFailure(c, 400, fmt.Sprintf("Malformed %s %q: expected a guid such as 00000000-0000-0000-0000-000000000000", c.Params[i].Key, guid))
+		return guid, false
+	}
+	return guid, true
+}
+
+// Body of the 409 response to a write that would duplicate a unique value.
+type duplicateConflict struct {
+	Message  string `json:"message"`
+	Entity   string `json:"entity"`   // What kind of entity already holds the value
+	Existing string `json:"existing"` // Guid of the entity
+}
+
+// What a duplicate of each kind of entity means to a client.
+var duplicateMessages = map[string]string{
+	accessors.EntityResource: "Another resource already has that name",
+	accessors.EntityVerb:     "The resource already has that verb",
+	accessors.EntityType:     "The resource already has that type",
+}
+
+// Responds to an error from the store with the status of its kind: 404
+//   for accessors.ErrNotFound, 409 for accessors.ErrConflict and 400 for
+//   accessors.ErrInvalid. A 409 for a duplicate names the existing entity.
+//   Anything else is a fault of the service; the client is sent a 500 with
+//   the given message. Every response carries the request's id, and every
+//   error is logged with the request, the faults as errors.
+func respondError(c *eden.Context, err error, message string) {
+	var notFound *accessors.NotFoundError
+	var duplicate *accessors.DuplicateError
+	switch {
+	case errors.As(err, &notFound):
+		respondFailure(c, 404, fmt.Sprintf("No %s found for %s", notFound.Entity, notFound.Guid))
+	case errors.As(err, &duplicate):
+		respondFailure(c, 409, duplicateConflict{duplicateMessages[duplicate.Entity], duplicate.Entity, duplicate.Guid})
+	case errors.Is(err, accessors.ErrNotFound):
+		respondFailure(c, 404, describe(err))
+	case errors.Is(err, accessors.ErrConflict):
+		respondFailure(c, 409, describe(err))
+	case errors.Is(err, accessors.ErrInvalid):
+		respondFailure(c, 400, describe(err))
+	default:
+		logError(c, err, true)
+		respondFailure(c, 500, message)
+		return
+	}
+	logError(c, err, false)
+}
+
+// Words an error from the store for a client: without the package prefix
+//   and capitalized.
+func describe(err error) string {
+	msg := strings.TrimPrefix(err.Error(), "accessors: ")
+	if msg == "" {
+		return msg
+	}
+	runes := []rune(msg)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,151 @@
+package apis
+
+import (
+	"encoding/json"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testResponseError struct {
+	Status string
+	Data   serviceError
+}
+
+func TestErrorStatusesWithMemoryStore(t *testing.T) {
+	api := newTrashApi()
+	missing := httprouter.Params{httprouter.Param{Key: "guid", Value: "99999999-9999-9999-9999-999999999999"}}
+	malformed := httprouter.Params{httprouter.Param{Key: "guid", Value: "not-a-guid"}}
+
+	handlers := []struct {
+		name    string
+		handler func(c *eden.Context)
+		method  string
+	}{
+		{"GetResource", api.GetResource, "GET"},
+		{"GetResourceVerbs", api.GetResourceVerbs, "GET"},
+		{"DeleteResource", api.DeleteResource, "DELETE"},
+		{"RemoveVerb", api.RemoveVerb, "DELETE"},
+		{"GetGrant", api.GetGrant, "GET"},
+		{"GetResourceType", api.GetResourceType, "GET"},
+	}
+	for _, h := range handlers {
+		// A malformed guid never reaches the store
+		if w := callHandler(h.handler, h.method, "/x/not-a-guid", "", "", malformed); w.Code != 400 {
+			t.Errorf("%s: Expected 400 but got %d", h.name, w.Code)
+		}
+
+		// A guid nothing has is not found
+		w := callHandler(h.handler, h.method, "/x/99999999-9999-9999-9999-999999999999", "", "", missing)
+		if w.Code != 404 {
+			t.Errorf("%s: Expected 404 but got %d: %s", h.name, w.Code, w.Body.String())
+		}
+	}
+
+	// The 404 names what was not found
+	w := callHandler(api.GetResource, "GET", "/resources/99999999-9999-9999-9999-999999999999", "", "", missing)
+	var output testResponseError
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := "No resource found for 99999999-9999-9999-9999-999999999999"
+	if output.Data.Message != expected {
+		t.Errorf("Expected %v but got %v", expected, output.Data.Message)
+	}
+}
+
+func TestRespondError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+		data string
+	}{
+		{&accessors.NotFoundError{accessors.EntityVerb, "11111111-2222-3333-4444-555555555555"}, 404, "No verb found for 11111111-2222-3333-4444-555555555555"},
+		{accessors.ErrDuplicate, 409, "Duplicate entry"},
+		{accessors.ErrInvalidSort, 400, "Invalid sort"},
+	}
+	for _, test := range tests {
+		w := callHandler(func(c *eden.Context) { respondError(c, test.err, "An error has occurred") }, "GET", "/", "", "", nil)
+		if w.Code != test.code {
+			t.Errorf("Expected %v but got %v", test.code, w.Code)
+		}
+		var output testResponseError
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if output.Data.Message != test.data {
+			t.Errorf("Expected %v but got %v", test.data, output.Data.Message)
+		}
+	}
+}
+
+// Checks that every error body carries the request's id, those of
+//   handlers answering directly included.
+func TestErrorsCarryRequestID(t *testing.T) {
+	api := newTrashApi()
+	header := http.Header{}
+	header.Set("X-Request-ID", "abc-123")
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "not-a-guid"}}
+
+	for _, w := range []*httptest.ResponseRecorder{
+		callHandlerWithHeader(api.GetResource, "GET", "/resources/not-a-guid", header, "", params),
+		callHandlerWithHeader(api.Authorize, "POST", "/authorize", header, "", nil),
+		callHandlerWithHeader(api.InsertResource, "POST", "/resources", header, "", nil),
+	} {
+		var output struct {
+			Status string
+			Data   map[string]interface{}
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if w.Code < 400 || output.Status != "ERROR" || output.Data["requestId"] != "abc-123" {
+			t.Errorf("Expected an error with requestId abc-123 but got %d %s", w.Code, w.Body.String())
+		}
+	}
+}
+
+type testResponseDuplicate struct {
+	Status string
+	Data   duplicateConflict
+}
+
+func TestDuplicatesWithMemoryStore(t *testing.T) {
+	api := newTrashApi()
+	board := `{"name": "whiteboard", "description": "a whiteboard", "apiEndpoint": "https://tmt.byu.edu/whiteboards"}`
+
+	// A trashed resource does not hold its name
+	if w := callHandler(api.InsertResource, "POST", "/resources", "application/json", board, nil); w.Code != 201 {
+		t.Fatalf("Expected 201 but got %d: %s", w.Code, w.Body.String())
+	}
+	verb := `{"resourceGUID": "00000000-0000-0000-0000-000000000003", "verb": "erase", "description": "can erase"}`
+	if w := callHandler(api.AddVerb, "POST", "/verbs", "application/json", verb, nil); w.Code != 201 {
+		t.Fatalf("Expected 201 but got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A live one does, and the conflict names it
+	tests := []struct {
+		handler  func(c *eden.Context)
+		body     string
+		expected duplicateConflict
+	}{
+		{api.InsertResource, board, duplicateConflict{"Another resource already has that name", accessors.EntityResource, "00000000-0000-0000-0000-000000000003"}},
+		{api.AddVerb, verb, duplicateConflict{"The resource already has that verb", accessors.EntityVerb, "00000000-0000-0000-0000-000000000004"}},
+	}
+	for _, test := range tests {
+		w := callHandler(test.handler, "POST", "/", "application/json", test.body, nil)
+		if w.Code != 409 {
+			t.Errorf("Expected 409 but got %d", w.Code)
+		}
+		var output testResponseDuplicate
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if output.Data != test.expected {
+			t.Errorf("Expected %v but got %v", test.expected, output.Data)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package apis
+
+import (
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Returns the entity tag of a version of a resource or verb.
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// Sets the ETag header of the response to the given version.
+func setETag(c *eden.Context, version int) {
+	c.Response.Header().Set("ETag", etag(version))
+}
+
+// Returns the entity tag and modification time of a resource served with
+//   its effective verbs, which also change when the types it inherits from
+//   do. A resource without types keeps the tag of its version; otherwise
+//   the tag is its version followed by a hash of its type chain.
+func inheritedTag(r accessors.Resource, chain []accessors.Resource) (string, time.Time) {
+	if len(chain) == 0 {
+		return etag(r.Version), r.Modified
+	}
+	h := fnv.New64a()
+	modified := r.Modified
+	for _, t := range chain {
+		fmt.Fprintf(h, "%s:%d;", t.Guid, t.Version)
+		if t.Modified.After(modified) {
+			modified = t.Modified
+		}
+	}
+	return strconv.Quote(fmt.Sprintf("%d-%x", r.Version, h.Sum64())), modified
+}
+
+// Checks the request's If-Match header, if it has one, against the current
+//   version of what it changes. Responds with a 412 and returns false if
+//   none of the listed entity tags match. Weak tags never match; tags from
+//   inheritedTag match on their version alone.
+func ifMatch(c *eden.Context, version int) bool {
+	header := c.Request.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+	current := etag(version)
+	inherited := strings.TrimSuffix(current, `"`) + "-"
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == current || strings.HasPrefix(tag, inherited) {
+			return true
+		}
+	}
+	respondFailure(c, 412, "The entity has changed; get it again and retry")
+	return false
+}
+
+// Sets the ETag and Last-Modified headers of a GET response and checks the
+//   request's If-None-Match header or, if it has none, its
+//   If-Modified-Since header against them. Responds with a 304 and returns
+//   true if the client's copy is still current. Tags are compared weakly.
+func notModified(c *eden.Context, tag string, modified time.Time) bool {
+	c.Response.Header().Set("ETag", tag)
+	if !modified.IsZero() {
+		c.Response.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if header := c.Request.Header.Get("If-None-Match"); header != "" {
+		for _, t := range strings.Split(header, ",") {
+			if t = strings.TrimSpace(t); t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(tag, "W/") {
+				c.Response.WriteHeader(304)
+				return true
+			}
+		}
+		return false
+	}
+
+	// Last-Modified only has whole seconds
+	if header := c.Request.Header.Get("If-Modified-Since"); header != "" && !modified.IsZero() {
+		if since, err := http.ParseTime(header); err == nil && !modified.Truncate(time.Second).After(since) {
+			c.Response.WriteHeader(304)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package apis
+
+import (
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{"", true},
+		{`"3"`, true},
+		{"*", true},
+		{`"1", "3"`, true},
+		{`"2"`, false},
+		{`W/"3"`, false},
+		{"3", false},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("PUT", "/resources/guid", nil)
+		if test.header != "" {
+			req.Header.Set("If-Match", test.header)
+		}
+		w := httptest.NewRecorder()
+		if ok := ifMatch(&eden.Context{Request: req, Response: w}, 3); ok != test.expected {
+			t.Errorf("Expected %v for %q but got %v", test.expected, test.header, ok)
+		}
+		if !test.expected && w.Code != 412 {
+			t.Errorf("Expected 412 for %q but got %d", test.header, w.Code)
+		}
+	}
+}
+
+func TestConcurrentUpdatesWithMemoryStore(t *testing.T) {
+	stopClock()
+	accessors.NewGuid = func() string {
+		return "11111111-2222-3333-4444-555555555555"
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	api.Store.Resources().Insert(accessors.Resource{Name: "test", Description: "This is a test", APIEndpoint: "https://tmt.byu.edu/resources"})
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "11111111-2222-3333-4444-555555555555"}}
+
+	put := func(ifMatch, body string) *httptest.ResponseRecorder {
+		header := http.Header{"Content-Type": {"application/json"}, "If-Match": {ifMatch}}
+		return callHandlerWithHeader(api.UpdateResource, "PUT", "/resources/11111111-2222-3333-4444-555555555555", header, body, params)
+	}
+
+	// GET exposes the version
+	w := callHandler(api.GetResource, "GET", "/resources/11111111-2222-3333-4444-555555555555", "", "", params)
+	if etag := w.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf(`Expected ETag "1" but got %v`, etag)
+	}
+
+	// Two editors start from version 1; only the first wins
+	if w := put(`"1"`, `{"description": "first"}`); w.Code != 200 || w.Header().Get("ETag") != `"2"` {
+		t.Errorf(`Expected 200 with ETag "2" but got %d with %v`, w.Code, w.Header().Get("ETag"))
+	}
+	if w := put(`"1"`, `{"description": "second"}`); w.Code != 412 {
+		t.Errorf("Expected 412 but got %d", w.Code)
+	}
+	if resource, _ := api.Store.Resources().Get("11111111-2222-3333-4444-555555555555"); resource.Description != "first" || resource.Version != 2 {
+		t.Errorf("Expected the first description at version 2 but got %v", resource)
+	}
+
+	// Deletes are checked too
+	w = callHandlerWithHeader(api.DeleteResource, "DELETE", "/resources/11111111-2222-3333-4444-555555555555", http.Header{"If-Match": {`"1"`}}, "", params)
+	if w.Code != 412 {
+		t.Errorf("Expected 412 but got %d", w.Code)
+	}
+	if _, err := api.Store.Resources().Get("11111111-2222-3333-4444-555555555555"); err != nil {
+		t.Errorf("Expected the resource to be kept but got %v", err)
+	}
+
+	// And so are verbs
+	accessors.NewGuid = func() string {
+		return "22222222-2222-2222-2222-222222222222"
+	}
+	w = callHandler(api.AddVerb, "POST", "/verbs", "application/json", `{"resourceGUID": "11111111-2222-3333-4444-555555555555", "verb": "edit", "description": "can edit"}`, nil)
+	if etag := w.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf(`Expected ETag "1" but got %v`, etag)
+	}
+	verbParams := httprouter.Params{httprouter.Param{Key: "guid", Value: "22222222-2222-2222-2222-222222222222"}}
+	header := http.Header{"Content-Type": {"application/json"}, "If-Match": {`"2"`}}
+	if w := callHandlerWithHeader(api.UpdateVerb, "PUT", "/verbs/22222222-2222-2222-2222-222222222222", header, `{"description": "may edit"}`, verbParams); w.Code != 412 {
+		t.Errorf("Expected 412 but got %d", w.Code)
+	}
+	header.Set("If-Match", `"1"`)
+	if w := callHandlerWithHeader(api.UpdateVerb, "PUT", "/verbs/22222222-2222-2222-2222-222222222222", header, `{"description": "may edit"}`, verbParams); w.Code != 200 || w.Header().Get("ETag") != `"2"` {
+		t.Errorf(`Expected 200 with ETag "2" but got %d with %v`, w.Code, w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,217 @@
+package apis
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of an exported catalog.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// The media type GET /export responds with in each format.
+var formatTypes = map[string]string{
+	FormatJSON: "application/json",
+	FormatYAML: "application/x-yaml",
+	FormatCSV:  "text/csv",
+}
+
+// Returns the catalog format of a media type, or false if there is none.
+func formatOfMediaType(mediaType string) (string, bool) {
+	switch mediaType {
+	case "application/json":
+		return FormatJSON, true
+	case "application/x-yaml", "application/yaml", "text/yaml", "text/x-yaml":
+		return FormatYAML, true
+	case "text/csv":
+		return FormatCSV, true
+	}
+	return "", false
+}
+
+// Returns the catalog format of a file from its extension, or false if
+//   there is none.
+func FormatOfFile(name string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON, true
+	case ".yaml", ".yml":
+		return FormatYAML, true
+	case ".csv":
+		return FormatCSV, true
+	}
+	return "", false
+}
+
+// The columns of a catalog in CSV. Each row is a resource, a verb or a
+//   type association, as told by its kind, and only uses the columns that
+//   apply to it.
+var csvHeader = []string{"kind", "guid", "resourceGUID", "name", "description", "apiEndpoint", "verb", "type"}
+
+// Kinds of CSV row.
+const (
+	csvResource = "resource"
+	csvVerb     = "verb"
+	csvType     = "type"
+)
+
+// Writes a catalog in the given format.
+func WriteCatalog(w io.Writer, format string, c accessors.Catalog) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(c)
+	case FormatYAML:
+		data, err := yaml.Marshal(c)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case FormatCSV:
+		return writeCSV(w, c)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// Writes each resource followed by its verbs, then the type associations.
+func writeCSV(w io.Writer, c accessors.Catalog) error {
+	cw := csv.NewWriter(w)
+	cw.Write(csvHeader)
+	for _, r := range c.Resources {
+		cw.Write([]string{csvResource, r.Guid, "", r.Name, r.Description, r.APIEndpoint, "", ""})
+		for _, v := range r.Verbs {
+			cw.Write([]string{csvVerb, v.Guid, r.Guid, "", v.Description, "", v.Verb, ""})
+		}
+	}
+	for _, t := range c.Types {
+		cw.Write([]string{csvType, t.Guid, t.ResourceGUID, "", "", "", "", t.Type})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Reads a catalog in the given format, rejecting unknown fields.
+func ReadCatalog(r io.Reader, format string) (accessors.Catalog, error) {
+	var c accessors.Catalog
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&c); err != nil {
+			return c, fmt.Errorf("invalid JSON catalog: %v", err)
+		}
+		if dec.More() {
+			return c, fmt.Errorf("invalid JSON catalog: more than one JSON value")
+		}
+	case FormatYAML:
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return c, err
+		}
+		if err := yaml.UnmarshalStrict(data, &c); err != nil {
+			return c, fmt.Errorf("invalid YAML catalog: %v", err)
+		}
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return c, fmt.Errorf("unknown format %q", format)
+	}
+	return c, nil
+}
+
+// Reads a catalog written by writeCSV. The columns may come in any order
+//   and all but kind and guid may be left out; rows may too, as long as
+//   every verb's resource is somewhere in the file.
+func readCSV(r io.Reader) (accessors.Catalog, error) {
+	c := accessors.Catalog{make([]accessors.CatalogResource, 0), make([]accessors.CatalogType, 0)}
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return c, nil
+	}
+	if err != nil {
+		return c, fmt.Errorf("invalid CSV catalog: %v", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		known := false
+		for _, h := range csvHeader {
+			known = known || h == name
+		}
+		if !known {
+			return c, fmt.Errorf("invalid CSV catalog: unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"kind", "guid"} {
+		if _, ok := columns[name]; !ok {
+			return c, fmt.Errorf("invalid CSV catalog: missing column %q", name)
+		}
+	}
+
+	type verbRow struct {
+		row      int
+		resource string
+		verb     accessors.CatalogVerb
+	}
+	var verbs []verbRow
+	resources := make(map[string]int)
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return c, fmt.Errorf("invalid CSV catalog: %v", err)
+		}
+		get := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return record[i]
+			}
+			return ""
+		}
+
+		switch get("kind") {
+		case csvResource:
+			resources[get("guid")] = len(c.Resources)
+			c.Resources = append(c.Resources, accessors.CatalogResource{get("guid"), get("name"), get("description"), get("apiEndpoint"), make([]accessors.CatalogVerb, 0)})
+		case csvVerb:
+			verbs = append(verbs, verbRow{row, get("resourceGUID"), accessors.CatalogVerb{get("guid"), get("verb"), get("description")}})
+		case csvType:
+			c.Types = append(c.Types, accessors.CatalogType{get("guid"), get("resourceGUID"), get("type")})
+		default:
+			return c, fmt.Errorf("invalid CSV catalog: row %d has unknown kind %q", row, get("kind"))
+		}
+	}
+
+	for _, v := range verbs {
+		i, ok := resources[v.resource]
+		if !ok {
+			return c, fmt.Errorf("invalid CSV catalog: the verb in row %d refers to resource %q, which is not in the file", v.row, v.resource)
+		}
+		c.Resources[i].Verbs = append(c.Resources[i].Verbs, v.verb)
+	}
+	return c, nil
+}
+
+// Encodes a catalog in the given format.
+func encodeCatalog(format string, c accessors.Catalog) ([]byte, error) {
+	var buf bytes.Buffer
+	err := WriteCatalog(&buf, format, c)
+	return buf.Bytes(), err
+}
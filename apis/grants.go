@@ -0,0 +1,191 @@
+package apis
+
+import (
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+)
+
+// Responds to a grant that could not be saved and returns false, or
+//   returns true if err is nil.
+func grantSaved(c *eden.Context, err error) bool {
+	switch err {
+	case nil:
+		return true
+	case accessors.ErrInvalidGrant:
+		respondFailure(c, 400, "A grant needs exactly one of a principal and a role")
+	case accessors.ErrUnknownVerb:
+		respondFailure(c, 400, "The resource has no such verb")
+	case accessors.ErrForeignKey:
+		respondFailure(c, 404, "No resource has that guid")
+	case accessors.ErrDuplicate:
+		respondFailure(c, 409, "The same grant already exists")
+	case accessors.ErrVersionMismatch:
+		respondFailure(c, 412, "The grant has changed; get it again and retry")
+	default:
+		respondError(c, err, "An error has occurred")
+	}
+	return false
+}
+
+// List the grants.
+// GET /grants?principal=:principal&role=:role&resource=:resourceGUID&verb=:verb
+//   Every parameter is optional and narrows the list. Grants are listed in
+//   the order they were made.
+func (a *Api) GetGrants(c *eden.Context) {
+	params := c.Request.URL.Query()
+	q := accessors.GrantQuery{
+		Principal:    params.Get("principal"),
+		Role:         params.Get("role"),
+		ResourceGUID: params.Get("resource"),
+		Verb:         params.Get("verb"),
+	}
+
+	grants, err := a.Store.Grants().List(q)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving grants")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", grants})
+}
+
+// Get a grant by guid.
+// GET /grants/:guid
+func (a *Api) GetGrant(c *eden.Context) {
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	grant, err := a.Store.Grants().Get(guid)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving the grant")
+		return
+	}
+
+	// Respond
+	setETag(c, grant.Version)
+	c.Respond(200, eden.Response{"OK", grant})
+}
+
+// Allow a principal, or everyone with a role, to perform a verb on a
+//   resource.
+// POST /grants principal=:principal or role=:role, resourceGUID=:resourceGUID, verb=:verb
+//   or a JSON body {"principal" or "role", "resourceGUID", "verb"}
+//   The verb must be one of the resource's own or inherited verbs.
+func (a *Api) AddGrant(c *eden.Context) {
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ga := store.Grants()
+
+	body, ok := readBody(c, grantFields)
+	if !ok || !requireFields(c, body, grantRequiredFields) {
+		return
+	}
+
+	grant := accessors.Grant{Principal: body["principal"], Role: body["role"], ResourceGUID: body["resourceGUID"], Verb: body["verb"]}
+	guid, err := ga.Add(grant)
+	if !grantSaved(c, err) {
+		return
+	}
+
+	// Read it back for the values set by the store
+	if grant, err = ga.Get(guid); err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+
+	// Respond with the new grant
+	c.Response.Header().Set("Location", "/grants/"+guid)
+	setETag(c, grant.Version)
+	c.Respond(201, eden.Response{"OK", grant})
+}
+
+// Change who a grant is for or what it allows.
+// PUT /grants/:guid with any of principal, role, resourceGUID and verb
+//   as form fields or a JSON body. Setting a principal clears the role and
+//   the other way around. An If-Match header must match the grant's ETag.
+func (a *Api) UpdateGrant(c *eden.Context) {
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ga := store.Grants()
+
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	body, ok := readBody(c, grantFields)
+	if !ok {
+		return
+	}
+
+	grant, err := ga.Get(guid)
+	if !grantSaved(c, err) {
+		return
+	}
+	if !ifMatch(c, grant.Version) {
+		return
+	}
+
+	// Update given fields
+	if principal, ok := body["principal"]; ok {
+		grant.Principal, grant.Role = principal, ""
+	}
+	if role, ok := body["role"]; ok {
+		grant.Principal, grant.Role = "", role
+	}
+	if resource, ok := body["resourceGUID"]; ok {
+		grant.ResourceGUID = resource
+	}
+	if verb, ok := body["verb"]; ok {
+		grant.Verb = verb
+	}
+
+	// Save
+	if !grantSaved(c, ga.Update(grant)) {
+		return
+	}
+	grant.Version++
+
+	// Respond
+	setETag(c, grant.Version)
+	c.Respond(200, eden.Response{"OK", grant})
+}
+
+// Revoke a grant.
+// DELETE /grants/:guid
+//   An If-Match header must match the grant's ETag. The response is a 412
+//   if it does not, or if the grant changes while it is revoked.
+func (a *Api) RemoveGrant(c *eden.Context) {
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ga := store.Grants()
+
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	grant, err := ga.Get(guid)
+	if !grantSaved(c, err) {
+		return
+	}
+	if !ifMatch(c, grant.Version) {
+		return
+	}
+
+	if !grantSaved(c, ga.Remove(guid, grant.Version)) {
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", "success"})
+}
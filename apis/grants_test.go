@@ -0,0 +1,86 @@
+package apis
+
+import (
+	"encoding/json"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"testing"
+)
+
+type testResponseGrant struct {
+	Status string
+	Data   accessors.Grant
+}
+
+type testResponseGrants struct {
+	Status string
+	Data   []accessors.Grant
+}
+
+func TestGrantsWithMemoryStore(t *testing.T) {
+	stopClock()
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	api.Store.Resources().Insert(accessors.Resource{Name: "whiteboard", APIEndpoint: "https://tmt.byu.edu/whiteboards"})
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+
+	// Grants need a verb the resource has and one of a principal and a role
+	for _, c := range []struct {
+		body     string
+		expected int
+	}{
+		{`{"principal": "jdoe", "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"}`, 201},
+		{`{"principal": "jdoe", "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"}`, 409},
+		{`{"principal": "jdoe", "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "paint"}`, 400},
+		{`{"principal": "jdoe", "role": "staff", "resourceGUID": "00000000-0000-0000-0000-000000000001", "verb": "erase"}`, 400},
+		{`{"role": "staff", "resourceGUID": "99999999-9999-9999-9999-999999999999", "verb": "erase"}`, 404},
+		{`{"role": "staff", "verb": "erase"}`, 400},
+	} {
+		if w := callHandler(api.AddGrant, "POST", "/grants", "application/json", c.body, nil); w.Code != c.expected {
+			t.Errorf("Expected %d for %s but got %d: %s", c.expected, c.body, w.Code, w.Body.String())
+		}
+	}
+
+	// Read it back
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000003"}}
+	w := callHandler(api.GetGrant, "GET", "/grants/00000000-0000-0000-0000-000000000003", "", "", params)
+	var grant testResponseGrant
+	if err := json.Unmarshal(w.Body.Bytes(), &grant); err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := accessors.Grant{"00000000-0000-0000-0000-000000000003", "jdoe", "", "00000000-0000-0000-0000-000000000001", "erase", 1}
+	if grant.Data != expected || w.Header().Get("ETag") != `"1"` {
+		t.Errorf("Expected %v but got %v", expected, grant.Data)
+	}
+
+	// Give it to a role instead
+	w = callHandlerWithHeader(api.UpdateGrant, "PUT", "/grants/00000000-0000-0000-0000-000000000003", http.Header{"Content-Type": {"application/json"}, "If-Match": {`"1"`}}, `{"role": "staff"}`, params)
+	if w.Code != 200 {
+		t.Errorf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	w = callHandler(api.GetGrants, "GET", "/grants?role=staff", "", "", nil)
+	var grants testResponseGrants
+	if err := json.Unmarshal(w.Body.Bytes(), &grants); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(grants.Data) != 1 || grants.Data[0].Principal != "" || grants.Data[0].Version != 2 {
+		t.Errorf("Expected the staff grant but got %v", grants.Data)
+	}
+
+	// Revoke it
+	if w := callHandlerWithHeader(api.RemoveGrant, "DELETE", "/grants/00000000-0000-0000-0000-000000000003", http.Header{"If-Match": {`"1"`}}, "", params); w.Code != 412 {
+		t.Errorf("Expected 412 but got %d", w.Code)
+	}
+	if w := callHandler(api.RemoveGrant, "DELETE", "/grants/00000000-0000-0000-0000-000000000003", "", "", params); w.Code != 200 {
+		t.Errorf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	if w := callHandler(api.GetGrant, "GET", "/grants/00000000-0000-0000-0000-000000000003", "", "", params); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package apis
+
+import (
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"os"
+	"strconv"
+)
+
+// Address of the TMT guid service.
+const defaultGuidURL = "http://tmt-guid.byu.edu/guid"
+
+// Makes new entities take their guids from the source selected by the
+//   GUID_SOURCE environment variable: "remote" (the default) asks the guid
+//   service at GUID_URL, "uuid4" makes random guids locally and "uuid7"
+//   makes time-ordered ones. The remote source is tuned with GUID_TIMEOUT,
+//   GUID_POOL_SIZE, GUID_FAILURE_THRESHOLD and GUID_COOLDOWN. Call the
+//   returned function to stop it.
+func StartGuidSource() (stop func(), err error) {
+	switch source := os.Getenv("GUID_SOURCE"); source {
+	case "", "remote":
+	case "uuid4":
+		accessors.SetGuidSource(accessors.UUIDv4{})
+		return func() {}, nil
+	case "uuid7":
+		accessors.SetGuidSource(&accessors.UUIDv7{})
+		return func() {}, nil
+	default:
+		return nil, fmt.Errorf("unknown GUID_SOURCE %q: use remote, uuid4 or uuid7", source)
+	}
+
+	config := accessors.RemoteGuidConfig{URL: os.Getenv("GUID_URL")}
+	if config.URL == "" {
+		config.URL = defaultGuidURL
+	}
+	if config.Timeout, err = durationEnv("GUID_TIMEOUT", 0); err != nil {
+		return nil, err
+	}
+	if config.Cooldown, err = durationEnv("GUID_COOLDOWN", 0); err != nil {
+		return nil, err
+	}
+	if config.PoolSize, err = countEnv("GUID_POOL_SIZE"); err != nil {
+		return nil, err
+	}
+	if config.FailureThreshold, err = countEnv("GUID_FAILURE_THRESHOLD"); err != nil {
+		return nil, err
+	}
+
+	remote := accessors.NewRemoteGuids(config)
+	accessors.SetGuidSource(remote)
+	return remote.Close, nil
+}
+
+// Reads a positive count from the environment; 0 if it is not set.
+func countEnv(name string) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s %q: use a positive number", name, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,29 @@
+package apis
+
+import (
+	"os"
+	"testing"
+)
+
+func TestStartGuidSourceConfig(t *testing.T) {
+	defer os.Unsetenv("GUID_SOURCE")
+	defer os.Unsetenv("GUID_POOL_SIZE")
+
+	os.Setenv("GUID_SOURCE", "uuid1")
+	if _, err := StartGuidSource(); err == nil {
+		t.Error("Expected an error for an unknown GUID_SOURCE")
+	}
+
+	os.Setenv("GUID_SOURCE", "remote")
+	os.Setenv("GUID_POOL_SIZE", "none")
+	if _, err := StartGuidSource(); err == nil {
+		t.Error("Expected an error for an invalid GUID_POOL_SIZE")
+	}
+
+	os.Setenv("GUID_SOURCE", "uuid7")
+	stop, err := StartGuidSource()
+	if err != nil {
+		t.Errorf("An unexpected error occurred starting the guid source: %v", err)
+	}
+	stop()
+}
@@ -0,0 +1,129 @@
+package apis
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"log/slog"
+	"time"
+)
+
+// Defaults of the startup connection check.
+const (
+	defaultConnectTimeout = time.Minute
+	maxConnectBackoff     = 10 * time.Second
+)
+
+// Wait before the first retry of a failed connection, doubling after each
+//   failure up to maxConnectBackoff.
+var connectBackoff = 500 * time.Millisecond
+
+// Longest wait for the database to answer a readiness check.
+const readyTimeout = 2 * time.Second
+
+// Calls connect until it succeeds, backing off between attempts, or until
+//   timeout has passed. Returns the last error if it never succeeds.
+func retryConnect(connect func() error, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := connectBackoff
+	for attempt := 1; ; attempt++ {
+		err := connect()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("unable to connect to the database after %d attempts: %v", attempt, err)
+		}
+		slog.Warn("connecting to the database failed", "attempt", attempt, "retryIn", backoff, "error", err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+}
+
+// Waits for the database to accept a connection, for as long as
+//   DB_CONNECT_TIMEOUT (default 1m). sql.Open does not connect, so
+//   without this an unreachable database would go unnoticed until the
+//   first request.
+func waitForDB(db *sql.DB) error {
+	timeout, err := durationEnv("DB_CONNECT_TIMEOUT", defaultConnectTimeout)
+	if err != nil {
+		return err
+	}
+	return retryConnect(db.Ping, timeout)
+}
+
+// Statuses of a dependency.
+const (
+	dependencyUp       = "up"
+	dependencyDown     = "down"
+	dependencyDegraded = "degraded" // Failing, but the service works around it
+)
+
+// The status of one thing the service depends on.
+type dependency struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Body of the /readyz response.
+type readiness struct {
+	Ready        bool         `json:"ready"`
+	Dependencies []dependency `json:"dependencies"`
+}
+
+// Tells whether the service is running.
+// GET /healthz
+//   Always a 200 while the process can answer; it checks nothing else, so
+//   a failing dependency does not get the service restarted.
+func (a *Api) Healthz(c *eden.Context) {
+	c.Respond(200, eden.Response{"OK", "alive"})
+}
+
+// Tells whether the service can handle requests, listing the status of
+//   each dependency.
+// GET /readyz
+//   The response is a 503 if the database cannot be reached or its schema
+//   is not fully migrated. A failing guid service only degrades the
+//   service, as guids are then made locally.
+func (a *Api) Readyz(c *eden.Context) {
+	ready := readiness{Ready: true, Dependencies: make([]dependency, 0)}
+	check := func(name string, err error, required bool) {
+		switch {
+		case err == nil:
+			ready.Dependencies = append(ready.Dependencies, dependency{name, dependencyUp, ""})
+		case required:
+			ready.Ready = false
+			ready.Dependencies = append(ready.Dependencies, dependency{name, dependencyDown, err.Error()})
+		default:
+			ready.Dependencies = append(ready.Dependencies, dependency{name, dependencyDegraded, err.Error()})
+		}
+	}
+
+	// Look past the timing of the store for its database
+	inner := a.Store
+	if t, ok := inner.(*accessors.TimedStore); ok {
+		inner = t.Store
+	}
+	if store, ok := inner.(*accessors.SQLStore); ok {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+		err := store.DB.PingContext(ctx)
+		cancel()
+		check("database", err, true)
+		if err == nil {
+			check("migrations", accessors.CheckSchema(store.DB, store.Dialect), true)
+		}
+	}
+	check("guids", accessors.CheckGuidSource(), false)
+
+	// Respond
+	if !ready.Ready {
+		respondFailure(c, 503, ready)
+		return
+	}
+	c.Respond(200, eden.Response{"OK", ready})
+}
@@ -0,0 +1,116 @@
+package apis
+
+import (
+	"encoding/json"
+	"errors"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testResponseReadiness struct {
+	Status string
+	Data   readiness
+}
+
+// Calls /readyz and returns the response code and the dependencies' statuses.
+func readyz(t *testing.T, api *Api) (int, map[string]string) {
+	w := callHandler(api.Readyz, "GET", "/readyz", "", "", nil)
+	var output testResponseReadiness
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if output.Data.Ready != (w.Code == 200) {
+		t.Errorf("Expected ready to match %d but got %v", w.Code, output.Data.Ready)
+	}
+	statuses := make(map[string]string)
+	for _, d := range output.Data.Dependencies {
+		statuses[d.Name] = d.Status
+	}
+	return w.Code, statuses
+}
+
+func TestHealthz(t *testing.T) {
+	api := &Api{accessors.NewMemoryStore()}
+	if w := callHandler(api.Healthz, "GET", "/healthz", "", "", nil); w.Code != 200 {
+		t.Errorf("Expected 200 but got %d", w.Code)
+	}
+}
+
+func TestReadyzWithSQLite(t *testing.T) {
+	db, err := accessors.OpenSQLite(":memory:")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred opening SQLite: %v", err)
+	}
+	defer db.Close()
+	api := &Api{accessors.NewSQLStore(db, accessors.SQLite)}
+
+	// Not ready until the schema is migrated
+	code, statuses := readyz(t, api)
+	if code != 503 || statuses["database"] != dependencyUp || statuses["migrations"] != dependencyDown {
+		t.Errorf("Expected 503 with pending migrations but got %d %v", code, statuses)
+	}
+	if _, err := accessors.MigrateUp(db, accessors.SQLite); err != nil {
+		t.Fatalf("An unexpected error occurred migrating: %v", err)
+	}
+	code, statuses = readyz(t, api)
+	if code != 200 || statuses["database"] != dependencyUp || statuses["migrations"] != dependencyUp || statuses["guids"] != dependencyUp {
+		t.Errorf("Expected 200 but got %d %v", code, statuses)
+	}
+
+	// Nor once the database is gone
+	db.Close()
+	code, statuses = readyz(t, api)
+	if code != 503 || statuses["database"] != dependencyDown {
+		t.Errorf("Expected 503 with the database down but got %d %v", code, statuses)
+	}
+}
+
+func TestReadyzGuidService(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer failing.Close()
+	remote := accessors.NewRemoteGuids(accessors.RemoteGuidConfig{URL: failing.URL})
+	defer remote.Close()
+	accessors.SetGuidSource(remote)
+	defer accessors.SetGuidSource(accessors.UUIDv4{})
+
+	// Guids are then made locally, so the service is still ready
+	code, statuses := readyz(t, &Api{accessors.NewMemoryStore()})
+	if code != 200 || statuses["guids"] != dependencyDegraded {
+		t.Errorf("Expected 200 with the guid service degraded but got %d %v", code, statuses)
+	}
+	if _, ok := statuses["database"]; ok {
+		t.Errorf("Expected no database status for the memory store but got %v", statuses)
+	}
+}
+
+func TestRetryConnect(t *testing.T) {
+	defer func(backoff time.Duration) { connectBackoff = backoff }(connectBackoff)
+	connectBackoff = time.Millisecond
+
+	// Failures are retried until the database answers
+	attempts := 0
+	err := retryConnect(func() error {
+		if attempts++; attempts < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}, time.Second)
+	if err != nil || attempts != 3 {
+		t.Errorf("Expected success on attempt 3 but got %v on attempt %d", err, attempts)
+	}
+
+	// Until the time is up
+	attempts = 0
+	err = retryConnect(func() error {
+		attempts++
+		return errors.New("connection refused")
+	}, 10*time.Millisecond)
+	if err == nil || attempts < 2 {
+		t.Errorf("Expected an error after several attempts but got %v after %d", err, attempts)
+	}
+}
@@ -0,0 +1,156 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"time"
+)
+
+// Header carrying the id of a request.
+const requestIDHeader = "X-Request-ID"
+
+// Request ids sent by clients are kept if they look like ids; anything
+//   else is replaced.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:+/=-]{1,128}$`)
+
+// Returns a logger writing one JSON object per line to w, at the level
+//   named by level: "debug", "info" (the default), "warn" or "error".
+func NewLogger(w io.Writer, level string) (*slog.Logger, error) {
+	var l slog.Level
+	if level != "" {
+		if err := l.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error: %v", err)
+		}
+	}
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l})), nil
+}
+
+// Makes the service log JSON to stderr at the level of LOG_LEVEL. Messages
+//   written with the log package go through the same logger.
+func SetupLogging() error {
+	logger, err := NewLogger(os.Stderr, os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// What is known of the request being handled.
+type requestInfo struct {
+	id     string
+	method string
+	route  string // The path the handler is registered for
+	start  time.Time
+}
+
+type requestInfoKey struct{}
+
+// Returns the request's info, set by Instrument. Handlers called without
+//   it, as in the tests, get the request id alone.
+func info(c *eden.Context) requestInfo {
+	if ri, ok := c.Request.Context().Value(requestInfoKey{}).(requestInfo); ok {
+		return ri
+	}
+	return requestInfo{id: c.Response.Header().Get(requestIDHeader), method: c.Request.Method}
+}
+
+// Gives a request an id: the X-Request-ID it was sent with or, if it has
+//   none or one that does not look like an id, a new one. The id is set on
+//   the request and echoed in the response headers, and returned.
+func requestID(c *eden.Context) string {
+	if id := c.Response.Header().Get(requestIDHeader); id != "" {
+		return id
+	}
+	id := c.Request.Header.Get(requestIDHeader)
+	if !requestIDPattern.MatchString(id) {
+		id, _ = accessors.UUIDv4{}.Guid()
+	}
+	c.Request.Header.Set(requestIDHeader, id)
+	c.Response.Header().Set(requestIDHeader, id)
+	return id
+}
+
+// Middleware giving every request an id, so even requests refused before
+//   they reach a handler can be traced.
+func RequestID(c *eden.Context) {
+	requestID(c)
+}
+
+// Attributes of a log line about the request: its id, method and route
+//   or path, its path parameters (the guid, verb and the like) and how
+//   long it has taken so far.
+func requestAttrs(c *eden.Context) []any {
+	ri := info(c)
+	attrs := []any{slog.String("requestId", ri.id), slog.String("method", ri.method)}
+	if ri.route != "" {
+		attrs = append(attrs, slog.String("route", ri.route))
+	} else {
+		attrs = append(attrs, slog.String("path", c.Request.URL.Path))
+	}
+	for _, p := range c.Params {
+		attrs = append(attrs, slog.String(p.Key, p.Value))
+	}
+	if !ri.start.IsZero() {
+		attrs = append(attrs, slog.Duration("duration", time.Since(ri.start)))
+	}
+	return attrs
+}
+
+// Logs one line per request once it has been handled.
+func logRequest(c *eden.Context, status int) {
+	attrs := append(requestAttrs(c), slog.Int("status", status))
+	level := slog.LevelInfo
+	if status >= 500 {
+		level = slog.LevelError
+	}
+	slog.Log(c.Request.Context(), level, "request", attrs...)
+}
+
+// Logs an error from the store while handling the request. Errors the
+//   client caused, such as a missing guid, are logged at the debug level.
+func logError(c *eden.Context, err error, fault bool) {
+	attrs := append(requestAttrs(c), slog.String("error", err.Error()))
+	if fault {
+		slog.ErrorContext(c.Request.Context(), "the store failed", attrs...)
+		return
+	}
+	slog.DebugContext(c.Request.Context(), "the store refused", attrs...)
+}
+
+// Body of an error response with a message, telling the client which
+//   request to report.
+type serviceError struct {
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
+}
+
+// Sends an error response carrying the request's id, so that the client
+//   can report it and it can be found in the log. A message is sent as
+//   {"message", "requestId"}; any other data is a JSON object and gains a
+//   requestId field.
+func respondFailure(c *eden.Context, code int, data interface{}) {
+	id := requestID(c)
+	if message, ok := data.(string); ok {
+		c.Respond(code, eden.Response{"ERROR", serviceError{message, id}})
+		return
+	}
+	body := make(map[string]interface{})
+	if b, err := json.Marshal(data); err != nil || json.Unmarshal(b, &body) != nil {
+		body = map[string]interface{}{"message": data}
+	}
+	body["requestId"] = id
+	c.Respond(code, eden.Response{"ERROR", body})
+}
+
+// Makes the request of c carry ri.
+func withInfo(c *eden.Context, ri requestInfo) {
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestInfoKey{}, ri))
+}
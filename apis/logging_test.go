@@ -0,0 +1,122 @@
+package apis
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	"github.com/julienschmidt/httprouter"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type testResponseFailure struct {
+	Status string
+	Data   serviceError
+}
+
+// Sends the log to a buffer until the returned function is called, and
+//   returns the buffer.
+func captureLog(t *testing.T) (*bytes.Buffer, func()) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, "debug")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	return &buf, func() { slog.SetDefault(previous) }
+}
+
+// Decodes each line of the log.
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected a JSON log line but got %q: %v", line, err)
+		}
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func TestRequestID(t *testing.T) {
+	handler := Instrument("GET", "/nothing", func(c *eden.Context) {})
+
+	// An id sent by the client is echoed
+	w := callHandlerWithHeader(handler, "GET", "/nothing", http.Header{"X-Request-Id": {"abc-123"}}, "", nil)
+	if id := w.Header().Get("X-Request-ID"); id != "abc-123" {
+		t.Errorf("Expected abc-123 but got %q", id)
+	}
+
+	// Otherwise, or if it does not look like an id, one is made
+	for _, sent := range []string{"", "not an id", strings.Repeat("a", 129)} {
+		w = callHandlerWithHeader(handler, "GET", "/nothing", http.Header{"X-Request-Id": {sent}}, "", nil)
+		if id := w.Header().Get("X-Request-ID"); !guidPattern.MatchString(id) {
+			t.Errorf("Expected a guid for %q but got %q", sent, id)
+		}
+	}
+}
+
+func TestLogFailure(t *testing.T) {
+	buf, restore := captureLog(t)
+	defer restore()
+
+	guid := "11111111-2222-3333-4444-555555555555"
+	handler := Instrument("GET", "/resources/:guid", func(c *eden.Context) {
+		respondError(c, errors.New("connection lost"), "An error has occurred")
+	})
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: guid}}
+	w := callHandlerWithHeader(handler, "GET", "/resources/"+guid, http.Header{"X-Request-Id": {"abc-123"}}, "", params)
+
+	// The client is told which request failed, but not why
+	if w.Code != 500 {
+		t.Errorf("Expected 500 but got %d", w.Code)
+	}
+	var output testResponseFailure
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := serviceError{"An error has occurred", "abc-123"}
+	if output.Data != expected {
+		t.Errorf("Expected %v but got %v", expected, output.Data)
+	}
+
+	// The log has the cause, then the request
+	lines := logLines(t, buf)
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines but got %v", lines)
+	}
+	for _, field := range []string{"requestId", "route", "guid", "duration", "error"} {
+		if _, ok := lines[0][field]; !ok {
+			t.Errorf("Expected %s in %v", field, lines[0])
+		}
+	}
+	if lines[0]["level"] != "ERROR" || lines[0]["error"] != "connection lost" || lines[0]["guid"] != guid || lines[0]["route"] != "/resources/:guid" {
+		t.Errorf("Expected the failure logged as an error but got %v", lines[0])
+	}
+	if lines[1]["msg"] != "request" || lines[1]["status"] != float64(500) || lines[1]["requestId"] != "abc-123" {
+		t.Errorf("Expected the request logged but got %v", lines[1])
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	if _, err := NewLogger(&bytes.Buffer{}, "loud"); err == nil {
+		t.Errorf("Expected an error for an unknown level but got none")
+	}
+
+	// Lines below the level are dropped
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, "warn")
+	if err != nil {
+		t.Fatalf("An unexpected error occurred: %v", err)
+	}
+	logger.Info("dropped")
+	logger.Warn("kept")
+	if lines := logLines(t, &buf); len(lines) != 1 || lines[0]["msg"] != "kept" {
+		t.Errorf("Expected only the warning but got %v", lines)
+	}
+}
@@ -0,0 +1,117 @@
+package apis
+
+import (
+	"database/sql"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The metrics served by GET /metrics.
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "resources_http_requests_total",
+		Help: "Requests handled, by method, route and response code.",
+	}, []string{"method", "route", "code"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "resources_http_request_duration_seconds",
+		Help:    "Time taken to handle requests, by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	storeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "resources_store_duration_seconds",
+		Help:    "Time taken by calls to the store, by accessor and method.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"accessor", "method"})
+
+	guidFallbacks = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "resources_guid_fallbacks_total",
+		Help: "Guids made locally because the guid service failed.",
+	}, func() float64 {
+		return float64(accessors.GuidFallbacks())
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		httpRequests,
+		httpDuration,
+		storeDuration,
+		guidFallbacks,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Adds the connection pool statistics of db to the metrics, as the
+//   go_sql_* metrics labelled with db_name="resources".
+func registerDBStats(db *sql.DB) error {
+	return registry.Register(collectors.NewDBStatsCollector(db, "resources"))
+}
+
+// Wraps s so the time taken by each of its calls is recorded.
+func timed(s accessors.Store) accessors.Store {
+	return accessors.NewTimedStore(s, func(accessor, method string, took time.Duration) {
+		storeDuration.WithLabelValues(accessor, method).Observe(took.Seconds())
+	})
+}
+
+// A ResponseWriter that remembers the status code written to it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Returns h counting, timing and logging its requests under method and
+//   route, the path the handler is registered for (such as
+//   "/resources/:guid"), so requests for different guids fall under one
+//   route. Each request is given an id, as by RequestID.
+func Instrument(method, route string, h func(*eden.Context)) func(*eden.Context) {
+	return func(c *eden.Context) {
+		start := time.Now()
+		withInfo(c, requestInfo{requestID(c), method, route, start})
+		w := &statusRecorder{ResponseWriter: c.Response}
+		c.Response = w
+		defer func() {
+			c.Response = w.ResponseWriter
+			if w.status == 0 {
+				w.status = http.StatusOK
+			}
+			httpRequests.WithLabelValues(method, route, strconv.Itoa(w.status)).Inc()
+			httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			logRequest(c, w.status)
+		}()
+		h(c)
+	}
+}
+
+var metricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+
+// Serves the metrics in the Prometheus text format.
+// GET /metrics
+func Metrics(c *eden.Context) {
+	metricsHandler.ServeHTTP(c.Response, c.Request)
+}
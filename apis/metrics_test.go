@@ -0,0 +1,55 @@
+package apis
+
+import (
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+)
+
+func TestInstrument(t *testing.T) {
+	api := &Api{timed(accessors.NewMemoryStore())}
+	handler := Instrument("GET", "/resources/:guid", api.GetResource)
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/resources/:guid", "404"))
+
+	// Requests are counted under their route and response code
+	for _, guid := range []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"} {
+		if w := callHandler(handler, "GET", "/resources/"+guid, "", "", httprouter.Params{httprouter.Param{Key: "guid", Value: guid}}); w.Code != 404 {
+			t.Errorf("Expected 404 but got %d", w.Code)
+		}
+	}
+	if count := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/resources/:guid", "404")) - before; count != 2 {
+		t.Errorf("Expected 2 but got %v", count)
+	}
+
+	// A handler that writes no header is a 200
+	before = testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/nothing", "200"))
+	handler = Instrument("GET", "/nothing", func(c *eden.Context) {})
+	callHandler(handler, "GET", "/nothing", "", "", nil)
+	if count := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/nothing", "200")) - before; count != 1 {
+		t.Errorf("Expected 1 but got %v", count)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	api := &Api{timed(accessors.NewMemoryStore())}
+	callHandler(Instrument("GET", "/resources", api.GetAllResources), "GET", "/resources", "", "", nil)
+
+	w := callHandler(Metrics, "GET", "/metrics", "", "", nil)
+	if w.Code != 200 {
+		t.Errorf("Expected 200 but got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, metric := range []string{
+		`resources_http_requests_total{code="200",method="GET",route="/resources"}`,
+		`resources_http_request_duration_seconds_count{method="GET",route="/resources"}`,
+		`resources_store_duration_seconds_count{accessor="resources",method="List"}`,
+		`resources_guid_fallbacks_total`,
+	} {
+		if !strings.Contains(body, metric) {
+			t.Errorf("Expected %s in the metrics but got %s", metric, body)
+		}
+	}
+}
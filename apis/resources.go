@@ -1,131 +1,313 @@
 package apis
 
 import (
+	"errors"
+	"fmt"
 	eden "github.com/byu-oit-ssengineering/tmt-eden"
 	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"strconv"
 )
 
-// Get all the resources.
-// GET /resources
+// Page sizes of GET /resources.
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// Get one page of the resources.
+// GET /resources?limit=:limit&cursor=:next&sort=:sort&name=:name&apiEndpoint=:prefix
+//   Resources are sorted by "created" (the default), "-created", "name" or
+//   "-name". The response holds the page, the total number of matching
+//   resources and, unless this is the last page, the cursor of the next.
+//   The response is a 304 if If-None-Match or If-Modified-Since shows the
+//   client's copy is current.
 func (a *Api) GetAllResources(c *eden.Context) {
-	ra := accessors.NewResourceAccessor(a.DB)
-	va := accessors.NewResourceVerbAccessor(a.DB)
+	ra := a.Store.Resources()
+	va := a.Store.Verbs()
+
+	// Parse the query
+	params := c.Request.URL.Query()
+	q := accessors.ResourceQuery{
+		Name:              params.Get("name"),
+		APIEndpointPrefix: params.Get("apiEndpoint"),
+		Sort:              params.Get("sort"),
+		Limit:             defaultPageSize,
+		Cursor:            params.Get("cursor"),
+	}
+	if limit := params.Get("limit"); limit != "" {
+		var err error
+		if q.Limit, err = strconv.Atoi(limit); err != nil || q.Limit < 1 || q.Limit > maxPageSize {
+			respondFailure(c, 400, fmt.Sprintf("limit must be a number from 1 to %d", maxPageSize))
+			return
+		}
+	}
 
-	resources, err := ra.GetAll()
+	// The catalog is unchanged if no resource has been modified since
+	modified, err := ra.LastModified()
 	if err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error occurred while retrieving resources"})
+		respondError(c, err, "An error occurred while retrieving resources")
+		return
+	}
+	if notModified(c, "W/"+strconv.Quote(syncToken(modified, nil)), modified) {
+		return
+	}
+
+	page, err := ra.List(q)
+	switch err {
+	case nil:
+	case accessors.ErrInvalidSort:
+		respondFailure(c, 400, "sort must be one of created, -created, name or -name")
+		return
+	case accessors.ErrInvalidCursor:
+		respondFailure(c, 400, "Invalid cursor")
+		return
+	default:
+		respondError(c, err, "An error occurred while retrieving resources")
 		return
 	}
 
-	for i := 0; i < len(resources); i++ {
-		resources[i].Verbs, _ = va.GetByResource(resources[i].Guid)
+	// Load the verbs of the whole page at once
+	guids := make([]string, len(page.Resources))
+	for i, r := range page.Resources {
+		guids[i] = r.Guid
+	}
+	verbs, err := va.GetByResources(guids)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving resources")
+		return
+	}
+	for i := 0; i < len(page.Resources); i++ {
+		page.Resources[i].Verbs = verbs[page.Resources[i].Guid]
 	}
 
 	// Respond
-	c.Respond(200, eden.Response{"OK", resources})
+	c.Respond(200, eden.Response{"OK", page})
 }
 
 // Gets a resource by guid.
 // GET /resources/:guid
+//   Its verbs are its effective verbs: its own and those inherited from
+//   its type chain, each telling where it comes from. The response is a
+//   304 if If-None-Match or If-Modified-Since shows the client's copy is
+//   current. GET /resources/changes is served here too, as the router
+//   cannot tell it apart from a guid.
 func (a *Api) GetResource(c *eden.Context) {
 	// Create new resource accessor
-	ra := accessors.NewResourceAccessor(a.DB)
-	va := accessors.NewResourceVerbAccessor(a.DB)
+	ra := a.Store.Resources()
+	va := a.Store.Verbs()
 
 	// Parse the resource guid
-	guid := c.Params[0].Value
+	if c.Params[0].Value == "changes" {
+		a.GetChanges(c)
+		return
+	}
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
 
 	// Get the resource
 	resource, err := ra.Get(guid)
 	if err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error occurred while retrieving resource information"})
+		respondError(c, err, "An error occurred while retrieving resource information")
 		return
 	}
 
-	resource.Verbs, _ = va.GetByResource(resource.Guid)
+	// Its verbs include those inherited from its types
+	inheritance, err := va.GetEffective(resource.Guid)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving resource information")
+		return
+	}
+
+	tag, modified := inheritedTag(resource, inheritance.Chain)
+	if notModified(c, tag, modified) {
+		return
+	}
 
 	// Respond
-	c.Respond(200, eden.Response{"OK", resource})
+	c.Respond(200, eden.Response{"OK", effectiveResource{resource, inheritance.Verbs, inheritance.Suppressed}})
+}
+
+// A resource as GET /resources/:guid responds with it: with its effective
+//   verbs in place of its own and the verbs it suppresses.
+type effectiveResource struct {
+	accessors.Resource
+	Verbs      []accessors.EffectiveVerb   `json:"verbs"`
+	Suppressed []accessors.VerbSuppression `json:"suppressed"`
 }
 
 // Create a resource.
 // POST /resources name=:name, description=:description, api=:apiEndpoint
+//   or a JSON body {"name", "description", "apiEndpoint"}
+//   Every field is required and apiEndpoint must be an absolute URL; the
+//   response to fields that break the rules is a 422 listing them all.
 func (a *Api) InsertResource(c *eden.Context) {
-	// Create new resource accessor
-	ra := accessors.NewResourceAccessor(a.DB)
+	// Create new resource accessor, making changes as the requesting user
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Resources()
 
-	// Parse name and description from POST data.
-	c.Request.ParseForm()
-	name, nameOk := c.Request.Form["name"]
-	description, descriptionOk := c.Request.Form["description"]
-	api, apiOk := c.Request.Form["api"]
-	if !descriptionOk || !nameOk || !apiOk {
-		c.Respond(400, eden.Response{"ERROR", "Unable to process request"})
+	// Parse name, description and api endpoint from the POST data.
+	body, ok := readBody(c, resourceFields)
+	if !ok || !validate(c, body, resourceRules, false) {
 		return
 	}
 
-	resource := accessors.Resource{Name: name[0], Description: description[0], APIEndpoint: api[0]}
+	resource := accessors.Resource{Name: body["name"], Description: body["description"], APIEndpoint: body["apiEndpoint"]}
 
 	// Insert the resource and test for errors
-	if err := ra.Insert(resource); err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error has occurred"})
+	guid, err := ra.Insert(resource)
+	if err != nil {
+		respondError(c, err, "An error has occurred")
 		return
 	}
 
-	// Respond
-	c.Respond(200, eden.Response{"OK", "success"})
+	// Read it back for the values set by the store
+	if resource, err = ra.Get(guid); err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+	resource.Verbs = make([]accessors.ResourceVerb, 0)
+
+	// Respond with the new resource
+	c.Response.Header().Set("Location", "/resources/"+guid)
+	setETag(c, resource.Version)
+	c.Respond(201, eden.Response{"OK", resource})
 }
 
 // Update a resource's name and/or description.
 // PUT /resources/:guid name=:newName, description=:newDescription, api=:newApiEndpoint
+//   or a JSON body with any of {"name", "description", "apiEndpoint"}
+//   The fields sent are held to the rules of InsertResource. An If-Match
+//   header must match the resource's ETag. The response is a 412 if it
+//   does not, or if the resource changes while it is updated.
 func (a *Api) UpdateResource(c *eden.Context) {
-	// Create new resource accessor
-	ra := accessors.NewResourceAccessor(a.DB)
+	// Create new resource accessor, making changes as the requesting user
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Resources()
 
 	// Parse resource guid
-	guid := c.Params[0].Value
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	// Parse the fields to change
+	body, ok := readBody(c, resourceFields)
+	if !ok || !validate(c, body, resourceRules, true) {
+		return
+	}
 
 	// Get the resource
 	resource, err := ra.Get(guid)
 	if err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An unexpected error occurred"})
+		respondError(c, err, "An unexpected error occurred")
+		return
+	}
+	if !ifMatch(c, resource.Version) {
 		return
 	}
 
 	// Update given fields
-	c.Request.ParseForm()
-	if name, nameOk := c.Request.Form["name"]; nameOk {
-		resource.Name = name[0]
+	if name, nameOk := body["name"]; nameOk {
+		resource.Name = name
 	}
-	if description, descriptionOk := c.Request.Form["description"]; descriptionOk {
-		resource.Description = description[0]
+	if description, descriptionOk := body["description"]; descriptionOk {
+		resource.Description = description
 	}
-	if api, apiOk := c.Request.Form["api"]; apiOk {
-		resource.APIEndpoint = api[0]
+	if api, apiOk := body["apiEndpoint"]; apiOk {
+		resource.APIEndpoint = api
 	}
 
 	// Save
-	if err := ra.Update(resource); err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error has occurred"})
+	if err := ra.Update(resource); err == accessors.ErrVersionMismatch {
+		respondFailure(c, 412, "The resource has changed; get it again and retry")
+		return
+	} else if err != nil {
+		respondError(c, err, "An error has occurred")
 		return
 	}
+	resource.Version++
 
 	// Respond
+	setETag(c, resource.Version)
 	c.Respond(200, eden.Response{"OK", "success"})
 }
 
-// Delete a resource.
-// DELETE /resources/:guid
+// Body of the 409 response to deleting a resource that is still referred to.
+type deleteConflict struct {
+	Message    string               `json:"message"`
+	Dependents accessors.Dependents `json:"dependents"`
+}
+
+// Move a resource to the trash.
+// DELETE /resources/:guid?cascade=true
+//   Without cascade a resource that still has verbs or type associations is
+//   not deleted and the response is a 409 listing them. With cascade its
+//   verbs go to the trash with it. An If-Match header must match the
+//   resource's ETag. The response is a 412 if it does not, or if the
+//   resource changes while it is deleted.
 func (a *Api) DeleteResource(c *eden.Context) {
-	// Create new resource accessor
-	ra := accessors.NewResourceAccessor(a.DB)
+	// Create new resource accessor, making changes as the requesting user
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Resources()
 
 	// Parse resource id
-	guid := c.Params[0].Value
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	// Parse the cascade option
+	cascade := false
+	if value := c.Request.URL.Query().Get("cascade"); value != "" {
+		var err error
+		if cascade, err = strconv.ParseBool(value); err != nil {
+			respondFailure(c, 400, "cascade must be true or false")
+			return
+		}
+	}
+
+	// Get the resource as it was
+	resource, err := ra.Get(guid)
+	if err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+	if !ifMatch(c, resource.Version) {
+		return
+	}
 
 	// Delete the resource
-	if err := ra.Delete(guid); err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error has occurred"})
+	if cascade {
+		_, err = ra.DeleteCascade(guid, resource.Version)
+	} else {
+		err = ra.Delete(guid, resource.Version)
+	}
+
+	var dependents *accessors.DependentsError
+	switch {
+	case err == nil:
+	case err == accessors.ErrVersionMismatch:
+		respondFailure(c, 412, "The resource has changed; get it again and retry")
+		return
+	case errors.As(err, &dependents):
+		respondFailure(c, 409, deleteConflict{"The resource still has verbs or type associations; delete with cascade=true to remove them too", dependents.Dependents})
+		return
+	case errors.Is(err, accessors.ErrForeignKey):
+		respondFailure(c, 409, "The resource is still referred to")
+		return
+	default:
+		respondError(c, err, "An error has occurred")
 		return
 	}
 
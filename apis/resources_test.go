@@ -2,12 +2,15 @@ package apis
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	eden "github.com/byu-oit-ssengineering/tmt-eden"
 	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
 	testhelpers "github.com/byu-oit-ssengineering/tmt-test-helpers"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/julienschmidt/httprouter"
+	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -16,9 +19,9 @@ type testResponseResource struct {
 	Data   accessors.Resource
 }
 
-type testResponseResourceArray struct {
+type testResponseResourcePage struct {
 	Status string
-	Data   []accessors.Resource
+	Data   accessors.ResourcePage
 }
 
 func TestGetResource(t *testing.T) {
@@ -27,21 +30,29 @@ func TestGetResource(t *testing.T) {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
-	expected := accessors.Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "tmt.byu.edu/resources",
-		[]accessors.ResourceVerb{accessors.ResourceVerb{"22222222-2222-2222-2222-222222222222", "11111111-2222-3333-4444-555555555555", "edit", "can edit"}}}
-	columns := []string{"guid", "name", "description", "apiEndpoint"}
+	expected := accessors.Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "https://tmt.byu.edu/resources", testTime, testTime, 1,
+		[]accessors.ResourceVerb{accessors.ResourceVerb{"22222222-2222-2222-2222-222222222222", "11111111-2222-3333-4444-555555555555", "edit", "can edit", 1}}}
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resources WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.)").
 		WithArgs("11111111-2222-3333-4444-555555555555").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
 
-	columns = []string{"guid", "resourceGUID", "name", "description"}
+	columns = []string{"guid", "resourceGUID", "verb", "description", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resourceVerbs WHERE resourceGUID=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID IN \\((.)\\)").
 		WithArgs("11111111-2222-3333-4444-555555555555").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("22222222-2222-2222-2222-222222222222,11111111-2222-3333-4444-555555555555,edit,can edit"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("22222222-2222-2222-2222-222222222222,11111111-2222-3333-4444-555555555555,edit,can edit,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM suppressedVerbs WHERE resourceGUID IN \\((.)\\)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceTypes JOIN resources ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID IN \\((.)\\)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"resourceGUID", "guid", "name", "description", "apiEndpoint", "created", "modified", "version"}))
 
 	// Create context, call API
 	var result []byte
@@ -67,35 +78,38 @@ func TestGetAllResources(t *testing.T) {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
 	expected := []accessors.Resource{
-		accessors.Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "tmt.byu.edu/resources",
-			[]accessors.ResourceVerb{accessors.ResourceVerb{"22222222-2222-2222-2222-222222222222", "11111111-2222-3333-4444-555555555555", "edit", "can edit"}}},
-		accessors.Resource{"00000000-9999-8888-7777-666666666666", "testing", "for testing purposes", "tmt.byu.edu/resources",
-			[]accessors.ResourceVerb{accessors.ResourceVerb{"33333333-3333-3333-3333-333333333333", "00000000-9999-8888-7777-666666666666", "edit", "can edit"}}},
+		accessors.Resource{"11111111-2222-3333-4444-555555555555", "test", "this is a test", "https://tmt.byu.edu/resources", testTime, testTime, 1,
+			[]accessors.ResourceVerb{accessors.ResourceVerb{"22222222-2222-2222-2222-222222222222", "11111111-2222-3333-4444-555555555555", "edit", "can edit", 1}}},
+		accessors.Resource{"00000000-9999-8888-7777-666666666666", "testing", "for testing purposes", "https://tmt.byu.edu/resources", testTime, testTime, 1,
+			[]accessors.ResourceVerb{accessors.ResourceVerb{"33333333-3333-3333-3333-333333333333", "00000000-9999-8888-7777-666666666666", "edit", "can edit", 1}}},
 	}
-	columns := []string{"guid", "name", "description", "apiEndpoint"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resources").
+	sqlmock.ExpectQuery("SELECT MAX\\(modified\\) FROM \\(SELECT MAX\\(modified\\) AS modified FROM resources UNION ALL SELECT MAX\\(changed\\) FROM auditLog WHERE entity=(.) UNION ALL SELECT MAX\\(changed\\) FROM auditLog WHERE entity=(.)\\) latest").
+		WithArgs("resource", "verb").
+		WillReturnRows(sqlmock.NewRows([]string{"modified"}).FromCSVString("2016-01-01 00:00:00"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources").
 		WithArgs().
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources\n00000000-9999-8888-7777-666666666666,testing,for testing purposes,tmt.byu.edu/resources"))
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).FromCSVString("2"))
 
-	columns = []string{"guid", "resourceGUID", "name", "description"}
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resourceVerbs WHERE resourceGUID=(.)").
-		WithArgs("11111111-2222-3333-4444-555555555555").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("22222222-2222-2222-2222-222222222222,11111111-2222-3333-4444-555555555555,edit,can edit"))
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE deleted IS NULL ORDER BY created ASC, guid ASC LIMIT (.)").
+		WithArgs(101).
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1\n00000000-9999-8888-7777-666666666666,testing,for testing purposes,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
 
-	columns = []string{"guid", "resourceGUID", "name", "description"}
+	columns = []string{"guid", "resourceGUID", "verb", "description", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resourceVerbs WHERE resourceGUID=(.)").
-		WithArgs("00000000-9999-8888-7777-666666666666").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("33333333-3333-3333-3333-333333333333,00000000-9999-8888-7777-666666666666,edit,can edit"))
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID IN \\((.),(.)\\)").
+		WithArgs("11111111-2222-3333-4444-555555555555", "00000000-9999-8888-7777-666666666666").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("22222222-2222-2222-2222-222222222222,11111111-2222-3333-4444-555555555555,edit,can edit,1\n33333333-3333-3333-3333-333333333333,00000000-9999-8888-7777-666666666666,edit,can edit,1"))
 
 	// Create context, call API
 	var result []byte
-	var output testResponseResourceArray
+	var output testResponseResourcePage
 	c := testhelpers.NewTestingContext("", nil, api.GetAllResources)
 	testhelpers.CallAPI(api.GetAllResources, c, &result)
 
@@ -106,33 +120,84 @@ func TestGetAllResources(t *testing.T) {
 	}
 
 	// Compare output to expected output
+	if len(output.Data.Resources) != len(expected) || output.Data.Total != 2 || output.Data.Next != "" {
+		t.Errorf("Expected a single page of 2 resources but got %v", output.Data)
+		return
+	}
 	for i := 0; i < len(expected); i++ {
-		if !expected[i].Equals(output.Data[i]) {
-			t.Errorf("Expected: %v, but got %v instead", expected, output.Data)
+		if !expected[i].Equals(output.Data.Resources[i]) {
+			t.Errorf("Expected: %v, but got %v instead", expected, output.Data.Resources)
 		}
 	}
 }
 
+func TestGetAllResourcesVerbError(t *testing.T) {
+	db, err := testhelpers.GetMockDB()
+	if err != nil {
+		t.Error("An unexpected error occurred instantiating accessor")
+		return
+	}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
+
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT MAX\\(modified\\) FROM \\(SELECT MAX\\(modified\\) AS modified FROM resources UNION ALL SELECT MAX\\(changed\\) FROM auditLog WHERE entity=(.) UNION ALL SELECT MAX\\(changed\\) FROM auditLog WHERE entity=(.)\\) latest").
+		WithArgs("resource", "verb").
+		WillReturnRows(sqlmock.NewRows([]string{"modified"}).FromCSVString("2016-01-01 00:00:00"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources").
+		WithArgs().
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).FromCSVString("1"))
+
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE deleted IS NULL ORDER BY created ASC, guid ASC LIMIT (.)").
+		WithArgs(101).
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
+
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID IN \\((.)\\)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnError(fmt.Errorf("connection lost"))
+
+	// A failure loading the verbs fails the request instead of dropping them
+	w := callHandler(api.GetAllResources, "GET", "/resources", "", "", nil)
+	if w.Code != 500 {
+		t.Errorf("Expected 500 but got %d", w.Code)
+	}
+}
+
 func TestInsertResource(t *testing.T) {
 	accessors.NewGuid = func() string {
 		return "123def"
 	}
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
+	sqlmock.ExpectBegin()
 	sqlmock.ExpectPrepare()
 	sqlmock.ExpectExec("INSERT INTO resources .+ VALUES .+").
-		WithArgs("123def", "test", "This is a test", "tmt.byu.edu/resources").
+		WithArgs("123def", "test", "This is a test", "https://tmt.byu.edu/resources", testTime, testTime).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
+
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.)").
+		WithArgs("123def").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("123def,test,This is a test,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
 
 	// Create context and call API
 	var result []byte
-	var output eden.Response
-	c := testhelpers.NewTestingContext("name=test&description=This%20is%20a%20test&api=tmt.byu.edu/resources", nil, api.InsertResource)
+	var output testResponseResource
+	c := testhelpers.NewTestingContext("name=test&description=This%20is%20a%20test&api=https://tmt.byu.edu/resources", nil, api.InsertResource)
 	testhelpers.CallAPI(api.InsertResource, c, &result)
 
 	// Parse output
@@ -141,9 +206,10 @@ func TestInsertResource(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 
-	// Ensure correct output
-	if output.Data != "success" {
-		t.Errorf("expected to get 'success' but got %v instead", output.Data)
+	// Ensure the created resource is returned
+	expected := accessors.Resource{"123def", "test", "This is a test", "https://tmt.byu.edu/resources", testTime, testTime, 1, []accessors.ResourceVerb{}}
+	if !expected.Equals(output.Data) {
+		t.Errorf("Expected: %v, but got %v instead", expected, output.Data)
 	}
 }
 
@@ -153,22 +219,31 @@ func TestUpdateResource(t *testing.T) {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
-	columns := []string{"guid", "name", "description", "apiEndpoint"}
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resources WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.)").
 		WithArgs("11111111-2222-3333-4444-555555555555").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,tmt.byu.edu/resources"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
+	sqlmock.ExpectBegin()
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectExec("UPDATE resources SET name=(.), description=(.), apiEndpoint=(.) WHERE guid=(.)").
-		WithArgs("changed", "testing", "tmt.byu.edu/resources", "11111111-2222-3333-4444-555555555555").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET name=(.), description=(.), apiEndpoint=(.), version=version\\+1, modified=(.) WHERE guid=(.) AND version=(.)").
+		WithArgs("changed", "testing", "https://tmt.byu.edu/resources", testTime, "11111111-2222-3333-4444-555555555555", 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
 	// Create context and call API
 	var result []byte
 	var output eden.Response
-	c := testhelpers.NewTestingContext("name=changed&description=testing&api=tmt.byu.edu/resources", httprouter.Params{httprouter.Param{Key: "guid", Value: "11111111-2222-3333-4444-555555555555"}}, api.UpdateResource)
+	c := testhelpers.NewTestingContext("name=changed&description=testing&api=https://tmt.byu.edu/resources", httprouter.Params{httprouter.Param{Key: "guid", Value: "11111111-2222-3333-4444-555555555555"}}, api.UpdateResource)
 	testhelpers.CallAPI(api.UpdateResource, c, &result)
 
 	// Parse output
@@ -184,17 +259,39 @@ func TestUpdateResource(t *testing.T) {
 }
 
 func TestDeleteResource(t *testing.T) {
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectExec("DELETE FROM resources WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.)").
 		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}).FromCSVString("11111111-2222-3333-4444-555555555555,test,this is a test,https://tmt.byu.edu/resources,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID=(.) AND deleted IS NULL ORDER BY guid").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceTypes WHERE resourceGUID=(.) OR type=(.) ORDER BY guid").
+		WithArgs("11111111-2222-3333-4444-555555555555", "11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "type"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET deleted=(.), modified=(.) WHERE guid=(.) AND version=(.) AND deleted IS NULL").
+		WithArgs(testTime, testTime, "11111111-2222-3333-4444-555555555555", 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
 	// Create context and call API
 	var result []byte
@@ -213,3 +310,146 @@ func TestDeleteResource(t *testing.T) {
 		t.Errorf("expected to get 'success' but got %v instead", output.Data)
 	}
 }
+
+func TestResourcesWithMemoryStore(t *testing.T) {
+	stopClock()
+	accessors.NewGuid = func() string {
+		return "11111111-2222-3333-4444-555555555555"
+	}
+	api := &Api{accessors.NewMemoryStore()}
+
+	// Create a resource
+	w := callHandler(api.InsertResource, "POST", "/resources", "application/x-www-form-urlencoded", "name=test&description=This%20is%20a%20test&api=https://tmt.byu.edu/resources", nil)
+	if w.Code != 201 {
+		t.Errorf("Expected 201 but got %d", w.Code)
+	}
+	if location := w.Header().Get("Location"); location != "/resources/11111111-2222-3333-4444-555555555555" {
+		t.Errorf("Expected Location /resources/11111111-2222-3333-4444-555555555555 but got %v", location)
+	}
+
+	var created testResponseResource
+	expected := accessors.Resource{"11111111-2222-3333-4444-555555555555", "test", "This is a test", "https://tmt.byu.edu/resources", testTime, testTime, 1, []accessors.ResourceVerb{}}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Errorf(err.Error())
+	}
+	if !expected.Equals(created.Data) {
+		t.Errorf("Expected: %v, but got %v", expected, created.Data)
+	}
+
+	// Read it back
+	var result []byte
+	var resource testResponseResource
+	c := testhelpers.NewTestingContext("", httprouter.Params{httprouter.Param{Key: "guid", Value: "11111111-2222-3333-4444-555555555555"}}, api.GetResource)
+	testhelpers.CallAPI(api.GetResource, c, &result)
+
+	err := json.Unmarshal(result, &resource)
+	if err != nil {
+		t.Errorf(err.Error())
+	}
+	if !expected.Equals(resource.Data) {
+		t.Errorf("Expected: %v, but got %v", expected, resource.Data)
+	}
+}
+
+func TestListResourcesWithMemoryStore(t *testing.T) {
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	stopClock()
+	api := &Api{accessors.NewMemoryStore()}
+	for _, name := range []string{"c", "a", "b"} {
+		api.Store.Resources().Insert(accessors.Resource{Name: name, APIEndpoint: "https://tmt.byu.edu/" + name})
+	}
+
+	// Walk the pages sorted by name
+	names := make([]string, 0)
+	target := "/resources?sort=name&limit=2"
+	for i := 0; i < 3; i++ {
+		w := callHandler(api.GetAllResources, "GET", target, "", "", nil)
+		if w.Code != 200 {
+			t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+		}
+		var output testResponseResourcePage
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if output.Data.Total != 3 {
+			t.Errorf("Expected total 3 but got %d", output.Data.Total)
+		}
+		for _, r := range output.Data.Resources {
+			names = append(names, r.Name)
+		}
+		if output.Data.Next == "" {
+			break
+		}
+		target = "/resources?sort=name&limit=2&cursor=" + url.QueryEscape(output.Data.Next)
+	}
+	if strings.Join(names, ",") != "a,b,c" {
+		t.Errorf("Expected a,b,c but got %v", names)
+	}
+
+	// Filters
+	w := callHandler(api.GetAllResources, "GET", "/resources?apiEndpoint=https://tmt.byu.edu/b", "", "", nil)
+	var output testResponseResourcePage
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if output.Data.Total != 1 || len(output.Data.Resources) != 1 || output.Data.Resources[0].Name != "b" {
+		t.Errorf("Expected only resource b but got %v", output.Data)
+	}
+
+	// Bad parameters
+	for _, target := range []string{
+		"/resources?limit=0",
+		"/resources?limit=1001",
+		"/resources?limit=ten",
+		"/resources?sort=size",
+		"/resources?cursor=bogus",
+	} {
+		if w := callHandler(api.GetAllResources, "GET", target, "", "", nil); w.Code != 400 {
+			t.Errorf("Expected 400 for %s but got %d", target, w.Code)
+		}
+	}
+}
+
+func TestDeleteResourceWithMemoryStore(t *testing.T) {
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	api.Store.Resources().Insert(accessors.Resource{Name: "whiteboard", APIEndpoint: "https://tmt.byu.edu/whiteboards"})
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000001"}}
+
+	// Refused while the verb exists
+	w := callHandler(api.DeleteResource, "DELETE", "/resources/00000000-0000-0000-0000-000000000001", "", "", params)
+	if w.Code != 409 {
+		t.Errorf("Expected 409 but got %d", w.Code)
+	}
+	var conflict struct {
+		Status string
+		Data   deleteConflict
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &conflict); err != nil {
+		t.Errorf(err.Error())
+	}
+	if len(conflict.Data.Dependents.Verbs) != 1 || conflict.Data.Dependents.Verbs[0].Guid != "00000000-0000-0000-0000-000000000002" {
+		t.Errorf("Expected the verb to be listed but got %v", conflict.Data.Dependents)
+	}
+
+	if w := callHandler(api.DeleteResource, "DELETE", "/resources/00000000-0000-0000-0000-000000000001?cascade=maybe", "", "", params); w.Code != 400 {
+		t.Errorf("Expected 400 but got %d", w.Code)
+	}
+
+	// Deleted with its verb on cascade
+	if w := callHandler(api.DeleteResource, "DELETE", "/resources/00000000-0000-0000-0000-000000000001?cascade=true", "", "", params); w.Code != 200 {
+		t.Errorf("Expected 200 but got %d", w.Code)
+	}
+	if _, err := api.Store.Verbs().Get("00000000-0000-0000-0000-000000000002"); err == nil {
+		t.Error("Expected the verb to be deleted")
+	}
+}
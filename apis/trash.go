@@ -0,0 +1,164 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Defaults of the trash purge job.
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval  = time.Hour
+)
+
+// List the deleted resources and verbs that have not been purged yet.
+// GET /trash
+func (a *Api) GetTrash(c *eden.Context) {
+	trash, err := a.Store.Trash().Get()
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving the trash")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", trash})
+}
+
+// Take a resource out of the trash, along with the verbs deleted with it.
+// POST /resources/:guid/restore
+func (a *Api) RestoreResource(c *eden.Context) {
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Resources()
+	va := store.Verbs()
+
+	// Parse the resource guid
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	// Restore the resource
+	if err := ra.Restore(guid); errors.Is(err, accessors.ErrNotFound) {
+		respondFailure(c, 404, "The resource is not in the trash")
+		return
+	} else if err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+
+	// Respond with the restored resource
+	resource, err := ra.Get(guid)
+	if err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+	if resource.Verbs, err = va.GetByResource(guid); err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+	setETag(c, resource.Version)
+	c.Respond(200, eden.Response{"OK", resource})
+}
+
+// Take a verb out of the trash. Its resource must not be in the trash.
+// POST /verbs/:guid/restore
+func (a *Api) RestoreVerb(c *eden.Context) {
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	va := store.Verbs()
+
+	// Parse the verb guid
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	// Restore the verb
+	switch err := va.Restore(guid); {
+	case err == nil:
+	case errors.Is(err, accessors.ErrNotFound):
+		respondFailure(c, 404, "The verb is not in the trash")
+		return
+	case err == accessors.ErrForeignKey:
+		respondFailure(c, 409, "The verb's resource is in the trash; restore the resource first")
+		return
+	default:
+		respondError(c, err, "An error has occurred")
+		return
+	}
+
+	// Respond with the restored verb
+	verb, err := va.Get(guid)
+	if err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+	setETag(c, verb.Version)
+	c.Respond(200, eden.Response{"OK", verb})
+}
+
+// Permanently deletes everything that has been in the trash for longer than
+//   retention, and returns how many resources and verbs were deleted.
+func (a *Api) PurgeTrash(retention time.Duration) (int, error) {
+	return a.Store.Trash().Purge(accessors.Now().Add(-retention))
+}
+
+// Starts purging the trash in the background. How long deleted rows are
+//   kept is read from TRASH_RETENTION (default 720h) and how often the
+//   trash is purged from TRASH_PURGE_INTERVAL (default 1h); an interval of
+//   0 turns purging off. Call the returned function to stop.
+func (a *Api) StartPurgeJob() (stop func(), err error) {
+	retention, err := durationEnv("TRASH_RETENTION", defaultTrashRetention)
+	if err != nil {
+		return nil, err
+	}
+	interval, err := durationEnv("TRASH_PURGE_INTERVAL", defaultPurgeInterval)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return func() {}, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if purged, err := a.PurgeTrash(retention); err != nil {
+					slog.Error("purging the trash failed", "error", err)
+				} else if purged > 0 {
+					slog.Info("purged the trash", "purged", purged)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }, nil
+}
+
+// Reads a duration such as "72h" from the environment.
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q: use a duration such as 720h", name, value)
+	}
+	return d, nil
+}
@@ -0,0 +1,132 @@
+package apis
+
+import (
+	"encoding/json"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	"github.com/julienschmidt/httprouter"
+	"os"
+	"testing"
+	"time"
+)
+
+type testResponseTrash struct {
+	Status string
+	Data   accessors.Trash
+}
+
+// Returns a memory backed api holding a resource with one verb, both
+//   deleted.
+func newTrashApi() *Api {
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	stopClock()
+	api := &Api{accessors.NewMemoryStore()}
+	api.Store.Resources().Insert(accessors.Resource{Name: "whiteboard", APIEndpoint: "https://tmt.byu.edu/whiteboards"})
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	api.Store.Resources().DeleteCascade("00000000-0000-0000-0000-000000000001", 2)
+	return api
+}
+
+func TestGetTrash(t *testing.T) {
+	api := newTrashApi()
+
+	w := callHandler(api.GetTrash, "GET", "/trash", "", "", nil)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	var output testResponseTrash
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(output.Data.Resources) != 1 || output.Data.Resources[0].Name != "whiteboard" || !output.Data.Resources[0].Deleted.Equal(testTime) {
+		t.Errorf("Expected the whiteboard but got %v", output.Data.Resources)
+	}
+	if len(output.Data.Verbs) != 1 || output.Data.Verbs[0].Verb != "erase" {
+		t.Errorf("Expected the erase verb but got %v", output.Data.Verbs)
+	}
+}
+
+func TestRestoreWithMemoryStore(t *testing.T) {
+	api := newTrashApi()
+	resourceParams := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000001"}}
+	verbParams := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000002"}}
+
+	// A verb cannot come back before its resource
+	if w := callHandler(api.RestoreVerb, "POST", "/verbs/00000000-0000-0000-0000-000000000002/restore", "", "", verbParams); w.Code != 409 {
+		t.Errorf("Expected 409 but got %d", w.Code)
+	}
+
+	// Restoring the resource brings its verb back too
+	w := callHandler(api.RestoreResource, "POST", "/resources/00000000-0000-0000-0000-000000000001/restore", "", "", resourceParams)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	var output testResponseResource
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Fatalf(err.Error())
+	}
+	expected := accessors.Resource{"00000000-0000-0000-0000-000000000001", "whiteboard", "", "https://tmt.byu.edu/whiteboards", testTime, testTime, 2,
+		[]accessors.ResourceVerb{accessors.ResourceVerb{"00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001", "erase", "can erase", 1}}}
+	if !expected.Equals(output.Data) {
+		t.Errorf("Expected %v but got %v", expected, output.Data)
+	}
+
+	// Nothing left to restore
+	if w := callHandler(api.RestoreResource, "POST", "/resources/00000000-0000-0000-0000-000000000001/restore", "", "", resourceParams); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+	if w := callHandler(api.RestoreVerb, "POST", "/verbs/00000000-0000-0000-0000-000000000002/restore", "", "", verbParams); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+
+	// A verb removed on its own can be restored on its own
+	api.Store.Verbs().Remove("00000000-0000-0000-0000-000000000002", 1)
+	if w := callHandler(api.RestoreVerb, "POST", "/verbs/00000000-0000-0000-0000-000000000002/restore", "", "", verbParams); w.Code != 200 {
+		t.Errorf("Expected 200 but got %d", w.Code)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	api := newTrashApi()
+
+	// Not old enough yet
+	accessors.Now = func() time.Time {
+		return testTime.Add(time.Hour)
+	}
+	if purged, err := api.PurgeTrash(2 * time.Hour); err != nil || purged != 0 {
+		t.Errorf("Expected 0 purged but got %d, %v", purged, err)
+	}
+
+	accessors.Now = func() time.Time {
+		return testTime.Add(3 * time.Hour)
+	}
+	if purged, err := api.PurgeTrash(2 * time.Hour); err != nil || purged != 2 {
+		t.Errorf("Expected 2 purged but got %d, %v", purged, err)
+	}
+	if trash, _ := api.Store.Trash().Get(); len(trash.Resources) != 0 || len(trash.Verbs) != 0 {
+		t.Errorf("Expected an empty trash but got %v", trash)
+	}
+}
+
+func TestStartPurgeJobConfig(t *testing.T) {
+	api := &Api{accessors.NewMemoryStore()}
+	defer os.Unsetenv("TRASH_RETENTION")
+	defer os.Unsetenv("TRASH_PURGE_INTERVAL")
+
+	os.Setenv("TRASH_RETENTION", "a week")
+	if _, err := api.StartPurgeJob(); err == nil {
+		t.Error("Expected an error for an invalid TRASH_RETENTION")
+	}
+
+	os.Setenv("TRASH_RETENTION", "168h")
+	os.Setenv("TRASH_PURGE_INTERVAL", "0")
+	stop, err := api.StartPurgeJob()
+	if err != nil {
+		t.Errorf("An unexpected error occurred starting the purge job: %v", err)
+	}
+	stop()
+}
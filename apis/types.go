@@ -7,17 +7,22 @@ import (
 
 // Get the type of the given resource guid.
 // GET /type/:guid
+//   A resource with several types responds with the first by association
+//   guid; GET /resources/:guid/types lists them all.
 func (a *Api) GetResourceType(c *eden.Context) {
 	// Create new resourceType accessor
-	ra := accessors.NewResourceTypeAccessor(a.DB)
+	ra := a.Store.Types()
 
 	// Parse the resourceType guid
-	guid := c.Params[0].Value
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
 
 	// Get the resourceType
 	resourceType, err := ra.GetType(guid)
 	if err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error occurred while retrieving resourceType information"})
+		respondError(c, err, "An error occurred while retrieving resourceType information")
 		return
 	}
 
@@ -25,27 +30,142 @@ func (a *Api) GetResourceType(c *eden.Context) {
 	c.Respond(200, eden.Response{"OK", resourceType})
 }
 
+// Get every type of a resource, each with its association.
+// GET /resources/:guid/types
+func (a *Api) GetResourceTypes(c *eden.Context) {
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	if _, err := a.Store.Resources().Get(guid); err != nil {
+		respondError(c, err, "An error occurred while retrieving resourceType information")
+		return
+	}
+
+	types, err := a.Store.Types().GetTypes(guid)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving resourceType information")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", types})
+}
+
+// Get one type association of a resource, with the type in full.
+// GET /resources/:guid/types/:typeGuid
+func (a *Api) GetResourceTypeAssociation(c *eden.Context) {
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+	typeGUID, ok := guidParam(c, 1)
+	if !ok {
+		return
+	}
+
+	if _, err := a.Store.Resources().Get(guid); err != nil {
+		respondError(c, err, "An error occurred while retrieving resourceType information")
+		return
+	}
+
+	types, err := a.Store.Types().GetTypes(guid)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving resourceType information")
+		return
+	}
+	for _, t := range types {
+		if t.Type == typeGUID {
+			c.Respond(200, eden.Response{"OK", t})
+			return
+		}
+	}
+	respondError(c, &accessors.NotFoundError{accessors.EntityType, typeGUID}, "")
+}
+
+// Get every resource of a type with its verbs, in the order they were
+//   created. For example, every whiteboard.
+// GET /types/:typeGuid/resources
+func (a *Api) GetResourcesOfType(c *eden.Context) {
+	typeGUID, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	if _, err := a.Store.Resources().Get(typeGUID); err != nil {
+		respondError(c, err, "An error occurred while retrieving resources")
+		return
+	}
+
+	resources, err := a.Store.Types().GetResourcesOfType(typeGUID)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving resources")
+		return
+	}
+
+	// Load the verbs of all of them at once
+	guids := make([]string, len(resources))
+	for i, r := range resources {
+		guids[i] = r.Guid
+	}
+	verbs, err := a.Store.Verbs().GetByResources(guids)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving resources")
+		return
+	}
+	for i := 0; i < len(resources); i++ {
+		resources[i].Verbs = verbs[resources[i].Guid]
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", resources})
+}
+
+// Get every resource that is the type of other resources, with how many,
+//   ordered by name.
+// GET /types
+func (a *Api) GetTypesInUse(c *eden.Context) {
+	types, err := a.Store.Types().GetTypesInUse()
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving resourceType information")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", types})
+}
+
 // Store the type of a resource.
 // POST /type resource=:resourceGUID, type=:resourceTypeGUID
+//   or a JSON body {"resource", "type"}
+//   Location is GET /resources/:guid/types/:typeGuid of the new association.
+//   Both must be the guids of resources outside the trash (422 if either
+//   is left out or malformed, 404 if either is missing) and must differ.
 func (a *Api) InsertResourceType(c *eden.Context) {
-	// Create new resourceType accessor
-	ra := accessors.NewResourceTypeAccessor(a.DB)
+	// Create new resourceType accessor, making changes as the requesting user
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Types()
 
-	// Parse name and description from POST data.
-	c.Request.ParseForm()
-	r, resourceOk := c.Request.Form["resource"]
-	t, typeOk := c.Request.Form["type"]
-	if !typeOk || !resourceOk {
-		c.Respond(400, eden.Response{"ERROR", "Unable to process request"})
+	// Parse resource and type from POST data.
+	body, ok := readBody(c, typeFields)
+	if !ok || !validate(c, body, typeRules, false) {
 		return
 	}
 
 	// Insert the resourceType and test for errors
-	if err := ra.Insert(r[0], t[0]); err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error has occurred"})
+	resourceType := accessors.ResourceType{ResourceGUID: body["resource"], Type: body["type"]}
+	guid, err := ra.Insert(resourceType.ResourceGUID, resourceType.Type)
+	if err != nil {
+		respondError(c, err, "An error has occurred")
 		return
 	}
+	resourceType.Guid = guid
 
-	// Respond
-	c.Respond(200, eden.Response{"OK", "success"})
+	// Respond with the new association
+	c.Response.Header().Set("Location", "/resources/"+resourceType.ResourceGUID+"/types/"+resourceType.Type)
+	c.Respond(201, eden.Response{"OK", resourceType})
 }
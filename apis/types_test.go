@@ -2,8 +2,8 @@ package apis
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
-	eden "github.com/byu-oit-ssengineering/tmt-eden"
 	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
 	testhelpers "github.com/byu-oit-ssengineering/tmt-test-helpers"
 	_ "github.com/go-sql-driver/mysql"
@@ -11,20 +11,45 @@ import (
 	"testing"
 )
 
+type testResponseResourceType struct {
+	Status string
+	Data   accessors.ResourceType
+}
+
+type testResponseResourceTypeDetails struct {
+	Status string
+	Data   []accessors.ResourceTypeDetail
+}
+
+type testResponseResourceTypeDetail struct {
+	Status string
+	Data   accessors.ResourceTypeDetail
+}
+
+type testResponseTypesInUse struct {
+	Status string
+	Data   []accessors.TypeInUse
+}
+
+type testResponseResources struct {
+	Status string
+	Data   []accessors.Resource
+}
+
 func TestGetResourceType(t *testing.T) {
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
-	expected := accessors.Resource{"11111111-2222-3333-2222-111111111111", "test", "this is a test", "tmt.byu.edu/resourceTypes", nil}
-	columns := []string{"guid", "name", "description", "apiEndpoint"}
+	expected := accessors.Resource{"11111111-2222-3333-2222-111111111111", "test", "this is a test", "https://tmt.byu.edu/resourceTypes", testTime, testTime, 1, nil}
+	columns := []string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT guid, name, description, apiEndpoint FROM resources JOIN resourceTypes ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources JOIN resourceTypes ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID=(.)").
 		WithArgs("11111111-2222-3333-4444-555555555555").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-2222-111111111111,test,this is a test,tmt.byu.edu/resourceTypes"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-2222-111111111111,test,this is a test,https://tmt.byu.edu/resourceTypes,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
 
 	// Create context, call API
 	var result []byte
@@ -48,22 +73,39 @@ func TestInsertResourceType(t *testing.T) {
 	accessors.NewGuid = func() string {
 		return "123def"
 	}
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("55555555-6666-7777-8888-999999999999").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 	sqlmock.ExpectPrepare()
 	sqlmock.ExpectExec("INSERT INTO resourceTypes .+ VALUES .+").
-		WithArgs("123def", "11111111-2222-3333-4444-555555555555", "test").
+		WithArgs("123def", "11111111-2222-3333-4444-555555555555", "55555555-6666-7777-8888-999999999999").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET version=version\\+1, modified=(.) WHERE guid=(.)").
+		WithArgs(testTime, "11111111-2222-3333-4444-555555555555").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
 	// Create context and call API
 	var result []byte
-	var output eden.Response
-	c := testhelpers.NewTestingContext("resource=11111111-2222-3333-4444-555555555555&type=test", nil, api.InsertResourceType)
+	var output testResponseResourceType
+	c := testhelpers.NewTestingContext("resource=11111111-2222-3333-4444-555555555555&type=55555555-6666-7777-8888-999999999999", nil, api.InsertResourceType)
 	testhelpers.CallAPI(api.InsertResourceType, c, &result)
 
 	err = json.Unmarshal(result, &output)
@@ -71,8 +113,113 @@ func TestInsertResourceType(t *testing.T) {
 		t.Error(err.Error())
 	}
 
-	// Ensure correct output
-	if output.Data != "success" {
-		t.Errorf("expected to get 'success' but got %v instead", output)
+	// Ensure the created association is returned
+	expected := accessors.ResourceType{"123def", "11111111-2222-3333-4444-555555555555", "55555555-6666-7777-8888-999999999999"}
+	if output.Data != expected {
+		t.Errorf("Expected: %v, but got %v instead", expected, output.Data)
+	}
+}
+
+func TestResourceTypesWithMemoryStore(t *testing.T) {
+	stopClock()
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	for _, name := range []string{"whiteboard", "board 1", "board 2", "room"} {
+		api.Store.Resources().Insert(accessors.Resource{Name: name, APIEndpoint: "https://tmt.byu.edu/" + name})
+	}
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "erase", Description: "can erase"})
+	for _, body := range []string{
+		`{"resource": "00000000-0000-0000-0000-000000000002", "type": "00000000-0000-0000-0000-000000000001"}`,
+		`{"resource": "00000000-0000-0000-0000-000000000003", "type": "00000000-0000-0000-0000-000000000001"}`,
+		`{"resource": "00000000-0000-0000-0000-000000000002", "type": "00000000-0000-0000-0000-000000000004"}`,
+	} {
+		if w := callHandler(api.InsertResourceType, "POST", "/type", "application/json", body, nil); w.Code != 201 {
+			t.Fatalf("Expected 201 but got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	// Every whiteboard, with its verbs
+	params := httprouter.Params{httprouter.Param{Key: "typeGuid", Value: "00000000-0000-0000-0000-000000000001"}}
+	w := callHandler(api.GetResourcesOfType, "GET", "/types/00000000-0000-0000-0000-000000000001/resources", "", "", params)
+	if w.Code != 200 {
+		t.Fatalf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	var resources testResponseResources
+	if err := json.Unmarshal(w.Body.Bytes(), &resources); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(resources.Data) != 2 || resources.Data[0].Name != "board 1" || len(resources.Data[0].Verbs) != 1 || resources.Data[1].Name != "board 2" || len(resources.Data[1].Verbs) != 0 {
+		t.Errorf("Expected both boards but got %v", resources.Data)
+	}
+
+	// Every type of a board, in full
+	params = httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000002"}}
+	w = callHandler(api.GetResourceTypes, "GET", "/resources/00000000-0000-0000-0000-000000000002/types", "", "", params)
+	var types testResponseResourceTypeDetails
+	if err := json.Unmarshal(w.Body.Bytes(), &types); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if w.Code != 200 || len(types.Data) != 2 || types.Data[0].TypeResource.Name != "whiteboard" || types.Data[1].TypeResource.Name != "room" || types.Data[1].ResourceGUID != "00000000-0000-0000-0000-000000000002" {
+		t.Errorf("Expected the whiteboard and room types but got %d and %v", w.Code, types.Data)
+	}
+
+	// The types in use, by name
+	w = callHandler(api.GetTypesInUse, "GET", "/types", "", "", nil)
+	var inUse testResponseTypesInUse
+	if err := json.Unmarshal(w.Body.Bytes(), &inUse); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if w.Code != 200 || len(inUse.Data) != 2 || inUse.Data[0].Name != "room" || inUse.Data[0].Count != 1 || inUse.Data[1].Name != "whiteboard" || inUse.Data[1].Count != 2 {
+		t.Errorf("Expected room and whiteboard but got %d and %v", w.Code, inUse.Data)
+	}
+
+	missing := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000999"}}
+	if w := callHandler(api.GetResourcesOfType, "GET", "/types/00000000-0000-0000-0000-000000000999/resources", "", "", missing); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+	if w := callHandler(api.GetResourceTypes, "GET", "/resources/00000000-0000-0000-0000-000000000999/types", "", "", missing); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+
+	// The association is at its Location
+	w = callHandler(api.InsertResourceType, "POST", "/type", "application/json", `{"resource": "00000000-0000-0000-0000-000000000003", "type": "00000000-0000-0000-0000-000000000004"}`, nil)
+	location := w.Header().Get("Location")
+	if location != "/resources/00000000-0000-0000-0000-000000000003/types/00000000-0000-0000-0000-000000000004" {
+		t.Errorf("Expected Location /resources/00000000-0000-0000-0000-000000000003/types/00000000-0000-0000-0000-000000000004 but got %v", location)
+	}
+	params = httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000003"}, httprouter.Param{Key: "typeGuid", Value: "00000000-0000-0000-0000-000000000004"}}
+	w = callHandler(api.GetResourceTypeAssociation, "GET", location, "", "", params)
+	var association testResponseResourceTypeDetail
+	if err := json.Unmarshal(w.Body.Bytes(), &association); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if w.Code != 200 || association.Data.ResourceGUID != "00000000-0000-0000-0000-000000000003" || association.Data.TypeResource.Name != "room" {
+		t.Errorf("Expected the room type of board 2 but got %d and %v", w.Code, association.Data)
+	}
+	params[1].Value = "00000000-0000-0000-0000-000000000002"
+	if w := callHandler(api.GetResourceTypeAssociation, "GET", "/resources/00000000-0000-0000-0000-000000000003/types/00000000-0000-0000-0000-000000000002", "", "", params); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+
+	// Both must be guids of live resources, and differ
+	for _, test := range []struct {
+		body     string
+		expected int
+	}{
+		{`{"resource": "00000000-0000-0000-0000-000000000003", "type": "room"}`, 422},
+		{`{"resource": "00000000-0000-0000-0000-000000000003"}`, 422},
+		{`{}`, 422},
+		{`{"resource": "3", "type": "00000000-0000-0000-0000-000000000004"}`, 422},
+		{`{"resource": "00000000-0000-0000-0000-000000000003", "type": "00000000-0000-0000-0000-000000000999"}`, 404},
+		{`{"resource": "00000000-0000-0000-0000-000000000999", "type": "00000000-0000-0000-0000-000000000004"}`, 404},
+		{`{"resource": "00000000-0000-0000-0000-000000000003", "type": "00000000-0000-0000-0000-000000000003"}`, 400},
+	} {
+		if w := callHandler(api.InsertResourceType, "POST", "/type", "application/json", test.body, nil); w.Code != test.expected {
+			t.Errorf("Expected %d for %s but got %d: %s", test.expected, test.body, w.Code, w.Body.String())
+		}
 	}
 }
@@ -0,0 +1,85 @@
+package apis
+
+import (
+	"fmt"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	rules "github.com/byu-oit-ssengineering/tmt-resources/rules"
+)
+
+// Body of the 422 response to a request whose fields break the rules.
+type invalidFields struct {
+	Message    string            `json:"message"`
+	Violations []rules.Violation `json:"violations"`
+}
+
+// The rules of one body field. Every field is required to be present when
+//   creating; when updating only the fields sent are checked.
+type fieldRules struct {
+	field field
+	rules rules.Field
+}
+
+// Rules of the fields of a resource.
+var resourceRules = []fieldRules{
+	{resourceFields[0], rules.Name},
+	{resourceFields[1], rules.Description},
+	{resourceFields[2], rules.APIEndpoint},
+}
+
+// Rules of the fields of a new verb.
+var verbRules = []fieldRules{
+	{verbFields[0], rules.Guid},
+	{verbFields[1], rules.Verb},
+	{verbFields[2], rules.Description},
+}
+
+// Rules of the fields of PUT /verbs/:guid.
+var verbUpdateRules = verbRules[2:]
+
+// Rules of the fields of a new type association.
+var typeRules = []fieldRules{
+	{typeFields[0], rules.Guid},
+	{typeFields[1], rules.Guid},
+}
+
+// Checks body against the rules of its fields. With partial set, as for
+//   an update, fields that were not sent are not checked. If any rule is
+//   broken the client is sent a 422 response listing every violation, and
+//   false is returned.
+func validate(c *eden.Context, body map[string]string, fields []fieldRules, partial bool) bool {
+	violations := check(c, body, fields, partial)
+	if len(violations) > 0 {
+		respondFailure(c, 422, invalidFields{"The request breaks the field rules", violations})
+		return false
+	}
+	return true
+}
+
+// Returns every rule body breaks, naming each field as the client sent
+//   it.
+func check(c *eden.Context, body map[string]string, fields []fieldRules, partial bool) []rules.Violation {
+	var violations []rules.Violation
+	for _, f := range fields {
+		name := fieldName(c, f.field)
+		value, ok := body[f.field.json]
+		switch {
+		case ok:
+			violations = append(violations, f.rules.Check(name, value)...)
+		case !partial:
+			violations = append(violations, rules.Violation{name, "required", fmt.Sprintf("%s is required", name)})
+		}
+	}
+	return violations
+}
+
+// Returns the i-th path parameter, which must be a verb matching
+//   rules.VerbPattern. If it is not the client is sent a 422 response, and
+//   ok is false.
+func verbParam(c *eden.Context, i int) (verb string, ok bool) {
+	verb = c.Params[i].Value
+	if violations := rules.Verb.Check(c.Params[i].Key, verb); len(violations) > 0 {
+		respondFailure(c, 422, invalidFields{"The request breaks the field rules", violations})
+		return verb, false
+	}
+	return verb, true
+}
@@ -0,0 +1,86 @@
+package apis
+
+import (
+	"encoding/json"
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	rules "github.com/byu-oit-ssengineering/tmt-resources/rules"
+	"github.com/julienschmidt/httprouter"
+	"strings"
+	"testing"
+)
+
+type testResponseInvalid struct {
+	Status string
+	Data   invalidFields
+}
+
+func TestValidation(t *testing.T) {
+	api := newTrashApi()
+	api.Store.Resources().Insert(accessors.Resource{Name: "room", Description: "a room", APIEndpoint: "https://tmt.byu.edu/rooms"})
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000003"}}
+	long := strings.Repeat("a", rules.MaxNameLength+1)
+
+	tests := []struct {
+		handler     func(*eden.Context)
+		contentType string
+		body        string
+		expected    []rules.Violation
+	}{
+		{api.InsertResource, "application/json", `{}`, []rules.Violation{
+			rules.Violation{"name", "required", "name is required"},
+			rules.Violation{"description", "required", "description is required"},
+			rules.Violation{"apiEndpoint", "required", "apiEndpoint is required"},
+		}},
+		{api.InsertResource, "application/x-www-form-urlencoded", "name=%20&description=a%20test&api=tmt.byu.edu/boards", []rules.Violation{
+			rules.Violation{"name", "required", "name must not be empty"},
+			rules.Violation{"api", "url", "api must be an absolute http or https URL such as https://tmt.byu.edu/whiteboards"},
+		}},
+		{api.InsertResource, "application/json", `{"name": "` + long + `", "description": "a test", "apiEndpoint": "ftp://tmt.byu.edu/boards"}`, []rules.Violation{
+			rules.Violation{"name", "maxLength", "name must be at most 255 characters"},
+			rules.Violation{"apiEndpoint", "url", "apiEndpoint must be an absolute http or https URL such as https://tmt.byu.edu/whiteboards"},
+		}},
+		{api.UpdateResource, "application/json", `{"apiEndpoint": "/boards"}`, []rules.Violation{
+			rules.Violation{"apiEndpoint", "url", "apiEndpoint must be an absolute http or https URL such as https://tmt.byu.edu/whiteboards"},
+		}},
+		{api.AddVerb, "application/json", `{"resourceGUID": "room", "verb": "Check Out"}`, []rules.Violation{
+			rules.Violation{"resourceGUID", "guid", "resourceGUID must be a guid such as 00000000-0000-0000-0000-000000000000"},
+			rules.Violation{"verb", "pattern", "verb must match " + rules.DefaultVerbPattern},
+			rules.Violation{"description", "required", "description is required"},
+		}},
+		{api.UpdateVerb, "application/json", `{"description": ""}`, []rules.Violation{
+			rules.Violation{"description", "required", "description must not be empty"},
+		}},
+	}
+
+	for _, test := range tests {
+		w := callHandler(test.handler, "POST", "/", test.contentType, test.body, params)
+		if w.Code != 422 {
+			t.Errorf("%s: Expected 422 but got %d", test.body, w.Code)
+		}
+		var output testResponseInvalid
+		if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if len(output.Data.Violations) != len(test.expected) {
+			t.Errorf("%s: Expected %v but got %v", test.body, test.expected, output.Data.Violations)
+			continue
+		}
+		for i := range test.expected {
+			if output.Data.Violations[i] != test.expected[i] {
+				t.Errorf("%s: Expected %v but got %v", test.body, test.expected[i], output.Data.Violations[i])
+			}
+		}
+	}
+
+	// Nothing was written
+	if resources, _ := api.Store.Resources().GetAll(); len(resources) != 1 {
+		t.Errorf("Expected 1 resource but got %d", len(resources))
+	}
+
+	// Valid fields pass
+	w := callHandler(api.AddVerb, "POST", "/verbs", "application/json", `{"resourceGUID": "00000000-0000-0000-0000-000000000003", "verb": "check-out", "description": "can check out"}`, nil)
+	if w.Code != 201 {
+		t.Errorf("Expected 201 but got %d: %s", w.Code, w.Body.String())
+	}
+}
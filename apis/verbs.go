@@ -1,102 +1,266 @@
 package apis
 
 import (
+	"errors"
 	eden "github.com/byu-oit-ssengineering/tmt-eden"
 	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
 )
 
-// Get a list of the verbs associated to a resource.
-// GET /verbs
+// Get a list of the effective verbs of a resource: its own and those it
+//   inherits from its type chain, each telling where it comes from.
+// GET /verbs/:resourceGUID
 func (a *Api) GetResourceVerbs(c *eden.Context) {
-	ra := accessors.NewResourceVerbAccessor(a.DB)
+	ra := a.Store.Verbs()
 
-	guid := c.Params[0].Value
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+	if _, err := a.Store.Resources().Get(guid); err != nil {
+		respondError(c, err, "An error occurred while retrieving resources")
+		return
+	}
 
-	verbs, err := ra.GetByResource(guid)
+	inheritance, err := ra.GetEffective(guid)
 	if err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error occurred while retrieving resources"})
+		respondError(c, err, "An error occurred while retrieving resources")
 		return
 	}
 
 	// Respond
-	c.Respond(200, eden.Response{"OK", verbs})
+	c.Respond(200, eden.Response{"OK", inheritance.Verbs})
+}
+
+// Get one verb of a resource, as AddVerb created it.
+// GET /resources/:guid/verbs/:verbGuid
+//   The response is a 404 unless the verb is the resource's own; inherited
+//   verbs are listed by GET /verbs/:resourceGUID.
+func (a *Api) GetVerb(c *eden.Context) {
+	resource, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+	guid, ok := guidParam(c, 1)
+	if !ok {
+		return
+	}
+
+	verb, err := a.Store.Verbs().Get(guid)
+	if err != nil {
+		respondError(c, err, "An error occurred while retrieving the verb")
+		return
+	}
+	if verb.ResourceGUID != resource {
+		respondError(c, &accessors.NotFoundError{accessors.EntityVerb, guid}, "")
+		return
+	}
+
+	// Respond
+	setETag(c, verb.Version)
+	c.Respond(200, eden.Response{"OK", verb})
 }
 
 // Associate a verb to a resource.
-// POST /verbs resource=:resourceGUID, verb=:verb, description=:description
+// POST /verbs resourceGUID=:resourceGUID, verb=:verb, description=:description
+//   or a JSON body {"resourceGUID", "verb", "description"}
+//   Every field is required and the verb must match VerbPattern; the
+//   response to fields that break the rules is a 422 listing them all. The
+//   response is a 404 if the resource does not exist or is in the trash.
+//   Location is GET /resources/:guid/verbs/:verbGuid of the new verb.
 func (a *Api) AddVerb(c *eden.Context) {
-	// Create new resource accessor
-	ra := accessors.NewResourceVerbAccessor(a.DB)
+	// Create new resource accessor, making changes as the requesting user
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Verbs()
 
-	// Parse name and description from POST data.
-	c.Request.ParseForm()
-	resourceGUID, resourceGUIDOk := c.Request.Form["resourceGUID"]
-	verb, verbOk := c.Request.Form["verb"]
-	description, descriptionOk := c.Request.Form["description"]
-	if !descriptionOk || !verbOk || !resourceGUIDOk {
-		c.Respond(400, eden.Response{"ERROR", "Not enough information given"})
+	// Parse resource, verb and description from POST data.
+	body, ok := readBody(c, verbFields)
+	if !ok || !validate(c, body, verbRules, false) {
 		return
 	}
 
-	resource := accessors.ResourceVerb{ResourceGUID: resourceGUID[0], Verb: verb[0], Description: description[0]}
+	resource := accessors.ResourceVerb{ResourceGUID: body["resourceGUID"], Verb: body["verb"], Description: body["description"]}
 
 	// Insert the resource and test for errors
-	if err := ra.Add(resource); err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error has occurred"})
+	guid, err := ra.Add(resource)
+	if err != nil {
+		respondError(c, err, "An error has occurred")
 		return
 	}
 
-	// Respond
-	c.Respond(200, eden.Response{"OK", "success"})
+	// Read it back for the values set by the store
+	if resource, err = ra.Get(guid); err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+
+	// Respond with the new verb
+	c.Response.Header().Set("Location", "/resources/"+resource.ResourceGUID+"/verbs/"+guid)
+	setETag(c, resource.Version)
+	c.Respond(201, eden.Response{"OK", resource})
 }
 
 // Update a verb's description.
-// PUT /verbs/:guid description=:newDescription
+// PUT /verbs/:guid description=:newDescription or a JSON body {"description"}
+//   An If-Match header must match the verb's ETag, its version as listed
+//   by GET /verbs/:resourceGUID. The response is a 412 if it does not, or
+//   if the verb changes while it is updated.
 func (a *Api) UpdateVerb(c *eden.Context) {
-	// Create new resource accessor
-	ra := accessors.NewResourceVerbAccessor(a.DB)
+	// Create new resource accessor, making changes as the requesting user
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Verbs()
 
 	// Parse resource guid
-	guid := c.Params[0].Value
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	// Parse the new description
+	body, ok := readBody(c, verbUpdateFields)
+	if !ok || !validate(c, body, verbUpdateRules, false) {
+		return
+	}
 
 	// Get the resource
 	resource, err := ra.Get(guid)
 	if err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An unexpected error occurred"})
+		respondError(c, err, "An unexpected error occurred")
 		return
 	}
-
-	// Update given fields
-	c.Request.ParseForm()
-	if description, descriptionOk := c.Request.Form["description"]; descriptionOk {
-		resource.Description = description[0]
-	} else {
-		c.Respond(400, eden.Response{"ERROR", "No new description has been specified"})
+	if !ifMatch(c, resource.Version) {
 		return
 	}
 
+	// Update given fields
+	resource.Description = body["description"]
+
 	// Save
-	if err := ra.Update(guid, resource.Description); err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error has occurred"})
+	if err := ra.Update(guid, resource.Description, resource.Version); err == accessors.ErrVersionMismatch {
+		respondFailure(c, 412, "The verb has changed; get it again and retry")
+		return
+	} else if err != nil {
+		respondError(c, err, "An error has occurred")
 		return
 	}
+	resource.Version++
 
 	// Respond
+	setETag(c, resource.Version)
 	c.Respond(200, eden.Response{"OK", "success"})
 }
 
-// Delete a verb-resource type association.
+// Move a verb-resource type association to the trash.
 // DELETE /verbs/:guid
+//   An If-Match header must match the verb's ETag. The response is a 412 if
+//   it does not, or if the verb changes while it is removed.
 func (a *Api) RemoveVerb(c *eden.Context) {
-	// Create new resource accessor
-	ra := accessors.NewResourceVerbAccessor(a.DB)
+	// Create new resource accessor, making changes as the requesting user
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Verbs()
 
 	// Parse resource id
-	guid := c.Params[0].Value
+	guid, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+
+	// Get the verb as it was
+	verb, err := ra.Get(guid)
+	if err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+	if !ifMatch(c, verb.Version) {
+		return
+	}
 
 	// Delete the resource
-	if err := ra.Remove(guid); err != nil {
-		c.Respond(500, eden.Response{"ERROR", "An error has occurred"})
+	if err := ra.Remove(guid, verb.Version); err == accessors.ErrVersionMismatch {
+		respondFailure(c, 412, "The verb has changed; get it again and retry")
+		return
+	} else if err != nil {
+		respondError(c, err, "An error has occurred")
+		return
+	}
+
+	// Respond
+	c.Respond(200, eden.Response{"OK", "success"})
+}
+
+// Stop a resource, and every resource of its type, from inheriting a verb.
+// PUT /resources/:guid/suppressed/:verb
+//   The response is a 422 if the verb does not match VerbPattern, a 404 if
+//   the resource does not exist and a 409 if the verb is already
+//   suppressed. To override an inherited verb instead,
+//   add a verb of the same name to the resource.
+func (a *Api) SuppressVerb(c *eden.Context) {
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Verbs()
+
+	resource, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+	verb, ok := verbParam(c, 1)
+	if !ok {
+		return
+	}
+
+	guid, err := ra.Suppress(resource, verb)
+	switch err {
+	case nil:
+	case accessors.ErrForeignKey:
+		respondFailure(c, 404, "No resource has that guid")
+		return
+	case accessors.ErrDuplicate:
+		respondFailure(c, 409, "The verb is already suppressed")
+		return
+	default:
+		respondError(c, err, "An error has occurred")
+		return
+	}
+
+	// Respond
+	c.Respond(201, eden.Response{"OK", accessors.VerbSuppression{guid, resource, verb}})
+}
+
+// Let a resource inherit a verb it suppressed again.
+// DELETE /resources/:guid/suppressed/:verb
+//   The response is a 422 if the verb does not match VerbPattern and a 404
+//   if it is not suppressed.
+func (a *Api) UnsuppressVerb(c *eden.Context) {
+	store, ok := a.as(c)
+	if !ok {
+		return
+	}
+	ra := store.Verbs()
+
+	resource, ok := guidParam(c, 0)
+	if !ok {
+		return
+	}
+	verb, ok := verbParam(c, 1)
+	if !ok {
+		return
+	}
+
+	if err := ra.Unsuppress(resource, verb); errors.Is(err, accessors.ErrNotFound) {
+		respondFailure(c, 404, "The verb is not suppressed")
+		return
+	} else if err != nil {
+		respondError(c, err, "An error has occurred")
 		return
 	}
 
@@ -2,12 +2,15 @@ package apis
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	eden "github.com/byu-oit-ssengineering/tmt-eden"
 	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
 	testhelpers "github.com/byu-oit-ssengineering/tmt-test-helpers"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -27,17 +30,29 @@ func TestGetResourceVerbs(t *testing.T) {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
 	expected := []accessors.ResourceVerb{
-		accessors.ResourceVerb{"11111111-2222-3333-4444-555555555555", "11111111-2222-3333-2222-111111111111", "test", "allows testing"},
-		accessors.ResourceVerb{"11111111-2222-3333-4444-666666666666", "11111111-2222-3333-2222-111111111111", "create", "can create tests"},
+		accessors.ResourceVerb{"11111111-2222-3333-4444-555555555555", "11111111-2222-3333-2222-111111111111", "test", "allows testing", 1},
+		accessors.ResourceVerb{"11111111-2222-3333-4444-666666666666", "11111111-2222-3333-2222-111111111111", "create", "can create tests", 1},
 	}
-	columns := []string{"guid", "resourceGUID", "verb", "description"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resourceVerbs WHERE resourceGUID=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resources WHERE guid=(.)").
 		WithArgs("11111111-2222-3333-2222-111111111111").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-2222-3333-2222-111111111111,test,allows testing\n11111111-2222-3333-4444-666666666666,11111111-2222-3333-2222-111111111111,create,can create tests"))
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "name", "description", "apiEndpoint", "created", "modified", "version"}).FromCSVString("11111111-2222-3333-2222-111111111111,whiteboard,a whiteboard,https://tmt.byu.edu/whiteboards,2016-01-01 00:00:00,2016-01-01 00:00:00,1"))
+	columns := []string{"guid", "resourceGUID", "verb", "description", "version"}
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID IN \\((.)\\)").
+		WithArgs("11111111-2222-3333-2222-111111111111").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,11111111-2222-3333-2222-111111111111,test,allows testing,1\n11111111-2222-3333-4444-666666666666,11111111-2222-3333-2222-111111111111,create,can create tests,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM suppressedVerbs WHERE resourceGUID IN \\((.)\\)").
+		WithArgs("11111111-2222-3333-2222-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceTypes JOIN resources ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID IN \\((.)\\)").
+		WithArgs("11111111-2222-3333-2222-111111111111").
+		WillReturnRows(sqlmock.NewRows([]string{"resourceGUID", "guid", "name", "description", "apiEndpoint", "created", "modified", "version"}))
 
 	// Create context, call API
 	var result []byte
@@ -63,52 +78,85 @@ func TestInsertResourceVerb(t *testing.T) {
 	accessors.NewGuid = func() string {
 		return "123def"
 	}
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM resources WHERE guid=(.) AND deleted IS NULL").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).FromCSVString("1"))
 	sqlmock.ExpectPrepare()
 	sqlmock.ExpectExec("INSERT INTO resourceVerbs .+ VALUES .+").
-		WithArgs("123def", "11111111-2222-3333-4444-555555555555", "test", "allows testing").
+		WithArgs("123def", "11111111-2222-3333-4444-555555555555", "test", "allows testing", testTime, testTime).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET version=version\\+1, modified=(.) WHERE guid=\\(SELECT resourceGUID FROM resourceVerbs WHERE guid=(.)\\)").
+		WithArgs(testTime, "123def").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE guid=(.)").
+		WithArgs("123def").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}).FromCSVString("123def,11111111-2222-3333-4444-555555555555,test,allows testing,1"))
 
 	// Create context and call API
 	var result []byte
-	var output eden.Response
-	c := testhelpers.NewTestingContext("resourceGUID=11111111-2222-3333-4444-555555555555&verb=test&description=allows%20testing", nil, AddVerb)
-	testhelpers.CallAPI(AddVerb, c, &result)
+	var output testResponseVerb
+	c := testhelpers.NewTestingContext("resourceGUID=11111111-2222-3333-4444-555555555555&verb=test&description=allows%20testing", nil, api.AddVerb)
+	testhelpers.CallAPI(api.AddVerb, c, &result)
 
 	err = json.Unmarshal(result, &output)
 	if err != nil {
 		t.Error(err.Error())
 	}
 
-	// Ensure correct output
-	if output.Data != "success" {
-		t.Errorf("expected to get 'success' but got %v instead", output)
+	// Ensure the created verb is returned
+	expected := accessors.ResourceVerb{"123def", "11111111-2222-3333-4444-555555555555", "test", "allows testing", 1}
+	if output.Data != expected {
+		t.Errorf("Expected: %v, but got %v instead", expected, output.Data)
 	}
 }
 
 func TestUpdateVerb(t *testing.T) {
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
-	columns := []string{"guid", "resourceGUID", "verb", "description"}
+	columns := []string{"guid", "resourceGUID", "verb", "description", "version"}
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectQuery("SELECT (.) FROM resourceVerbs WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE guid=(.)").
 		WithArgs("11111111-2222-3333-4444-555555555555").
-		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,00000000-9999-8888-7777-666666666666,test,this is a test"))
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,00000000-9999-8888-7777-666666666666,test,this is a test,1"))
+	sqlmock.ExpectBegin()
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectExec("UPDATE resourceVerbs SET description=(.) WHERE guid=(.)").
-		WithArgs("testing", "11111111-2222-3333-4444-555555555555").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE guid=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows(columns).FromCSVString("11111111-2222-3333-4444-555555555555,00000000-9999-8888-7777-666666666666,test,this is a test,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resourceVerbs SET description=(.), version=version\\+1, modified=(.) WHERE guid=(.) AND version=(.)").
+		WithArgs("testing", testTime, "11111111-2222-3333-4444-555555555555", 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET version=version\\+1, modified=(.) WHERE guid=\\(SELECT resourceGUID FROM resourceVerbs WHERE guid=(.)\\)").
+		WithArgs(testTime, "11111111-2222-3333-4444-555555555555").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
 	// Create context and call API
 	var result []byte
@@ -128,17 +176,55 @@ func TestUpdateVerb(t *testing.T) {
 }
 
 func TestDeleteResourceVerb(t *testing.T) {
+	stopClock()
 	db, err := testhelpers.GetMockDB()
 	if err != nil {
 		t.Error("An unexpected error occurred instantiating accessor")
 		return
 	}
-	api := &Api{db}
+	api := &Api{accessors.NewSQLStore(db, accessors.MySQL)}
 
 	sqlmock.ExpectPrepare()
-	sqlmock.ExpectExec("DELETE FROM resourceVerbs WHERE guid=(.)").
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE guid=(.)").
+		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}).FromCSVString("11111111-2222-3333-4444-555555555555,00000000-9999-8888-7777-666666666666,test,this is a test,1"))
+	sqlmock.ExpectBegin()
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE guid=(.)").
 		WithArgs("11111111-2222-3333-4444-555555555555").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}).FromCSVString("11111111-2222-3333-4444-555555555555,00000000-9999-8888-7777-666666666666,test,this is a test,1"))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resourceVerbs SET deleted=(.), modified=(.) WHERE guid=(.) AND version=(.) AND deleted IS NULL").
+		WithArgs(testTime, testTime, "11111111-2222-3333-4444-555555555555", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("UPDATE resources SET version=version\\+1, modified=(.) WHERE guid=\\(SELECT resourceGUID FROM resourceVerbs WHERE guid=(.)\\)").
+		WithArgs(testTime, "11111111-2222-3333-4444-555555555555").
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT resourceTypes.resourceGUID FROM resourceTypes JOIN resources ON resources.guid=resourceTypes.resourceGUID WHERE resourceTypes.type=(.) AND resources.deleted IS NULL").
+		WithArgs("00000000-9999-8888-7777-666666666666").
+		WillReturnRows(sqlmock.NewRows([]string{"resourceGUID"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM resourceVerbs WHERE resourceGUID IN \\((.)\\) AND deleted IS NULL").
+		WithArgs("00000000-9999-8888-7777-666666666666").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb", "description", "version"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT guid, resourceGUID, verb FROM suppressedVerbs WHERE resourceGUID IN \\((.)\\)").
+		WithArgs("00000000-9999-8888-7777-666666666666").
+		WillReturnRows(sqlmock.NewRows([]string{"guid", "resourceGUID", "verb"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT resourceTypes.resourceGUID, (.+) FROM resourceTypes JOIN resources ON resources.guid=resourceTypes.type WHERE resourceTypes.resourceGUID IN \\((.)\\)").
+		WithArgs("00000000-9999-8888-7777-666666666666").
+		WillReturnRows(sqlmock.NewRows([]string{"resourceGUID", "guid", "name", "description", "apiEndpoint", "created", "modified", "version"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectQuery("SELECT (.+) FROM grants WHERE resourceGUID=(.) AND verb=(.)").
+		WithArgs("00000000-9999-8888-7777-666666666666", "test").
+		WillReturnRows(sqlmock.NewRows([]string{"guid"}))
+	sqlmock.ExpectPrepare()
+	sqlmock.ExpectExec("INSERT INTO auditLog .+ VALUES .+").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	sqlmock.ExpectCommit()
 
 	// Create context and call API
 	var result []byte
@@ -156,3 +242,146 @@ func TestDeleteResourceVerb(t *testing.T) {
 		t.Errorf("expected to get 'success' but got %v instead", output)
 	}
 }
+
+func TestAddVerbWithMemoryStore(t *testing.T) {
+	api := &Api{accessors.NewMemoryStore()}
+	accessors.NewGuid = func() string {
+		return "11111111-2222-3333-4444-555555555555"
+	}
+	api.Store.Resources().Insert(accessors.Resource{Name: "test", Description: "This is a test", APIEndpoint: "https://tmt.byu.edu/resources"})
+	accessors.NewGuid = func() string {
+		return "22222222-2222-2222-2222-222222222222"
+	}
+
+	w := callHandler(api.AddVerb, "POST", "/verbs", "application/json", `{"resourceGUID": "11111111-2222-3333-4444-555555555555", "verb": "edit", "description": "can edit"}`, nil)
+	if w.Code != 201 {
+		t.Errorf("Expected 201 but got %d", w.Code)
+	}
+	location := w.Header().Get("Location")
+	if location != "/resources/11111111-2222-3333-4444-555555555555/verbs/22222222-2222-2222-2222-222222222222" {
+		t.Errorf("Expected Location /resources/11111111-2222-3333-4444-555555555555/verbs/22222222-2222-2222-2222-222222222222 but got %v", location)
+	}
+
+	var output testResponseVerb
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Error(err.Error())
+	}
+	expected := accessors.ResourceVerb{"22222222-2222-2222-2222-222222222222", "11111111-2222-3333-4444-555555555555", "edit", "can edit", 1}
+	if output.Data != expected {
+		t.Errorf("Expected: %v, but got %v instead", expected, output.Data)
+	}
+
+	// Following Location gets the verb back
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "11111111-2222-3333-4444-555555555555"}, httprouter.Param{Key: "verbGuid", Value: "22222222-2222-2222-2222-222222222222"}}
+	w = callHandler(api.GetVerb, "GET", location, "", "", params)
+	output = testResponseVerb{}
+	if err := json.Unmarshal(w.Body.Bytes(), &output); err != nil {
+		t.Error(err.Error())
+	}
+	if w.Code != 200 || output.Data != expected || w.Header().Get("ETag") != `"1"` {
+		t.Errorf("Expected %v but got %d %v", expected, w.Code, output.Data)
+	}
+
+	// Only under its own resource
+	params[0].Value = "33333333-2222-3333-4444-555555555555"
+	if w := callHandler(api.GetVerb, "GET", "/resources/33333333-2222-3333-4444-555555555555/verbs/22222222-2222-2222-2222-222222222222", "", "", params); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+
+	// A resource that does not exist or is in the trash takes no verbs
+	api.Store.Resources().DeleteCascade("11111111-2222-3333-4444-555555555555", 2)
+	accessors.NewGuid = func() string {
+		return "44444444-2222-2222-2222-222222222222"
+	}
+	for _, resource := range []string{"33333333-2222-3333-4444-555555555555", "11111111-2222-3333-4444-555555555555"} {
+		if w := callHandler(api.AddVerb, "POST", "/verbs", "application/json", `{"resourceGUID": "`+resource+`", "verb": "view", "description": "can view"}`, nil); w.Code != 404 {
+			t.Errorf("Expected 404 for %s but got %d", resource, w.Code)
+		}
+	}
+}
+
+type testResponseEffectiveVerbs struct {
+	Status string
+	Data   []accessors.EffectiveVerb
+}
+
+func TestInheritanceWithMemoryStore(t *testing.T) {
+	stopClock()
+	n := 0
+	accessors.NewGuid = func() string {
+		n++
+		return fmt.Sprintf("00000000-0000-0000-0000-%012d", n)
+	}
+	api := &Api{accessors.NewMemoryStore()}
+	api.Store.Resources().Insert(accessors.Resource{Name: "whiteboard", APIEndpoint: "https://tmt.byu.edu/whiteboards"})
+	api.Store.Resources().Insert(accessors.Resource{Name: "board 1", APIEndpoint: "https://tmt.byu.edu/whiteboards/1"})
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "erase", Description: "can erase"})
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000001", Verb: "view", Description: "can view"})
+	api.Store.Verbs().Add(accessors.ResourceVerb{ResourceGUID: "00000000-0000-0000-0000-000000000002", Verb: "view", Description: "can view the board"})
+	api.Store.Types().Insert("00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000001")
+	params := httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000002"}}
+
+	// The board inherits erase and overrides view
+	w := callHandler(api.GetResourceVerbs, "GET", "/verbs/00000000-0000-0000-0000-000000000002", "", "", params)
+	var verbs testResponseEffectiveVerbs
+	if err := json.Unmarshal(w.Body.Bytes(), &verbs); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(verbs.Data) != 2 || verbs.Data[0].Verb != "view" || verbs.Data[0].Inherited || len(verbs.Data[0].Overrides) != 1 || verbs.Data[1].Verb != "erase" || verbs.Data[1].InheritedFrom != "00000000-0000-0000-0000-000000000001" {
+		t.Errorf("Expected view and an inherited erase but got %v", verbs.Data)
+	}
+
+	// So does the resource, with a tag that follows its type
+	w = callHandler(api.GetResource, "GET", "/resources/00000000-0000-0000-0000-000000000002", "", "", params)
+	if !strings.Contains(w.Body.String(), `"inheritedFrom":"00000000-0000-0000-0000-000000000001"`) {
+		t.Errorf("Expected an inherited verb but got %s", w.Body.String())
+	}
+	tag := w.Header().Get("ETag")
+	api.Store.Verbs().Update("00000000-0000-0000-0000-000000000003", "can wipe", 1)
+	w = callHandlerWithHeader(api.GetResource, "GET", "/resources/00000000-0000-0000-0000-000000000002", http.Header{"If-None-Match": {tag}}, "", params)
+	if w.Code != 200 {
+		t.Errorf("Expected 200 after the type changed but got %d", w.Code)
+	}
+	tag = w.Header().Get("ETag")
+	w = callHandlerWithHeader(api.UpdateResource, "PUT", "/resources/00000000-0000-0000-0000-000000000002", http.Header{"Content-Type": {"application/json"}, "If-Match": {tag}}, `{"description": "the first board"}`, params)
+	if w.Code != 200 {
+		t.Errorf("Expected the tag to match but got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Suppressing hides erase
+	params = httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000002"}, httprouter.Param{Key: "verb", Value: "erase"}}
+	if w := callHandler(api.SuppressVerb, "PUT", "/resources/00000000-0000-0000-0000-000000000002/suppressed/erase", "", "", params); w.Code != 201 {
+		t.Errorf("Expected 201 but got %d: %s", w.Code, w.Body.String())
+	}
+	if w := callHandler(api.SuppressVerb, "PUT", "/resources/00000000-0000-0000-0000-000000000002/suppressed/erase", "", "", params); w.Code != 409 {
+		t.Errorf("Expected 409 but got %d", w.Code)
+	}
+	w = callHandler(api.GetResourceVerbs, "GET", "/verbs/00000000-0000-0000-0000-000000000002", "", "", params)
+	if err := json.Unmarshal(w.Body.Bytes(), &verbs); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(verbs.Data) != 1 || verbs.Data[0].Verb != "view" {
+		t.Errorf("Expected only view but got %v", verbs.Data)
+	}
+
+	// And unsuppressing brings it back
+	if w := callHandler(api.UnsuppressVerb, "DELETE", "/resources/00000000-0000-0000-0000-000000000002/suppressed/erase", "", "", params); w.Code != 200 {
+		t.Errorf("Expected 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	if w := callHandler(api.UnsuppressVerb, "DELETE", "/resources/00000000-0000-0000-0000-000000000002/suppressed/erase", "", "", params); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+	params = httprouter.Params{httprouter.Param{Key: "guid", Value: "99999999-9999-9999-9999-999999999999"}, httprouter.Param{Key: "verb", Value: "erase"}}
+	if w := callHandler(api.SuppressVerb, "PUT", "/resources/99999999-9999-9999-9999-999999999999/suppressed/erase", "", "", params); w.Code != 404 {
+		t.Errorf("Expected 404 but got %d", w.Code)
+	}
+
+	// The verb must match VerbPattern
+	params = httprouter.Params{httprouter.Param{Key: "guid", Value: "00000000-0000-0000-0000-000000000002"}, httprouter.Param{Key: "verb", Value: "Erase All"}}
+	if w := callHandler(api.SuppressVerb, "PUT", "/resources/00000000-0000-0000-0000-000000000002/suppressed/Erase%20All", "", "", params); w.Code != 422 {
+		t.Errorf("Expected 422 but got %d: %s", w.Code, w.Body.String())
+	}
+	if w := callHandler(api.UnsuppressVerb, "DELETE", "/resources/00000000-0000-0000-0000-000000000002/suppressed/Erase%20All", "", "", params); w.Code != 422 {
+		t.Errorf("Expected 422 but got %d: %s", w.Code, w.Body.String())
+	}
+}
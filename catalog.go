@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	apis "github.com/byu-oit-ssengineering/tmt-resources/apis"
+	rules "github.com/byu-oit-ssengineering/tmt-resources/rules"
+	"io"
+	"os"
+	"os/user"
+)
+
+const (
+	exportUsage = "usage: resources export [-format json|yaml|csv] [file]"
+	importUsage = "usage: resources import [-mode merge|replace] [-format json|yaml|csv] [-actor name] [file]"
+)
+
+// Runs the export subcommand and returns the process exit code. The
+//   catalog is written to the named file, or to standard output.
+func exportCatalog(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", "", "json, yaml or csv; by default from the file extension, else json")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, exportUsage)
+		return 2
+	}
+	file := flags.Arg(0)
+	if *format == "" {
+		*format = fileFormat(file)
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer store.DB.Close()
+
+	catalog, err := store.Catalog().Export()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var out io.Writer = os.Stdout
+	if file != "" {
+		f, err := os.Create(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := apis.WriteCatalog(out, *format, catalog); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// Runs the import subcommand and returns the process exit code. The
+//   catalog is read from the named file, or from standard input. The
+//   changes are audited as made by -actor, by default the user running the
+//   command. Verbs must match VERB_PATTERN, as they must for the service.
+func importCatalog(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := flags.String("mode", accessors.ImportMerge, "merge or replace")
+	format := flags.String("format", "", "json, yaml or csv; by default from the file extension, else json")
+	actor := flags.String("actor", currentUser(), "who the changes are audited as made by")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 || *actor == "" {
+		fmt.Fprintln(os.Stderr, importUsage)
+		return 2
+	}
+	file := flags.Arg(0)
+	if *format == "" {
+		*format = fileFormat(file)
+	}
+
+	// The catalog is held to the verb pattern the service uses
+	if err := rules.LoadVerbPattern(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var in io.Reader = os.Stdin
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+	catalog, err := apis.ReadCatalog(in, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	store, err := openStore()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer store.DB.Close()
+
+	result, err := store.As(*actor).Catalog().Import(catalog, *mode)
+	if e, ok := err.(*accessors.CatalogError); ok {
+		for _, problem := range e.Problems {
+			fmt.Fprintln(os.Stderr, problem)
+		}
+		return 1
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("created %d, updated %d, unchanged %d, deleted %d\n", result.Created, result.Updated, result.Unchanged, result.Deleted)
+	return 0
+}
+
+// Returns the catalog format of a file, json if it cannot be told.
+func fileFormat(file string) string {
+	if format, ok := apis.FormatOfFile(file); ok {
+		return format
+	}
+	return apis.FormatJSON
+}
+
+// Opens the database selected by the environment and checks its schema.
+func openStore() (*accessors.SQLStore, error) {
+	db, dialect, err := apis.OpenDB()
+	if err != nil {
+		return nil, err
+	}
+	if db == nil {
+		return nil, fmt.Errorf("in-memory storage cannot be exported or imported")
+	}
+	if err := accessors.CheckSchema(db, dialect); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return accessors.NewSQLStore(db, dialect), nil
+}
+
+// Returns the name of the user running the command, or "" if it is not
+//   known.
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	accessors "github.com/byu-oit-ssengineering/tmt-resources/accessors"
+	apis "github.com/byu-oit-ssengineering/tmt-resources/apis"
+	"os"
+)
+
+const migrateUsage = "usage: resources migrate up|down|status"
+
+// Runs the migrate subcommand and returns the process exit code.
+//   up applies all pending migrations, down reverts the last applied one
+//   and status lists the applied and pending versions.
+func migrate(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db, dialect, err := apis.OpenDB()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if db == nil {
+		fmt.Fprintln(os.Stderr, "in-memory storage has no schema to migrate")
+		return 1
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := accessors.MigrateUp(db, dialect)
+		for _, m := range applied {
+			fmt.Printf("applied %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("already up to date")
+		}
+	case "down":
+		m, err := accessors.MigrateDown(db, dialect)
+		if err == sql.ErrNoRows {
+			fmt.Println("no migrations to revert")
+			return 0
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("reverted %d_%s\n", m.Version, m.Name)
+	case "status":
+		status, err := accessors.GetMigrationStatus(db, dialect)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("version %d of %d\n", status.Version, status.Latest)
+		if i := status.Interrupted; i != nil {
+			fmt.Printf("interrupted %d_%s %s after %d of %d statements\n", i.Version, i.Name, i.Direction, i.Applied, i.Statements)
+		}
+		for _, m := range status.Pending {
+			fmt.Printf("pending %d_%s\n", m.Version, m.Name)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
@@ -4,42 +4,116 @@ import (
 	"fmt"
 	eden "github.com/byu-oit-ssengineering/tmt-eden"
 	apis "github.com/byu-oit-ssengineering/tmt-resources/apis"
+	"log/slog"
+	"os"
 )
 
+// Paths anyone may request, so load balancers can check on the service.
+var publicPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+}
+
+// Authorizes every request but those for the public paths.
+func authorize(c *eden.Context) {
+	if publicPaths[c.Request.URL.Path] {
+		return
+	}
+	eden.Authorize(c)
+}
+
 // Responds with the allowed HTTP methods for this microservice.
 func Options(c *eden.Context) {
 	c.Response.Header().Add("Access-Control-Allow-Origin", "*")
 	c.Response.Header().Add("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
-	c.Response.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+	c.Response.Header().Add("Access-Control-Allow-Headers", "Content-Type, If-Match, If-None-Match, If-Modified-Since, X-Request-ID")
+	c.Response.Header().Add("Access-Control-Expose-Headers", "ETag, Last-Modified, X-Request-ID")
 	c.Response.WriteHeader(200)
 }
 
-func main() {
-	r := eden.New()
-	r.Use(eden.Authorize)
+// Returns the user named in the Basic credentials of a request, which
+//   authorize has had eden.Authorize verify.
+func verifiedUser(c *eden.Context) (string, bool) {
+	user, _, ok := c.Request.BasicAuth()
+	return user, ok && user != ""
+}
 
+// Logs why the service cannot start and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// Starts the api: the store, audited as the verified user of each
+//   request, and the trash purge. Call the returned function to stop the
+//   purge.
+func start() (*apis.Api, func(), error) {
+	apis.Actor = verifiedUser
 	a, err := apis.New()
 	if err != nil {
-		panic(err)
+		return nil, nil, fmt.Errorf("starting the service failed: %v", err)
+	}
+	stopPurge, err := a.StartPurgeJob()
+	if err != nil {
+		return nil, nil, fmt.Errorf("starting the trash purge failed: %v", err)
 	}
-	// Register api paths
+	return a, stopPurge, nil
+}
+
+// Registers the api paths with handle.
+func routes(a *apis.Api, handle func(method, path string, h func(*eden.Context))) {
+	// Health checks
+	handle("GET", "/healthz", a.Healthz)
+	handle("GET", "/readyz", a.Readyz)
+	handle("GET", "/metrics", apis.Metrics)
 
 	// Resources
-	r.GET("/resources", a.GetAllResources)
-	r.GET("/resources/:guid", a.GetResource)
-	r.POST("/resources", a.InsertResource)
-	r.PUT("/resources/:guid", a.UpdateResource)
-	r.DELETE("/resources/:guid", a.DeleteResource)
+	handle("GET", "/resources", a.GetAllResources)
+	handle("GET", "/resources/:guid", a.GetResource) // and /resources/changes
+	handle("POST", "/resources", a.InsertResource)
+	handle("PUT", "/resources/:guid", a.UpdateResource)
+	handle("DELETE", "/resources/:guid", a.DeleteResource)
+	handle("POST", "/resources/:guid/restore", a.RestoreResource)
+	handle("GET", "/resources/:guid/history", a.GetResourceHistory)
+	handle("GET", "/resources/:guid/types", a.GetResourceTypes)
+	handle("GET", "/resources/:guid/types/:typeGuid", a.GetResourceTypeAssociation)
+	handle("GET", "/resources/:guid/verbs/:verbGuid", a.GetVerb)
+	handle("PUT", "/resources/:guid/suppressed/:verb", a.SuppressVerb)
+	handle("DELETE", "/resources/:guid/suppressed/:verb", a.UnsuppressVerb)
 
 	// Resource Verbs
-	r.GET("/verbs/:guid", a.GetResourceVerbs)
-	r.POST("/verbs", a.AddVerb)
-	r.PUT("/verbs/:guid", a.UpdateVerb)
-	r.DELETE("/verbs/:guid", a.RemoveVerb)
+	handle("GET", "/verbs/:guid", a.GetResourceVerbs)
+	handle("POST", "/verbs", a.AddVerb)
+	handle("PUT", "/verbs/:guid", a.UpdateVerb)
+	handle("DELETE", "/verbs/:guid", a.RemoveVerb)
+	handle("POST", "/verbs/:guid/restore", a.RestoreVerb)
+
+	// Grants
+	handle("GET", "/grants", a.GetGrants)
+	handle("GET", "/grants/:guid", a.GetGrant)
+	handle("POST", "/grants", a.AddGrant)
+	handle("PUT", "/grants/:guid", a.UpdateGrant)
+	handle("DELETE", "/grants/:guid", a.RemoveGrant)
+
+	// Authorization decisions
+	handle("POST", "/authorize", a.Authorize)
+	handle("POST", "/authorize/batch", a.AuthorizeBatch)
+
+	// Trash
+	handle("GET", "/trash", a.GetTrash)
+
+	// Audit log
+	handle("GET", "/audit", a.GetAudit)
+
+	// Import and export
+	handle("GET", "/export", a.ExportCatalog)
+	handle("POST", "/import", a.ImportCatalog)
 
 	// Resource Types
-	r.GET("/type/:guid", a.GetResourceType)
-	r.POST("/type", a.InsertResourceType)
+	handle("GET", "/type/:guid", a.GetResourceType)
+	handle("POST", "/type", a.InsertResourceType)
+	handle("GET", "/types", a.GetTypesInUse)
+	handle("GET", "/types/:typeGuid/resources", a.GetResourcesOfType)
 
 	// For HTTP requests that aren't GET or POST, due to this being a cross-domain micro-service
 	//   from the TMT, the browser is required to send an OPTIONS preflight request
@@ -47,10 +121,52 @@ func main() {
 	//   that GET, POST, PUT, and DELETE methods are available. See the Options function for how this is done.
 	// Also, this is a generalized version. If it is needed to be more specific, it could register an OPTIONS
 	//   url for each possible path to more specifically lock down cross-origin requests.
-	r.Register("OPTIONS", "/*path", Options)
+	handle("OPTIONS", "/*path", Options)
+}
+
+func main() {
+	if err := apis.SetupLogging(); err != nil {
+		fatal("configuring the log failed", err)
+	}
+
+	stopGuids, err := apis.StartGuidSource()
+	if err != nil {
+		fatal("starting the guid source failed", err)
+	}
+	defer stopGuids()
+
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			os.Exit(migrate(os.Args[2:]))
+		case "export":
+			os.Exit(exportCatalog(os.Args[2:]))
+		case "import":
+			os.Exit(importCatalog(os.Args[2:]))
+		}
+	}
+
+	r := eden.New()
+	r.Use(apis.RequestID)
+	r.Use(authorize)
+
+	a, stopPurge, err := start()
+	if err != nil {
+		fatal("the service could not start", err)
+	}
+	defer stopPurge()
+
+	// Register api paths, counting, timing and logging their requests
+	routes(a, func(method, path string, h func(*eden.Context)) {
+		r.Register(method, path, apis.Instrument(method, path, h))
+	})
 
 	// Run the server
+	slog.Info("listening", "addr", ":9000")
 	if err := r.Run(":9000"); err != nil {
-		fmt.Println(err)
+		stopPurge()
+		stopGuids()
+		fatal("the server stopped", err)
 	}
 }
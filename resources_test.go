@@ -0,0 +1,50 @@
+package main
+
+import (
+	eden "github.com/byu-oit-ssengineering/tmt-eden"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Checks that the service starts as main starts it, and that a change
+//   sent with credentials is made as their user.
+func TestStart(t *testing.T) {
+	os.Setenv("STORAGE", "memory")
+	defer os.Unsetenv("STORAGE")
+
+	a, stop, err := start()
+	if err != nil {
+		t.Fatalf("Expected the service to start but got %v", err)
+	}
+	defer stop()
+
+	handlers := make(map[string]func(*eden.Context))
+	routes(a, func(method, path string, h func(*eden.Context)) {
+		handlers[method+" "+path] = h
+	})
+	insert, ok := handlers["POST /resources"]
+	if !ok {
+		t.Fatalf("Expected POST /resources to be registered but got %v", handlers)
+	}
+
+	for _, test := range []struct {
+		user     string
+		expected int
+	}{
+		{"jdoe", 201},
+		{"", 401},
+	} {
+		req := httptest.NewRequest("POST", "/resources", strings.NewReader(`{"name": "board `+test.user+`", "description": "a board", "apiEndpoint": "https://tmt.byu.edu/boards"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if test.user != "" {
+			req.SetBasicAuth(test.user, "secret")
+		}
+		w := httptest.NewRecorder()
+		insert(&eden.Context{Request: req, Response: w})
+		if w.Code != test.expected {
+			t.Errorf("Expected %d for %q but got %d: %s", test.expected, test.user, w.Code, w.Body.String())
+		}
+	}
+}
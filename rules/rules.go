@@ -0,0 +1,110 @@
+package rules
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Longest values accepted, in characters. Names and endpoints are stored
+//   as VARCHAR(255).
+const (
+	MaxNameLength        = 255
+	MaxDescriptionLength = 4000
+	MaxEndpointLength    = 255
+)
+
+// Verbs are lower case words joined by hyphens, such as "erase" or
+//   "check-out", unless VERB_PATTERN says otherwise.
+const DefaultVerbPattern = `^[a-z][a-z0-9]*(-[a-z0-9]+)*$`
+
+// Matches a guid: 32 hex digits in groups of 8, 4, 4, 4 and 12.
+var GuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// The pattern every new verb must match.
+var VerbPattern = regexp.MustCompile(DefaultVerbPattern)
+
+// Sets VerbPattern from the VERB_PATTERN environment variable, if it is
+//   set.
+func LoadVerbPattern() error {
+	value := os.Getenv("VERB_PATTERN")
+	if value == "" {
+		return nil
+	}
+	pattern, err := regexp.Compile(value)
+	if err != nil {
+		return fmt.Errorf("VERB_PATTERN is not a valid regular expression: %v", err)
+	}
+	VerbPattern = pattern
+	return nil
+}
+
+// One broken rule. Field is named as the client sent it.
+type Violation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// The rules of one field's value: it must not be blank, must be at most
+//   MaxLength characters if MaxLength is set, and must keep Format if it is
+//   set. Format returns the rule a value breaks and why, or "" if it breaks
+//   none.
+type Field struct {
+	MaxLength int
+	Format    func(value string) (rule, message string)
+}
+
+// Rules of the fields of resources and verbs.
+var (
+	Name        = Field{MaxNameLength, nil}
+	Description = Field{MaxDescriptionLength, nil}
+	APIEndpoint = Field{MaxEndpointLength, AbsoluteURL}
+	Verb        = Field{MaxNameLength, VerbName}
+	Guid        = Field{0, GuidFormat}
+)
+
+// Returns every rule value breaks, naming the field name.
+func (f Field) Check(name, value string) []Violation {
+	if strings.TrimSpace(value) == "" {
+		return []Violation{{name, "required", fmt.Sprintf("%s must not be empty", name)}}
+	}
+	var violations []Violation
+	if f.MaxLength > 0 && utf8.RuneCountInString(value) > f.MaxLength {
+		violations = append(violations, Violation{name, "maxLength", fmt.Sprintf("%s must be at most %d characters", name, f.MaxLength)})
+	}
+	if f.Format != nil {
+		if rule, message := f.Format(value); rule != "" {
+			violations = append(violations, Violation{name, rule, fmt.Sprintf("%s %s", name, message)})
+		}
+	}
+	return violations
+}
+
+// An api endpoint must be an absolute http or https URL.
+func AbsoluteURL(value string) (string, string) {
+	u, err := url.Parse(value)
+	if err != nil || !u.IsAbs() || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "url", "must be an absolute http or https URL such as https://tmt.byu.edu/whiteboards"
+	}
+	return "", ""
+}
+
+// A verb must match VerbPattern.
+func VerbName(value string) (string, string) {
+	if !VerbPattern.MatchString(value) {
+		return "pattern", fmt.Sprintf("must match %s", VerbPattern)
+	}
+	return "", ""
+}
+
+// A reference to another entity must be a guid.
+func GuidFormat(value string) (string, string) {
+	if !GuidPattern.MatchString(value) {
+		return "guid", "must be a guid such as 00000000-0000-0000-0000-000000000000"
+	}
+	return "", ""
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		field    Field
+		value    string
+		expected []Violation
+	}{
+		{Name, "whiteboard", nil},
+		{Name, " ", []Violation{{"name", "required", "name must not be empty"}}},
+		{Name, strings.Repeat("a", MaxNameLength+1), []Violation{{"name", "maxLength", "name must be at most 255 characters"}}},
+		{APIEndpoint, "tmt.byu.edu/boards", []Violation{{"name", "url", "name must be an absolute http or https URL such as https://tmt.byu.edu/whiteboards"}}},
+		{Verb, "Check Out", []Violation{{"name", "pattern", "name must match " + DefaultVerbPattern}}},
+		{Verb, "check-out", nil},
+		{Guid, "room", []Violation{{"name", "guid", "name must be a guid such as 00000000-0000-0000-0000-000000000000"}}},
+		{Guid, "00000000-0000-0000-0000-000000000001", nil},
+	}
+	for _, test := range tests {
+		violations := test.field.Check("name", test.value)
+		if len(violations) != len(test.expected) {
+			t.Errorf("%s: Expected %v but got %v", test.value, test.expected, violations)
+			continue
+		}
+		for i := range test.expected {
+			if violations[i] != test.expected[i] {
+				t.Errorf("%s: Expected %v but got %v", test.value, test.expected[i], violations[i])
+			}
+		}
+	}
+}
+
+func TestLoadVerbPattern(t *testing.T) {
+	defer func() {
+		os.Unsetenv("VERB_PATTERN")
+		VerbPattern = regexp.MustCompile(DefaultVerbPattern)
+	}()
+
+	os.Setenv("VERB_PATTERN", "^[A-Z]+$")
+	if err := LoadVerbPattern(); err != nil {
+		t.Errorf("An unexpected error occurred: %v", err)
+	}
+	if !VerbPattern.MatchString("ERASE") || VerbPattern.MatchString("erase") {
+		t.Errorf("Expected %v but got %v", "^[A-Z]+$", VerbPattern)
+	}
+
+	os.Setenv("VERB_PATTERN", "[")
+	if err := LoadVerbPattern(); err == nil {
+		t.Errorf("Expected an error for an invalid pattern")
+	}
+}